@@ -2,7 +2,10 @@ package main
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
+	"os"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -11,9 +14,16 @@ import (
 	// 担当Aのパッケージ
 	"TO-DO-IT/internal/calendar"
 	"TO-DO-IT/internal/score"
+	"TO-DO-IT/internal/stats"
+	"TO-DO-IT/internal/task"
 
 	// 担当Cのパッケージ
 	"TO-DO-IT/internal/game" // ← インポートを確認
+	"TO-DO-IT/internal/importer"
+	"TO-DO-IT/internal/prune"
+	"TO-DO-IT/internal/recommend"
+	"TO-DO-IT/internal/steam"
+	"TO-DO-IT/internal/storage"
 	// ... (他に必要なパッケージ)
 )
 
@@ -29,27 +39,87 @@ func main() {
 	if err := initDB(db); err != nil {
 		log.Fatal("Failed to initialize database:", err)
 	}
+	// 既存のDBファイルに後から追加したカラムを足す
+	if err := migrateDB(db); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
 
 	// --- 依存関係の構築 (DI) ---
 	// 各担当のリポジトリを初期化
 	gameRepo := game.NewRepository(db)     // 担当C
 	calendarRepo := calendar.NewRepository(db) // 担当A
 	scoreRepo := score.NewRepository(db)       // 担当A
-	// ... (taskRepoなど)
+	taskRepo := task.NewRepository(db)         // プレイセッション (タイマー)
+
+	// プラットフォームのカタログ（PS5 → PlayStation 5 などの別名）を登録
+	if err := gameRepo.EnsurePlatforms(game.DefaultPlatforms); err != nil {
+		log.Fatal("Failed to seed platforms:", err)
+	}
 
 	// 各担当のサービスを初期化
-	// (担当Aのcalendarサービスは、担当Cのgameリポジトリが必要)
-	calendarSvc := calendar.NewService(calendarRepo, gameRepo) // 担当A
-	scoreSvc := score.NewService(scoreRepo)                     // 担当A
-	
+	scoreSvc := score.NewService(scoreRepo) // 担当A
+
+	// カバー画像の保存先（COVER_STORAGE_DIR で変更できる）
+	coverDir := os.Getenv("COVER_STORAGE_DIR")
+	if coverDir == "" {
+		coverDir = "./uploads"
+	}
+	coverStorage, err := storage.NewLocal(coverDir)
+	if err != nil {
+		log.Fatal("Failed to prepare cover storage:", err)
+	}
+
 	// ★↓↓↓ 担当Cのサービスを初期化 (コメントアウト解除) ↓↓↓
-	gameSvc := game.NewService(gameRepo)
+	// (完了報告のボーナス付与に担当Aのscoreサービスが必要)
+	gameSvc := game.NewService(gameRepo, scoreSvc, coverStorage)
+
+	// (担当Aのcalendarサービスは、担当Cのgameリポジトリ・サービスが必要)
+	calendarSvc := calendar.NewService(calendarRepo, gameRepo, gameSvc) // 担当A
+	// ゲームがクリア・中断されたら、calendar 側で残りの予定を取り消す
+	gameSvc.AddStatusListener(calendarSvc)
+	// ゲームを完全に削除したら、calendar 側で未実施の予定を消す
+	gameSvc.AddPurgeListener(calendarSvc)
+	gameSvc.AddMergeListener(calendarSvc)
+
+	// プレイセッション (実際に遊んだ時間をゲームの累計プレイ時間・ポイントに反映する)
+	taskSvc := task.NewService(taskRepo, gameRepo, gameSvc, calendarRepo, scoreSvc)
+	gameSvc.AddPurgeListener(taskSvc)
+	gameSvc.AddMergeListener(taskSvc)
+	// ゲーム詳細に最新のプレイ日記を載せる
+	gameSvc.SetJournalProvider(taskSvc)
 
 	// 各担当のハンドラを初期化
 	calendarHandler := calendar.NewHandler(calendarSvc) // 担当A
 	scoreHandler := score.NewHandler(scoreSvc)          // 担当D
+	taskHandler := task.NewHandler(taskSvc)
+	// 統計 (ゲーム・カレンダー・プレイセッションのリポジトリで集計する)
+	statsSvc := stats.NewService(stats.NewRepository(db), gameRepo, calendarRepo, taskRepo)
+	statsHandler := stats.NewHandler(statsSvc)
 	gameHandler := game.NewHandler(gameSvc)             // 担当C
 
+	// 「今なにを遊ぶか」のおすすめ (ゲームとカレンダーの両方を使う)
+	recommendHandler := recommend.NewHandler(recommend.NewService(gameRepo, gameSvc, calendarRepo, taskRepo))
+
+	// 楽しめていない・進んでいないゲームを「やめる候補」に挙げる。スケジュールの自動生成では後回しにする
+	pruneSvc := prune.NewService(gameRepo, gameSvc, calendarRepo, taskRepo)
+	calendarSvc.SetDropCandidateProvider(pruneSvc)
+	pruneHandler := prune.NewHandler(pruneSvc)
+
+	// Steam ライブラリ取り込み (担当C)
+	// STEAM_API_BASE_URL を指定すると、テスト用の偽 Steam サーバーに向けられる
+	steamClient := steam.NewClient(os.Getenv("STEAM_API_BASE_URL"), os.Getenv("STEAM_API_KEY"))
+	steamHandler := steam.NewHandler(steam.NewService(steamClient, gameSvc))
+
+	// 他サービス (Playnite / Backloggd / HowLongToBeat) からのインポート (担当C)
+	importHandler := importer.NewHandler(importer.NewService(importer.DefaultRegistry(), gameSvc))
+
+	// 積みゲーの状態を毎日記録する (推移のグラフ用)。1時間ごとに今日の記録を取り直す
+	stopSnapshotJob := stats.StartSnapshotJob(statsSvc, time.Hour)
+	defer stopSnapshotJob()
+	// 止め忘れたプレイセッションを自動的に終了する
+	stopAutoCloseJob := task.StartAutoCloseJob(taskSvc, 10*time.Minute)
+	defer stopAutoCloseJob()
+
 	// --- Echoサーバーのセットアップ ---
 	e := echo.New()
 
@@ -59,15 +129,23 @@ func main() {
 	// 担当Aのルートを登録
 	calendarHandler.RegisterRoutes(api)
 	scoreHandler.RegisterRoutes(api)
+	statsHandler.RegisterRoutes(api)
+	taskHandler.RegisterRoutes(api)
 
 	// 担当Cのルートを登録
 	gameHandler.RegisterRoutes(api)
+	steamHandler.RegisterRoutes(api)
+	importHandler.RegisterRoutes(api)
+	recommendHandler.RegisterRoutes(api)
+	pruneHandler.RegisterRoutes(api)
 
 	// CORS設定を追加
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"http://localhost:5173", "http://localhost:3000"},
-		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.DELETE},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept},
+		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.PATCH, echo.DELETE},
+		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, "If-Match"},
+		// フロントエンドから ETag を読めるようにする（更新時に If-Match で送り返す）
+		ExposeHeaders: []string{"ETag"},
 	}))
 
 	// --- サーバー起動 ---
@@ -91,6 +169,66 @@ func initDB(db *sql.DB) error {
 		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	);
 
+	CREATE TABLE IF NOT EXISTS game_notes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		game_id INTEGER NOT NULL,
+		body TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_game_notes_game_id ON game_notes (game_id);
+
+	CREATE TABLE IF NOT EXISTS game_status_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		game_id INTEGER NOT NULL,
+		from_status TEXT NOT NULL DEFAULT '',
+		to_status TEXT NOT NULL,
+		reason TEXT NOT NULL DEFAULT '',
+		changed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_game_status_history_game_id ON game_status_history (game_id);
+
+	CREATE TABLE IF NOT EXISTS tags (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		name TEXT NOT NULL COLLATE NOCASE,
+		color TEXT NOT NULL DEFAULT '',
+		schedule_days TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (user_id, name)
+	);
+
+	CREATE TABLE IF NOT EXISTS game_tags (
+		game_id INTEGER NOT NULL,
+		tag_id INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (game_id, tag_id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_game_tags_tag_id ON game_tags (tag_id);
+
+	CREATE TABLE IF NOT EXISTS game_relations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		game_id INTEGER NOT NULL,
+		related_game_id INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (game_id, related_game_id, type)
+	);
+	CREATE INDEX IF NOT EXISTS idx_game_relations_related_game_id ON game_relations (related_game_id);
+
+	CREATE TABLE IF NOT EXISTS platforms (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS platform_aliases (
+		alias_key TEXT PRIMARY KEY,
+		alias TEXT NOT NULL,
+		platform_id INTEGER NOT NULL
+	);
+
 	CREATE TABLE IF NOT EXISTS fixed_events (
 		id TEXT PRIMARY KEY,
 		user_id TEXT NOT NULL,
@@ -108,6 +246,50 @@ func initDB(db *sql.DB) error {
 		status TEXT DEFAULT 'pending'
 	);
 
+	CREATE TABLE IF NOT EXISTS backlog_snapshots (
+		user_id INTEGER NOT NULL,
+		day TEXT NOT NULL,
+		unstarted INTEGER NOT NULL DEFAULT 0,
+		playing INTEGER NOT NULL DEFAULT 0,
+		paused INTEGER NOT NULL DEFAULT 0,
+		completed INTEGER NOT NULL DEFAULT 0,
+		dropped INTEGER NOT NULL DEFAULT 0,
+		remaining_hours REAL NOT NULL DEFAULT 0,
+		source TEXT NOT NULL DEFAULT 'job',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, day)
+	);
+
+	CREATE TABLE IF NOT EXISTS play_sessions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id TEXT NOT NULL,
+		game_id INTEGER NOT NULL,
+		schedule_id TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'running',
+		started_at DATETIME NOT NULL,
+		ended_at DATETIME,
+		paused_at DATETIME,
+		paused_seconds INTEGER NOT NULL DEFAULT 0,
+		minutes INTEGER NOT NULL DEFAULT 0,
+		points INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS idx_play_sessions_game_id ON play_sessions (game_id);
+
+	CREATE TABLE IF NOT EXISTS journal_entries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id TEXT NOT NULL,
+		game_id INTEGER NOT NULL,
+		schedule_id TEXT NOT NULL DEFAULT '',
+		session_id INTEGER NOT NULL DEFAULT 0,
+		text TEXT NOT NULL DEFAULT '',
+		enjoyment INTEGER NOT NULL DEFAULT 0,
+		mood TEXT NOT NULL DEFAULT '',
+		progress_note TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_journal_entries_game_id ON journal_entries (game_id);
+
 	CREATE TABLE IF NOT EXISTS motivation (
 		user_id TEXT PRIMARY KEY,
 		points INTEGER DEFAULT 0,
@@ -118,4 +300,80 @@ func initDB(db *sql.DB) error {
 
 	_, err := db.Exec(schema)
 	return err
-}
\ No newline at end of file
+}
+
+// migrateDB は、CREATE TABLE IF NOT EXISTS では増えないカラムを既存テーブルに追加します。
+// 新しいカラムはここに追記してください（追加済みのものはスキップされます）。
+func migrateDB(db *sql.DB) error {
+	columns := []struct {
+		table      string
+		column     string
+		definition string
+	}{
+		// 担当C: 完了報告
+		{"games", "estimated_hours", "REAL NOT NULL DEFAULT 0"},
+		{"games", "completed_at", "DATETIME"},
+		{"games", "rating", "INTEGER NOT NULL DEFAULT 0"},
+		{"games", "review", "TEXT NOT NULL DEFAULT ''"},
+		{"games", "completion_type", "TEXT NOT NULL DEFAULT ''"},
+		// 担当C: ゴミ箱（論理削除）
+		{"games", "deleted_at", "DATETIME"},
+		// 担当C: Steam ライブラリ取り込み
+		{"games", "played_minutes", "INTEGER NOT NULL DEFAULT 0"},
+		{"games", "steam_app_id", "INTEGER NOT NULL DEFAULT 0"},
+		// 担当C: 楽観的ロック（ETag / If-Match）
+		{"games", "version", "INTEGER NOT NULL DEFAULT 1"},
+		// 担当C: カバー画像
+		{"games", "cover_key", "TEXT NOT NULL DEFAULT ''"},
+		// 担当C: 購入情報（価格は通貨の最小単位）
+		{"games", "purchase_price", "INTEGER"},
+		{"games", "currency", "TEXT NOT NULL DEFAULT ''"},
+		{"games", "purchase_date", "TEXT NOT NULL DEFAULT ''"},
+		{"games", "store", "TEXT NOT NULL DEFAULT ''"},
+		// 担当C: 遊ぶ順番の希望（おすすめで使う）
+		{"games", "priority", "INTEGER NOT NULL DEFAULT 0"},
+		{"games", "deadline", "TEXT NOT NULL DEFAULT ''"},
+	}
+
+	for _, c := range columns {
+		exists, err := columnExists(db, c.table, c.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.column, c.definition)); err != nil {
+			return err
+		}
+		log.Printf("Migrated: added %s.%s", c.table, c.column)
+	}
+	return nil
+}
+
+// columnExists は、テーブルに指定したカラムがあるかを PRAGMA table_info で調べます。
+func columnExists(db *sql.DB, table, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
@@ -0,0 +1,291 @@
+package calendar
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"TO-DO-IT/internal/game"
+)
+
+// 実績から求める割合の下限と、信頼区間の z 値 (80%)
+const (
+	minPlayRate = 0.05
+	forecastZ   = 1.2816
+)
+
+// Forecast ... 積みゲーを GenerateSchedule と同じ枠の探し方で先まで割り当て、終わる日を見積もる
+//   - 未開始・プレイ中・中断中のゲームを、プレイ中 → 中断中 → 未開始の順に1本ずつ終わらせる
+//   - DLC・続編は、本編・前作が終わる予定の後に始める
+//   - 予定したセッションは PlayRate の割合しかプレイされないとして、その分多く割り当てる
+//   - 固定予定・タグの曜日ルール・1日の上限 (DailyMinutes) を守る
+func (s *service) Forecast(userID string, opts ForecastOptions) (*Forecast, error) {
+	if opts.SessionMinutes == 0 {
+		opts.SessionMinutes = DefaultSessionMinutes
+	}
+	if opts.DailyMinutes == 0 {
+		opts.DailyMinutes = DefaultDailyMinutes
+	}
+	if opts.HorizonDays == 0 {
+		opts.HorizonDays = DefaultHorizonDays
+	}
+	if opts.SessionMinutes < 0 || opts.SessionMinutes > 24*60 ||
+		opts.DailyMinutes < 0 || opts.DailyMinutes > 24*60 ||
+		opts.HorizonDays < 0 || opts.HorizonDays > MaxHorizonDays {
+		return nil, ErrInvalidForecastOption
+	}
+
+	now := time.Now()
+	forecast := &Forecast{GeneratedAt: now, Games: []*GameForecast{}}
+
+	// 1. 実績 (これまでに完了・スキップしたセッション) からプレイする割合を求める
+	counts, err := s.calendarRepo.GetSessionCounts(userID, time.Time{}, now)
+	if err != nil {
+		return nil, err
+	}
+	forecast.Assumptions = ForecastAssumptions{
+		ForecastOptions:   opts,
+		CompletedSessions: counts.Completed,
+		SkippedSessions:   counts.Skipped,
+	}
+	a := &forecast.Assumptions
+	a.PlayRate, a.PlayRateLow, a.PlayRateHigh = playRate(counts.Completed, counts.Skipped)
+
+	// 2. 積みゲーと、見積もれないゲームを分ける
+	games, err := s.gameRepo.GetGamesByUserID(1) // testUserID = 1
+	if err != nil {
+		return nil, err
+	}
+	backlog := backlogInPlayOrder(games)
+	if len(backlog) == 0 {
+		forecast.ClearedAt, forecast.ClearedEarliest, forecast.ClearedLatest = &now, &now, &now
+		return forecast, nil
+	}
+
+	blocked, err := s.gameSvc.BlockedGames()
+	if err != nil {
+		return nil, err
+	}
+	reasons := unforecastableGames(backlog, blocked)
+
+	var plannable []*game.Game
+	for _, g := range backlog {
+		if reasons[g.ID] == "" {
+			plannable = append(plannable, g)
+		}
+	}
+
+	// 3. 見積もる期間の固定予定を避けて、3通りの割合で割り当てる
+	end := now.AddDate(0, 0, opts.HorizonDays)
+	fixedEvents, err := s.calendarRepo.GetFixedEventsByUserID(userID, now, end)
+	if err != nil {
+		return nil, err
+	}
+	// スケジュール生成と同じく、よくスキップされる枠を避けて割り当てる
+	slots, err := s.learnSlots(userID, now.AddDate(0, 0, -DefaultInsightDays), now)
+	if err != nil {
+		return nil, err
+	}
+	expected := simulateClearance(plannable, blocked, fixedEvents, slots, now, end, opts, a.PlayRate)
+	earliest := simulateClearance(plannable, blocked, fixedEvents, slots, now, end, opts, a.PlayRateHigh)
+	latest := simulateClearance(plannable, blocked, fixedEvents, slots, now, end, opts, a.PlayRateLow)
+
+	// 4. ゲームごとの結果をまとめる
+	for _, g := range backlog {
+		f := &GameForecast{
+			GameID:         g.ID,
+			Title:          g.Title,
+			Status:         g.Status,
+			RemainingHours: math.Round(remainingHours(g)*100) / 100,
+			Reason:         reasons[g.ID],
+		}
+		if run := expected[g.ID]; run != nil {
+			f.Sessions = run.sessions
+			f.StartDate = run.start
+			f.ProjectedCompletion = run.done
+		}
+		if run := earliest[g.ID]; run != nil {
+			f.Earliest = run.done
+		}
+		if run := latest[g.ID]; run != nil {
+			f.Latest = run.done
+		}
+		if f.Reason == "" && f.ProjectedCompletion == nil {
+			f.Reason = ForecastReasonBeyondHorizon
+		}
+		forecast.Games = append(forecast.Games, f)
+	}
+	sort.SliceStable(forecast.Games, func(i, j int) bool {
+		a, b := forecast.Games[i].ProjectedCompletion, forecast.Games[j].ProjectedCompletion
+		return a != nil && (b == nil || a.Before(*b))
+	})
+
+	forecast.ClearedAt = clearedAt(backlog, expected)
+	forecast.ClearedEarliest = clearedAt(backlog, earliest)
+	forecast.ClearedLatest = clearedAt(backlog, latest)
+	return forecast, nil
+}
+
+// playRate ... 完了・スキップの件数から、プレイする割合とその80%信頼区間 (Wilson) を求める
+// 実績がなければ、すべてプレイする (1) とみなし、幅は 0.5〜1 にする
+func playRate(completed int, skipped int) (rate float64, low float64, high float64) {
+	n := float64(completed + skipped)
+	if n == 0 {
+		return 1, 0.5, 1
+	}
+	p := float64(completed) / n
+	z2 := forecastZ * forecastZ
+	center := (p + z2/(2*n)) / (1 + z2/n)
+	half := forecastZ * math.Sqrt(p*(1-p)/n+z2/(4*n*n)) / (1 + z2/n)
+
+	rate = math.Max(p, minPlayRate)
+	low = math.Max(math.Min(center-half, rate), minPlayRate)
+	high = math.Min(math.Max(center+half, rate), 1)
+	return rate, low, high
+}
+
+// backlogInPlayOrder ... 積みゲーを、プレイ中 → 中断中 → 未開始の順に並べる (同じステータスの中は元の順)
+func backlogInPlayOrder(games []*game.Game) []*game.Game {
+	rank := map[string]int{game.StatusPlaying: 1, game.StatusPaused: 2, game.StatusUnstarted: 3}
+	var backlog []*game.Game
+	for _, g := range games {
+		if rank[g.Status] > 0 {
+			backlog = append(backlog, g)
+		}
+	}
+	sort.SliceStable(backlog, func(i, j int) bool {
+		return rank[backlog[i].Status] < rank[backlog[j].Status]
+	})
+	return backlog
+}
+
+// unforecastableGames ... 見積もれないゲームと、その理由を返す
+// 本編・前作が見積もれないゲームも、始められないので見積もれない
+func unforecastableGames(backlog []*game.Game, blocked map[int][]int) map[int]string {
+	reasons := map[int]string{}
+	for _, g := range backlog {
+		switch {
+		case g.EstimatedHours <= 0:
+			reasons[g.ID] = ForecastReasonNoEstimate
+		case !canScheduleAnyDay(g):
+			reasons[g.ID] = ForecastReasonNoWeekday
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, g := range backlog {
+			if reasons[g.ID] != "" {
+				continue
+			}
+			for _, before := range blocked[g.ID] {
+				if reasons[before] != "" {
+					reasons[g.ID] = ForecastReasonBlocked
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return reasons
+}
+
+// remainingHours ... クリアまでの残り時間 (推定プレイ時間 - プレイ時間)
+func remainingHours(g *game.Game) float64 {
+	return math.Max(g.EstimatedHours-float64(g.PlayedMinutes)/60, 0)
+}
+
+// clearanceRun ... 1本のゲームの割り当て結果
+type clearanceRun struct {
+	start    *time.Time
+	done     *time.Time
+	sessions int
+}
+
+// simulateClearance ... games を順に、終わるまでセッションを割り当てる
+// 予定したセッションは rate の割合しかプレイされないので、残り時間 / rate だけ割り当てる。
+// end までに終わらないゲームと、それより後のゲームは結果に入らない。
+// 枠は GenerateSchedule と同じく slots の確率で選ぶ
+func simulateClearance(games []*game.Game, blocked map[int][]int, fixedEvents []FixedEvent, slots *slotModel, start time.Time, end time.Time, opts ForecastOptions, rate float64) map[int]*clearanceRun {
+	session := time.Duration(opts.SessionMinutes) * time.Minute
+	daily := time.Duration(opts.DailyMinutes) * time.Minute
+	used := map[string]time.Duration{} // 日ごとの割り当て済みの時間
+
+	runs := map[int]*clearanceRun{}
+	pending := append([]*game.Game{}, games...)
+	cursor := start
+
+	for len(pending) > 0 {
+		// 本編・前作が終わっている最初のゲーム
+		idx := -1
+		for i, g := range pending {
+			ready := true
+			for _, before := range blocked[g.ID] {
+				if runs[before] == nil {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			break
+		}
+		g := pending[idx]
+		pending = append(pending[:idx], pending[idx+1:]...)
+
+		run := &clearanceRun{}
+		need := time.Duration(math.Ceil(remainingHours(g)/rate*60)) * time.Minute // 分単位に切り上げ
+		for need > 0 {
+			t, d := nextBudgetedSlot(cursor, min(session, need), fixedEvents, g.CanScheduleOn, slots, used, daily)
+			if t.After(end) {
+				return runs // ここから先は見積もる期間の外
+			}
+			used[t.Format("2006-01-02")] += d
+			cursor = t.Add(d)
+			need -= d
+			run.sessions++
+			if run.start == nil {
+				run.start = &t
+			}
+		}
+		done := cursor
+		if run.start == nil {
+			run.start = &done // 残り時間がないゲームは、すぐに終わる
+		}
+		run.done = &done
+		runs[g.ID] = run
+	}
+	return runs
+}
+
+// nextBudgetedSlot ... findPreferredTime でその日のうちのプレイする確率が高い枠を探し、その日の上限 (daily) を超えないように長さを詰める
+// その日の上限に達していれば、翌日から探し直す
+func nextBudgetedSlot(cursor time.Time, duration time.Duration, fixedEvents []FixedEvent, allowed func(time.Weekday) bool, slots *slotModel, used map[string]time.Duration, daily time.Duration) (time.Time, time.Duration) {
+	for {
+		dayEnd := time.Date(cursor.Year(), cursor.Month(), cursor.Day()+1, 0, 0, 0, 0, cursor.Location())
+		t := findPreferredTime(cursor, dayEnd, duration, fixedEvents, allowed, slots)
+		left := daily - used[t.Format("2006-01-02")]
+		if left > 0 {
+			return t, min(duration, left)
+		}
+		cursor = time.Date(t.Year(), t.Month(), t.Day()+1, 9, 0, 0, 0, t.Location())
+	}
+}
+
+// clearedAt ... すべての積みゲーが終わる予定。見積もれなかったゲームがあれば nil
+func clearedAt(backlog []*game.Game, runs map[int]*clearanceRun) *time.Time {
+	var last *time.Time
+	for _, g := range backlog {
+		run := runs[g.ID]
+		if run == nil {
+			return nil
+		}
+		if last == nil || run.done.After(*last) {
+			last = run.done
+		}
+	}
+	return last
+}
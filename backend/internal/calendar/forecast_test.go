@@ -0,0 +1,159 @@
+package calendar
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"TO-DO-IT/internal/game"
+)
+
+func TestPlayRate(t *testing.T) {
+	tests := []struct {
+		completed, skipped int
+		rate, low, high    float64
+	}{
+		{completed: 0, skipped: 0, rate: 1, low: 0.5, high: 1}, // 実績がなければ、すべてプレイするとみなす
+		{completed: 8, skipped: 2, rate: 0.8, low: 0.6016, high: 0.9138},
+		{completed: 5, skipped: 5, rate: 0.5, low: 0.3122, high: 0.6878},
+		{completed: 10, skipped: 0, rate: 1, low: 0.8589, high: 1},
+		{completed: 0, skipped: 10, rate: minPlayRate, low: minPlayRate, high: 0.1411}, // 下限で止める
+	}
+	for _, tt := range tests {
+		rate, low, high := playRate(tt.completed, tt.skipped)
+		if math.Abs(rate-tt.rate) > 1e-4 || math.Abs(low-tt.low) > 1e-4 || math.Abs(high-tt.high) > 1e-4 {
+			t.Errorf("playRate(%d, %d) = %.4f, %.4f, %.4f; want %.4f, %.4f, %.4f",
+				tt.completed, tt.skipped, rate, low, high, tt.rate, tt.low, tt.high)
+		}
+		if low > rate || rate > high {
+			t.Errorf("playRate(%d, %d): want low <= rate <= high, got %.4f, %.4f, %.4f", tt.completed, tt.skipped, low, rate, high)
+		}
+	}
+}
+
+// monday ... 2026-01-05 (月) の h 時
+func monday(h int) time.Time {
+	return time.Date(2026, time.January, 5, h, 0, 0, 0, time.UTC)
+}
+
+func TestSimulateClearance(t *testing.T) {
+	opts := ForecastOptions{SessionMinutes: 120, DailyMinutes: 120}
+	type want struct {
+		start, done time.Time
+		sessions    int
+	}
+	tests := []struct {
+		name        string
+		games       []*game.Game
+		blocked     map[int][]int
+		fixedEvents []FixedEvent
+		slots       *slotModel
+		opts        ForecastOptions
+		rate        float64
+		end         time.Time
+		want        map[int]want
+	}{
+		{
+			name:  "one session per day",
+			games: []*game.Game{{ID: 1, EstimatedHours: 4}},
+			opts:  opts,
+			rate:  1,
+			want:  map[int]want{1: {start: monday(9), done: monday(11).AddDate(0, 0, 1), sessions: 2}},
+		},
+		{
+			name:  "low play rate needs more sessions",
+			games: []*game.Game{{ID: 1, EstimatedHours: 4}},
+			opts:  opts,
+			rate:  0.5,
+			want:  map[int]want{1: {start: monday(9), done: monday(11).AddDate(0, 0, 3), sessions: 4}},
+		},
+		{
+			name:  "played time is subtracted",
+			games: []*game.Game{{ID: 1, EstimatedHours: 4, PlayedMinutes: 180}},
+			opts:  opts,
+			rate:  1,
+			want:  map[int]want{1: {start: monday(9), done: monday(10), sessions: 1}},
+		},
+		{
+			name:  "nothing left finishes at once",
+			games: []*game.Game{{ID: 1, EstimatedHours: 2, PlayedMinutes: 150}},
+			opts:  opts,
+			rate:  1,
+			want:  map[int]want{1: {start: monday(9), done: monday(9), sessions: 0}},
+		},
+		{
+			name:  "daily budget shortens the last session of the day",
+			games: []*game.Game{{ID: 1, EstimatedHours: 4}},
+			opts:  ForecastOptions{SessionMinutes: 120, DailyMinutes: 180},
+			rate:  1,
+			want:  map[int]want{1: {start: monday(9), done: monday(10).AddDate(0, 0, 1), sessions: 3}},
+		},
+		{
+			name:        "fixed events are avoided",
+			games:       []*game.Game{{ID: 1, EstimatedHours: 2}},
+			fixedEvents: []FixedEvent{{StartTime: monday(9), EndTime: monday(12)}},
+			opts:        opts,
+			rate:        1,
+			want:        map[int]want{1: {start: monday(12), done: monday(14), sessions: 1}},
+		},
+		{
+			name:  "slots with higher probability are preferred",
+			games: []*game.Game{{ID: 1, EstimatedHours: 4}},
+			slots: preferSlots(map[time.Time]float64{monday(20): 0.9, monday(20).AddDate(0, 0, 1): 0.9}),
+			opts:  opts,
+			rate:  1,
+			want:  map[int]want{1: {start: monday(20), done: monday(22).AddDate(0, 0, 1), sessions: 2}},
+		},
+		{
+			name:  "weekday rules of tags are followed",
+			games: []*game.Game{{ID: 1, EstimatedHours: 2, Tags: []*game.Tag{{ScheduleDays: []string{"sat", "sun"}}}}},
+			opts:  opts,
+			rate:  1,
+			want:  map[int]want{1: {start: monday(9).AddDate(0, 0, 5), done: monday(11).AddDate(0, 0, 5), sessions: 1}},
+		},
+		{
+			name:    "sequels wait for the game before them",
+			games:   []*game.Game{{ID: 2, EstimatedHours: 2}, {ID: 1, EstimatedHours: 2}},
+			blocked: map[int][]int{2: {1}},
+			opts:    opts,
+			rate:    1,
+			want: map[int]want{
+				1: {start: monday(9), done: monday(11), sessions: 1},
+				2: {start: monday(9).AddDate(0, 0, 1), done: monday(11).AddDate(0, 0, 1), sessions: 1},
+			},
+		},
+		{
+			name:  "games beyond the horizon are left out",
+			games: []*game.Game{{ID: 1, EstimatedHours: 2}, {ID: 2, EstimatedHours: 20}, {ID: 3, EstimatedHours: 2}},
+			opts:  opts,
+			rate:  1,
+			end:   monday(9).AddDate(0, 0, 3),
+			want:  map[int]want{1: {start: monday(9), done: monday(11), sessions: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, slots := tt.end, tt.slots
+			if end.IsZero() {
+				end = monday(9).AddDate(0, 0, 30)
+			}
+			if slots == nil {
+				slots = &slotModel{}
+			}
+			runs := simulateClearance(tt.games, tt.blocked, tt.fixedEvents, slots, monday(9), end, tt.opts, tt.rate)
+			if len(runs) != len(tt.want) {
+				t.Fatalf("got %d runs, want %d", len(runs), len(tt.want))
+			}
+			for id, w := range tt.want {
+				run := runs[id]
+				if run == nil {
+					t.Fatalf("game %d: no run", id)
+				}
+				if !run.start.Equal(w.start) || !run.done.Equal(w.done) || run.sessions != w.sessions {
+					t.Errorf("game %d: start %v, done %v, %d sessions; want %v, %v, %d",
+						id, run.start, run.done, run.sessions, w.start, w.done, w.sessions)
+				}
+			}
+		})
+	}
+}
@@ -1,10 +1,14 @@
 package calendar
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/labstack/echo/v4"
+
+	"TO-DO-IT/internal/mergepatch"
 )
 
 type Handler struct {
@@ -21,14 +25,20 @@ func (h *Handler) RegisterRoutes(api *echo.Group) {
 	{
 		// 自動生成 [cite: 71]
 		calApi.POST("/generate", h.handleGenerateSchedule)
+		// 積みゲー消化予定日の見積もり (保存はしない)
+		calApi.GET("/forecast", h.handleForecast) // ?session_minutes=120&daily_minutes=120&horizon_days=365
+		calApi.GET("/insights", h.handleInsights) // ?days=90 (曜日・時間帯ごとにプレイする確率)
 
 		// スケジュール [cite: 72-73]
 		calApi.GET("/schedule", h.handleGetSchedules)
-		calApi.PUT("/schedule/:id", h.handleUpdateScheduleStatus)
+		calApi.PUT("/schedule/:id", h.handleReplaceSchedule) // 全体の置き換え ({"status": ...} だけなら以前と同じ進捗更新)
+		calApi.PATCH("/schedule/:id", h.handlePatchSchedule) // 部分更新 (JSON Merge Patch)。進捗更新は {"status": "completed"}
 
 		// 固定予定 [cite: 81-82]
 		calApi.POST("/fixed-events", h.handleCreateFixedEvent)
 		calApi.GET("/fixed-events", h.handleGetFixedEvents)
+		calApi.PUT("/fixed-events/:id", h.handleReplaceFixedEvent)
+		calApi.PATCH("/fixed-events/:id", h.handlePatchFixedEvent)
 	}
 }
 
@@ -45,6 +55,64 @@ func (h *Handler) handleGenerateSchedule(c echo.Context) error {
 	return c.JSON(http.StatusCreated, schedules)
 }
 
+// handleForecast ... 積みゲーがすべて終わる日と、ゲームごとの終わる日を見積もる
+// 省略した条件は既定値 (2時間のセッションを1日1回、1年先まで)
+func (h *Handler) handleForecast(c echo.Context) error {
+	userID := "user_123" // 仮
+
+	var opts ForecastOptions
+	params := []struct {
+		name string
+		dst  *int
+	}{
+		{"session_minutes", &opts.SessionMinutes},
+		{"daily_minutes", &opts.DailyMinutes},
+		{"horizon_days", &opts.HorizonDays},
+	}
+	for _, p := range params {
+		v := c.QueryParam(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": ErrInvalidForecastOption.Error()})
+		}
+		*p.dst = n
+	}
+
+	forecast, err := h.service.Forecast(userID, opts)
+	if err != nil {
+		if errors.Is(err, ErrInvalidForecastOption) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, forecast)
+}
+
+func (h *Handler) handleInsights(c echo.Context) error {
+	userID := "user_123" // 仮
+
+	var opts InsightsOptions
+	if v := c.QueryParam("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": ErrInvalidInsightsOption.Error()})
+		}
+		opts.Days = n
+	}
+
+	insights, err := h.service.Insights(userID, opts)
+	if err != nil {
+		if errors.Is(err, ErrInvalidInsightsOption) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, insights)
+}
+
 func (h *Handler) handleGetSchedules(c echo.Context) error {
 	userID := "user_123" // 仮
 	// TODO: クエリパラメータから期間を取得
@@ -58,21 +126,59 @@ func (h *Handler) handleGetSchedules(c echo.Context) error {
 	return c.JSON(http.StatusOK, schedules)
 }
 
-func (h *Handler) handleUpdateScheduleStatus(c echo.Context) error {
+// handleReplaceSchedule ... スケジュールを置き換える (PUT)。game_id, start_time, end_time, status はすべて必須
+// 以前の PUT は進捗更新 {"status": "completed"} だけを受け付けていたので、
+// status だけのボディは互換のため PATCH と同じ部分更新として扱う (新しいクライアントは PATCH を使う)
+func (h *Handler) handleReplaceSchedule(c echo.Context) error {
 	scheduleID := c.Param("id")
 
-	// リクエストボディから新しいステータスを取得
-	var reqBody struct {
-		Status string `json:"status"`
+	var req ScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
 	}
-	if err := c.Bind(&reqBody); err != nil {
-		return c.JSON(http.StatusBadRequest, "invalid request body")
+
+	if req.GameID == "" && req.StartTime.IsZero() && req.EndTime.IsZero() && req.Status != "" {
+		schedule, err := h.service.PatchSchedule(scheduleID, &SchedulePatch{Status: mergepatch.Value(req.Status)})
+		if err != nil {
+			return scheduleError(c, err)
+		}
+		return c.JSON(http.StatusOK, schedule)
 	}
 
-	if err := h.service.UpdateScheduleStatus(scheduleID, reqBody.Status); err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	schedule, err := h.service.ReplaceSchedule(scheduleID, &req)
+	if err != nil {
+		return scheduleError(c, err)
 	}
-	return c.JSON(http.StatusOK, map[string]string{"message": "status updated"})
+	return c.JSON(http.StatusOK, schedule)
+}
+
+// handlePatchSchedule ... スケジュールを部分的に更新する (PATCH)
+// Content-Type が application/merge-patch+json でも読めるように、Bind ではなく直接デコードする
+func (h *Handler) handlePatchSchedule(c echo.Context) error {
+	scheduleID := c.Param("id")
+
+	var patch SchedulePatch
+	if err := mergepatch.Decode(c.Request().Body, &patch); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	schedule, err := h.service.PatchSchedule(scheduleID, &patch)
+	if err != nil {
+		return scheduleError(c, err)
+	}
+	return c.JSON(http.StatusOK, schedule)
+}
+
+// scheduleError ... スケジュール更新のエラーをレスポンスに変換する
+func scheduleError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidScheduleStatus), errors.Is(err, ErrRequiredField),
+		errors.Is(err, ErrInvalidTimeRange), errors.Is(err, ErrGameNotFound):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrScheduleNotFound), errors.Is(err, ErrFixedEventNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 }
 
 func (h *Handler) handleCreateFixedEvent(c echo.Context) error {
@@ -99,3 +205,35 @@ func (h *Handler) handleGetFixedEvents(c echo.Context) error {
 	}
 	return c.JSON(http.StatusOK, events)
 }
+
+// handleReplaceFixedEvent ... 固定予定を置き換える (PUT)。title, start_time, end_time はすべて必須
+func (h *Handler) handleReplaceFixedEvent(c echo.Context) error {
+	eventID := c.Param("id")
+
+	var req FixedEventRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	event, err := h.service.ReplaceFixedEvent(eventID, &req)
+	if err != nil {
+		return scheduleError(c, err)
+	}
+	return c.JSON(http.StatusOK, event)
+}
+
+// handlePatchFixedEvent ... 固定予定を部分的に更新する (PATCH, JSON Merge Patch)
+func (h *Handler) handlePatchFixedEvent(c echo.Context) error {
+	eventID := c.Param("id")
+
+	var patch FixedEventPatch
+	if err := mergepatch.Decode(c.Request().Body, &patch); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	event, err := h.service.PatchFixedEvent(eventID, &patch)
+	if err != nil {
+		return scheduleError(c, err)
+	}
+	return c.JSON(http.StatusOK, event)
+}
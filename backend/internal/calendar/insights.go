@@ -0,0 +1,188 @@
+package calendar
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// スケジュールを入れる時間帯 (adjustToBusinessHours と同じ 9:00〜23:00 に始まる枠)
+const (
+	firstSlotHour = 9
+	lastSlotHour  = 22
+)
+
+const (
+	// slotPriorWeight ... 実績の少ない枠の確率を、曜日・時間帯全体の割合に寄せる強さ (この件数分の実績とみなす)
+	slotPriorWeight = 4.0
+	// slotLatenessPenalty ... GenerateSchedule で、1日遅い枠を選ぶときに確率から引く値
+	// 確率がどの枠も同じ (実績がない) なら、これまでどおり一番早い枠に入る
+	slotLatenessPenalty = 0.03
+	// InsightSlotCount ... おすすめ・避けたい枠として返す件数
+	InsightSlotCount = 3
+)
+
+// weekdayKeys ... 曜日の表記 (game のタグの schedule_days と同じ)
+var weekdayKeys = [7]string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// slotModel ... スケジュールの結果を、曜日・開始時刻ごとに数えたもの
+type slotModel struct {
+	overall SlotStat
+	weekday [7]SlotStat
+	hour    [24]SlotStat
+	cell    [7][24]SlotStat
+}
+
+// learnSlots ... from 以降に始まり、now までに終わったスケジュールの結果を数え、確率を求める
+// 取り消された予定は、ユーザーの都合ではないので数えない
+func (s *service) learnSlots(userID string, from time.Time, now time.Time) (*slotModel, error) {
+	schedules, err := s.calendarRepo.GetSchedulesByUserID(userID, from, now)
+	if err != nil {
+		return nil, err
+	}
+	return countSlots(schedules, from, now), nil
+}
+
+// countSlots ... learnSlots の集計部分。schedules の結果を枠ごとに数え、確率を求める
+func countSlots(schedules []Schedule, from time.Time, now time.Time) *slotModel {
+	m := &slotModel{}
+	for _, sc := range schedules {
+		if sc.StartTime.Before(from) {
+			continue
+		}
+		var count func(st *SlotStat)
+		switch {
+		case sc.Status == ScheduleStatusCompleted:
+			count = func(st *SlotStat) { st.Completed++ }
+		case sc.Status == ScheduleStatusSkipped:
+			count = func(st *SlotStat) { st.Skipped++ }
+		case sc.Status == ScheduleStatusPending && sc.EndTime.Before(now):
+			count = func(st *SlotStat) { st.Missed++ }
+		default:
+			continue
+		}
+		wd, h := slotOf(sc.StartTime)
+		for _, st := range []*SlotStat{&m.overall, &m.weekday[wd], &m.hour[h], &m.cell[wd][h]} {
+			count(st)
+			st.Scheduled++
+		}
+	}
+
+	// 全体 → 曜日・時間帯 → 曜日×時間帯 の順に、実績の少ない枠ほど上の段の割合に寄せる
+	m.overall.Probability = (float64(m.overall.Completed) + 1) / (float64(m.overall.Scheduled) + 2)
+	for wd := range m.weekday {
+		m.weekday[wd].Probability = smoothed(m.weekday[wd], m.overall.Probability)
+	}
+	for h := range m.hour {
+		m.hour[h].Probability = smoothed(m.hour[h], m.overall.Probability)
+	}
+	for wd := range m.cell {
+		for h := range m.cell[wd] {
+			// 曜日と時間帯の影響は独立とみなして、両方の割合から枠の割合を見込む
+			prior := m.weekday[wd].Probability * m.hour[h].Probability / m.overall.Probability
+			prior = math.Min(math.Max(prior, 0.01), 0.99)
+			m.cell[wd][h].Probability = smoothed(m.cell[wd][h], prior)
+		}
+	}
+	return m
+}
+
+// smoothed ... 実績に、prior の割合の slotPriorWeight 件分を足して確率を求める
+func smoothed(st SlotStat, prior float64) float64 {
+	return (float64(st.Completed) + slotPriorWeight*prior) / (float64(st.Scheduled) + slotPriorWeight)
+}
+
+// slotOf ... 時刻の曜日と時 (サーバーのタイムゾーン)
+func slotOf(t time.Time) (weekday int, hour int) {
+	t = t.Local()
+	return int(t.Weekday()), t.Hour()
+}
+
+// probability ... t に始まる予定を、予定どおりにプレイする確率
+func (m *slotModel) probability(t time.Time) float64 {
+	wd, h := slotOf(t)
+	return m.cell[wd][h].Probability
+}
+
+// findPreferredTime ... start から end までの空いている枠のうち、プレイする確率が高く、早い枠を選ぶ
+// 空いている枠がなければ、findNextAvailableTime と同じく end より後の最初の空きを返す
+func findPreferredTime(start time.Time, end time.Time, duration time.Duration, fixedEvents []FixedEvent, allowed func(time.Weekday) bool, slots *slotModel) time.Time {
+	best := findNextAvailableTime(start, duration, fixedEvents, allowed)
+	if !best.Before(end) {
+		return best
+	}
+	score := func(t time.Time) float64 {
+		return slots.probability(t) - slotLatenessPenalty*t.Sub(start).Hours()/24
+	}
+	bestScore := score(best)
+
+	// 以降の毎時0分から探し直して、候補を比べる (同じ点数なら早い枠のまま)
+	for t := start.Truncate(time.Hour).Add(time.Hour); t.Before(end); t = t.Add(time.Hour) {
+		candidate := findNextAvailableTime(t, duration, fixedEvents, allowed)
+		if !candidate.Before(end) {
+			break
+		}
+		if sc := score(candidate); sc > bestScore {
+			best, bestScore = candidate, sc
+		}
+	}
+	return best
+}
+
+// Insights ... 曜日・時間帯ごとの実績と確率をまとめる
+func (s *service) Insights(userID string, opts InsightsOptions) (*Insights, error) {
+	if opts.Days == 0 {
+		opts.Days = DefaultInsightDays
+	}
+	if opts.Days < 1 || opts.Days > MaxInsightDays {
+		return nil, ErrInvalidInsightsOption
+	}
+
+	now := time.Now()
+	from := now.AddDate(0, 0, -opts.Days)
+	m, err := s.learnSlots(userID, from, now)
+	if err != nil {
+		return nil, err
+	}
+
+	ins := &Insights{GeneratedAt: now, From: from, Overall: m.overall}
+	for wd, st := range m.weekday {
+		ins.ByWeekday = append(ins.ByWeekday, &WeekdayInsight{Weekday: weekdayKeys[wd], SlotStat: st})
+	}
+	for h := firstSlotHour; h <= lastSlotHour; h++ {
+		ins.ByHour = append(ins.ByHour, &HourInsight{Hour: h, SlotStat: m.hour[h]})
+	}
+
+	tried := []*SlotRef{}
+	for wd := range m.cell {
+		row := &HeatmapRow{Weekday: weekdayKeys[wd]}
+		for h := firstSlotHour; h <= lastSlotHour; h++ {
+			st := m.cell[wd][h]
+			row.Hours = append(row.Hours, &HourInsight{Hour: h, SlotStat: st})
+			if st.Scheduled > 0 {
+				tried = append(tried, &SlotRef{Weekday: weekdayKeys[wd], Hour: h, Probability: st.Probability, Scheduled: st.Scheduled})
+			}
+		}
+		ins.Heatmap = append(ins.Heatmap, row)
+	}
+
+	// おすすめは全体より確率の高い枠、避けたい枠は低い枠から選ぶ (同じ確率なら実績の多い枠を先に)
+	sort.SliceStable(tried, func(i, j int) bool {
+		if tried[i].Probability != tried[j].Probability {
+			return tried[i].Probability > tried[j].Probability
+		}
+		return tried[i].Scheduled > tried[j].Scheduled
+	})
+	ins.BestSlots, ins.WorstSlots = []*SlotRef{}, []*SlotRef{}
+	for _, ref := range tried {
+		if ref.Probability > m.overall.Probability && len(ins.BestSlots) < InsightSlotCount {
+			ins.BestSlots = append(ins.BestSlots, ref)
+		}
+	}
+	for i := len(tried) - 1; i >= 0; i-- {
+		if tried[i].Probability < m.overall.Probability && len(ins.WorstSlots) < InsightSlotCount {
+			ins.WorstSlots = append(ins.WorstSlots, tried[i])
+		}
+	}
+	return ins, nil
+}
@@ -0,0 +1,141 @@
+package calendar
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// at ... 2026-01-05 (月) から day 日後の h 時 (サーバーのタイムゾーン)
+func at(day int, h int) time.Time {
+	return time.Date(2026, time.January, 5+day, h, 0, 0, 0, time.Local)
+}
+
+// preferSlots ... 指定した枠だけ確率を持つ slotModel を作る
+func preferSlots(p map[time.Time]float64) *slotModel {
+	m := &slotModel{}
+	for t, prob := range p {
+		wd, h := slotOf(t)
+		m.cell[wd][h].Probability = prob
+	}
+	return m
+}
+
+func TestCountSlots(t *testing.T) {
+	schedule := func(start time.Time, status string) Schedule {
+		return Schedule{StartTime: start, EndTime: start.Add(2 * time.Hour), Status: status}
+	}
+	schedules := []Schedule{
+		schedule(at(0, 20), ScheduleStatusCompleted),
+		schedule(at(0, 20), ScheduleStatusCompleted),
+		schedule(at(0, 9), ScheduleStatusSkipped),
+		schedule(at(0, 9), ScheduleStatusPending),     // 終了時刻を過ぎたので、プレイされなかったとみなす
+		schedule(at(6, 22), ScheduleStatusPending),    // まだ終わっていない
+		schedule(at(1, 20), ScheduleStatusCancelled),  // 取り消しは数えない
+		schedule(at(-1, 20), ScheduleStatusCompleted), // from より前
+	}
+	m := countSlots(schedules, at(0, 0), at(7, 0))
+
+	want := SlotStat{Scheduled: 4, Completed: 2, Skipped: 1, Missed: 1, Probability: 0.5}
+	if m.overall != want {
+		t.Errorf("overall = %+v, want %+v", m.overall, want)
+	}
+	mon := int(time.Monday)
+	if st := m.cell[mon][20]; st.Scheduled != 2 || st.Completed != 2 {
+		t.Errorf("monday 20:00 = %+v, want 2 scheduled and 2 completed", st)
+	}
+
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{name: "monday", got: m.weekday[mon].Probability, want: 0.5},
+		{name: "20:00", got: m.hour[20].Probability, want: 4.0 / 6},
+		{name: "9:00", got: m.hour[9].Probability, want: 2.0 / 6},
+		{name: "monday 20:00", got: m.cell[mon][20].Probability, want: (2 + 4*4.0/6) / 6},
+		{name: "monday 9:00", got: m.cell[mon][9].Probability, want: 4 * 2.0 / 6 / 6},
+		{name: "slot without results", got: m.cell[int(time.Tuesday)][15].Probability, want: 0.5},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > 1e-9 {
+			t.Errorf("%s: probability = %.4f, want %.4f", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestCountSlotsWithoutResults(t *testing.T) {
+	m := countSlots(nil, at(0, 0), at(7, 0))
+	if m.overall.Probability != 0.5 || m.probability(at(2, 14)) != 0.5 {
+		t.Errorf("probability = %.4f, %.4f; want 0.5", m.overall.Probability, m.probability(at(2, 14)))
+	}
+}
+
+func TestFindPreferredTime(t *testing.T) {
+	anyDay := func(time.Weekday) bool { return true }
+	tests := []struct {
+		name        string
+		end         time.Time
+		fixedEvents []FixedEvent
+		allowed     func(time.Weekday) bool
+		slots       *slotModel
+		want        time.Time
+	}{
+		{
+			name:  "earliest slot without preference",
+			slots: &slotModel{},
+			want:  at(0, 9),
+		},
+		{
+			name:  "slot with higher probability",
+			slots: preferSlots(map[time.Time]float64{at(0, 20): 0.9}),
+			want:  at(0, 20),
+		},
+		{
+			name:        "busy preferred slot falls back to the earliest",
+			fixedEvents: []FixedEvent{{StartTime: at(0, 19), EndTime: at(0, 23)}},
+			slots:       preferSlots(map[time.Time]float64{at(0, 20): 0.9}),
+			want:        at(0, 9),
+		},
+		{
+			name:  "small gain does not outweigh a day of delay",
+			end:   at(2, 0),
+			slots: preferSlots(map[time.Time]float64{at(0, 9): 0.5, at(1, 9): 0.52}),
+			want:  at(0, 9),
+		},
+		{
+			name:  "large gain is worth a day of delay",
+			end:   at(2, 0),
+			slots: preferSlots(map[time.Time]float64{at(0, 9): 0.5, at(1, 9): 0.6}),
+			want:  at(1, 9),
+		},
+		{
+			name:    "weekday rules are followed",
+			end:     at(2, 0),
+			allowed: func(wd time.Weekday) bool { return wd == time.Tuesday },
+			slots:   preferSlots(map[time.Time]float64{at(0, 20): 0.9}),
+			want:    at(1, 9),
+		},
+		{
+			name:        "no free slot before end",
+			fixedEvents: []FixedEvent{{StartTime: at(0, 9), EndTime: at(0, 23)}},
+			slots:       &slotModel{},
+			want:        at(1, 9),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, allowed := tt.end, tt.allowed
+			if end.IsZero() {
+				end = at(1, 0)
+			}
+			if allowed == nil {
+				allowed = anyDay
+			}
+			got := findPreferredTime(at(0, 9), end, 2*time.Hour, tt.fixedEvents, allowed, tt.slots)
+			if !got.Equal(tt.want) {
+				t.Errorf("findPreferredTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
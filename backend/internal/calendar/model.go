@@ -1,6 +1,10 @@
 package calendar
 
-import "time"
+import (
+	"time"
+
+	"TO-DO-IT/internal/mergepatch"
+)
 
 // FixedEvent (固定予定) [cite: 56-58, 81]
 // ユーザーが手動で登録する、スケジュール自動生成時に考慮すべき予定（仕事、授業など）
@@ -22,3 +26,182 @@ type Schedule struct {
 	EndTime   time.Time `json:"end_time"`   // プレイ終了予定時刻
 	Status    string    `json:"status"`     // "予定", "完了", "スキップ" [cite: 48, 73]
 }
+
+// スケジュールのステータス
+const (
+	ScheduleStatusPending   = "pending"   // 予定
+	ScheduleStatusCompleted = "completed" // 完了
+	ScheduleStatusSkipped   = "skipped"   // スキップ
+	ScheduleStatusCancelled = "cancelled" // ゲームのクリア・中断により取り消し
+)
+
+// ScheduleRequest ... スケジュールの置き換え (PUT /api/calendar/schedule/:id) のリクエストボディ
+// PUT は全体の置き換えなので、すべての項目が必須
+type ScheduleRequest struct {
+	GameID    string    `json:"game_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Status    string    `json:"status"`
+}
+
+// SchedulePatch ... スケジュールの部分更新 (PATCH /api/calendar/schedule/:id) のリクエストボディ
+// JSON Merge Patch (RFC 7396) 形式。省略した項目は変更しない。どの項目も消せないので null はエラー
+type SchedulePatch struct {
+	GameID    mergepatch.Field[string]    `json:"game_id"`
+	StartTime mergepatch.Field[time.Time] `json:"start_time"`
+	EndTime   mergepatch.Field[time.Time] `json:"end_time"`
+	Status    mergepatch.Field[string]    `json:"status"`
+}
+
+// FixedEventRequest ... 固定予定の置き換え (PUT /api/calendar/fixed-events/:id) のリクエストボディ
+type FixedEventRequest struct {
+	Title     string    `json:"title"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// FixedEventPatch ... 固定予定の部分更新 (PATCH /api/calendar/fixed-events/:id) のリクエストボディ
+type FixedEventPatch struct {
+	Title     mergepatch.Field[string]    `json:"title"`
+	StartTime mergepatch.Field[time.Time] `json:"start_time"`
+	EndTime   mergepatch.Field[time.Time] `json:"end_time"`
+}
+
+// SessionCounts ... 期間内のスケジュール (プレイセッション) の集計 (GET /api/stats で使う)
+type SessionCounts struct {
+	Pending   int `json:"pending"`
+	Completed int `json:"completed"`
+	Skipped   int `json:"skipped"`
+	Cancelled int `json:"cancelled"`
+	// PlannedHours ... 取り消し以外のスケジュールの合計時間
+	PlannedHours float64 `json:"planned_hours"`
+	// PlayedHours ... 完了したスケジュールの合計時間 (プレイセッションで実際の時間を記録したものを除く)
+	PlayedHours float64 `json:"played_hours"`
+}
+
+// ForecastOptions ... 積みゲー消化予定日の見積もり (GET /api/calendar/forecast) の条件
+type ForecastOptions struct {
+	// SessionMinutes ... 1回のプレイ時間 (分)。GenerateSchedule と同じ2時間が既定
+	SessionMinutes int `json:"session_minutes"`
+	// DailyMinutes ... 1日にプレイできる時間 (分) の上限
+	DailyMinutes int `json:"daily_minutes"`
+	// HorizonDays ... 何日先まで見積もるか。これより先に終わるゲームは予定日なし
+	HorizonDays int `json:"horizon_days"`
+}
+
+// 見積もりの条件の既定値と上限
+const (
+	DefaultSessionMinutes = 120
+	DefaultDailyMinutes   = 120
+	DefaultHorizonDays    = 365
+	MaxHorizonDays        = 5 * 365
+)
+
+// ForecastAssumptions ... 見積もりに使った条件と、スケジュールの実績から求めた割合
+type ForecastAssumptions struct {
+	ForecastOptions
+	// 実績 (これまでのスケジュールのうち、完了・スキップしたもの)
+	CompletedSessions int `json:"completed_sessions"`
+	SkippedSessions   int `json:"skipped_sessions"`
+	// PlayRate ... 予定したセッションを実際にプレイする割合 (完了 / (完了 + スキップ))。実績がなければ1とみなす
+	PlayRate float64 `json:"play_rate"`
+	// PlayRateLow / PlayRateHigh ... PlayRate の幅 (80%の信頼区間)。予定日の幅はこれで見積もる
+	PlayRateLow  float64 `json:"play_rate_low"`
+	PlayRateHigh float64 `json:"play_rate_high"`
+}
+
+// 見積もれなかった理由
+const (
+	ForecastReasonNoEstimate    = "no_estimate"    // 推定プレイ時間が入っていない
+	ForecastReasonNoWeekday     = "no_weekday"     // タグのルールで、予定を入れられる曜日がない
+	ForecastReasonBlocked       = "blocked"        // 本編・前作が見積もれないため、始められない
+	ForecastReasonBeyondHorizon = "beyond_horizon" // 見積もる期間内に終わらない
+)
+
+// GameForecast ... ゲームごとの見積もり
+type GameForecast struct {
+	GameID         int     `json:"game_id"`
+	Title          string  `json:"title"`
+	Status         string  `json:"status"`
+	RemainingHours float64 `json:"remaining_hours"` // 推定プレイ時間 - プレイ時間
+	Sessions       int     `json:"sessions"`        // 終わるまでに予定するセッション数 (PlayRate のとき)
+	// StartDate ... 最初のセッションの開始予定 (PlayRate のとき)
+	StartDate *time.Time `json:"start_date"`
+	// ProjectedCompletion ... 終わる予定 (PlayRate のとき)。Earliest / Latest は PlayRateHigh / PlayRateLow のとき
+	ProjectedCompletion *time.Time `json:"projected_completion"`
+	Earliest            *time.Time `json:"earliest"`
+	Latest              *time.Time `json:"latest"`
+	Reason              string     `json:"reason,omitempty"` // 見積もれなかった理由
+}
+
+// Forecast ... GET /api/calendar/forecast のレスポンス
+type Forecast struct {
+	GeneratedAt time.Time           `json:"generated_at"`
+	Assumptions ForecastAssumptions `json:"assumptions"`
+	// ClearedAt ... 積みゲー (未開始・プレイ中・中断中のゲーム) がすべて終わる予定。見積もれないゲームがあれば null
+	ClearedAt       *time.Time      `json:"cleared_at"`
+	ClearedEarliest *time.Time      `json:"cleared_earliest"`
+	ClearedLatest   *time.Time      `json:"cleared_latest"`
+	Games           []*GameForecast `json:"games"` // 終わる順 (見積もれなかったゲームは最後)
+}
+
+// InsightsOptions ... 時間帯ごとの実績 (GET /api/calendar/insights) の条件
+type InsightsOptions struct {
+	// Days ... 何日前までのスケジュールから求めるか
+	Days int `json:"days"`
+}
+
+// 実績を見る期間の既定値と上限 (GenerateSchedule は既定値を使う)
+const (
+	DefaultInsightDays = 90
+	MaxInsightDays     = 365
+)
+
+// SlotStat ... ある枠 (全体・曜日・時間帯・曜日×時間帯) に予定したスケジュールの結果と、予定どおりにプレイする確率
+type SlotStat struct {
+	Scheduled int `json:"scheduled"` // Completed + Skipped + Missed
+	Completed int `json:"completed"`
+	Skipped   int `json:"skipped"`
+	Missed    int `json:"missed"` // 完了もスキップもされないまま、終了時刻を過ぎた予定
+	// Probability ... 完了する確率。実績が少ない枠は、曜日・時間帯全体の割合に寄せて求める
+	Probability float64 `json:"probability"`
+}
+
+// WeekdayInsight ... 曜日ごとの実績
+type WeekdayInsight struct {
+	Weekday string `json:"weekday"` // sun, mon, ... sat (タグの schedule_days と同じ表記)
+	SlotStat
+}
+
+// HourInsight ... 開始時刻 (時) ごとの実績
+type HourInsight struct {
+	Hour int `json:"hour"` // 9〜22 (スケジュールを入れる時間帯)
+	SlotStat
+}
+
+// HeatmapRow ... ヒートマップの1行 (1つの曜日の、時間帯ごとの実績)
+type HeatmapRow struct {
+	Weekday string         `json:"weekday"`
+	Hours   []*HourInsight `json:"hours"`
+}
+
+// SlotRef ... 曜日と開始時刻の組 (おすすめ・避けたい枠)
+type SlotRef struct {
+	Weekday     string  `json:"weekday"`
+	Hour        int     `json:"hour"`
+	Probability float64 `json:"probability"`
+	Scheduled   int     `json:"scheduled"`
+}
+
+// Insights ... GET /api/calendar/insights のレスポンス
+type Insights struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	From        time.Time         `json:"from"` // この時刻以降に始まったスケジュールから求めた
+	Overall     SlotStat          `json:"overall"`
+	ByWeekday   []*WeekdayInsight `json:"by_weekday"` // 日曜から
+	ByHour      []*HourInsight    `json:"by_hour"`
+	Heatmap     []*HeatmapRow     `json:"heatmap"` // 曜日×時間帯 (GenerateSchedule はこの確率の高い枠を優先する)
+	// BestSlots / WorstSlots ... 実績のある枠のうち、全体より確率の高い・低い枠を、高い・低い順に InsightSlotCount 件まで
+	BestSlots  []*SlotRef `json:"best_slots"`
+	WorstSlots []*SlotRef `json:"worst_slots"`
+}
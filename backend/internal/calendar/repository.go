@@ -10,22 +10,74 @@ type Repository interface {
 	// 固定予定 (FixedEvent)
 	GetFixedEventsByUserID(userID string, start time.Time, end time.Time) ([]FixedEvent, error)
 	CreateFixedEvent(event *FixedEvent) error
-	// ... (UpdateFixedEvent, DeleteFixedEvent も必要) [cite: 83-84]
+	GetFixedEventByID(eventID string) (*FixedEvent, error)
+	UpdateFixedEvent(event *FixedEvent) error
+	// ... (DeleteFixedEvent も必要) [cite: 83-84]
 
 	// スケジュール (Schedule)
 	GetSchedulesByUserID(userID string, start time.Time, end time.Time) ([]Schedule, error)
+	GetScheduleByID(scheduleID string) (*Schedule, error)
 	CreateSchedules(schedules []Schedule) error
 	UpdateScheduleStatus(scheduleID string, status string) error // [cite: 73]
+	// UpdateSchedule ... ゲーム・時間・ステータスをまとめて更新する
+	UpdateSchedule(schedule *Schedule) error
+	// CancelPendingSchedulesByGameID ... ゲームの未実施(pending)スケジュールを取り消し、件数を返す
+	CancelPendingSchedulesByGameID(gameID string) (int64, error)
+	// DeletePendingSchedulesByGameID ... ゲームの未実施(pending)スケジュールを削除し、件数を返す
+	DeletePendingSchedulesByGameID(gameID string) (int64, error)
+	// MoveSchedules ... ゲームのスケジュールをすべて別のゲームに付け替え、件数を返す (ゲームの統合用)
+	MoveSchedules(fromGameID string, toGameID string) (int64, error)
+	// GetSessionCounts ... 開始時刻が from 以上 to 未満のスケジュールを、ステータスごとに数えて時間を合計する
+	GetSessionCounts(userID string, from time.Time, to time.Time) (*SessionCounts, error)
+	// GetRecentSchedulesByStatus ... 開始時刻が since 以降で、ステータスが status のスケジュールを新しい順に返す
+	GetRecentSchedulesByStatus(userID string, status string, since time.Time) ([]Schedule, error)
+
+	// トランザクション
+	// WithTx ... game など他パッケージと同じトランザクション上で動くリポジトリを返す
+	WithTx(tx *sql.Tx) Repository
+	// RunInTx ... fn をトランザクション内で実行する。fn がエラーを返すとロールバック
+	RunInTx(fn func(tx *sql.Tx) error) error
+}
+
+// querier ... *sql.DB と *sql.Tx の共通メソッド
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
 }
 
 // postgresRepository (実装)
 type postgresRepository struct {
 	db *sql.DB
+	tx *sql.Tx // トランザクション中のみ
+	q  querier // 実際にSQLを発行する先 (db か tx)
 }
 
 // NewRepository ... DB接続を受け取り、リポジトリを初期化
 func NewRepository(db *sql.DB) Repository {
-	return &postgresRepository{db: db}
+	return &postgresRepository{db: db, q: db}
+}
+
+func (r *postgresRepository) WithTx(tx *sql.Tx) Repository {
+	return &postgresRepository{db: r.db, tx: tx, q: tx}
+}
+
+func (r *postgresRepository) RunInTx(fn func(tx *sql.Tx) error) error {
+	// すでにトランザクション中ならそのまま使う
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // --- 固定予定 (FixedEvent) の実装 ---
@@ -36,7 +88,7 @@ func (r *postgresRepository) GetFixedEventsByUserID(userID string, start time.Ti
 			  WHERE user_id = ? AND start_time < ? AND end_time > ?
 			  ORDER BY start_time`
 
-	rows, err := r.db.Query(query, userID, end, start)
+	rows, err := r.q.Query(query, userID, end, start)
 	if err != nil {
 		return nil, err
 	}
@@ -62,7 +114,29 @@ func (r *postgresRepository) CreateFixedEvent(event *FixedEvent) error {
 	query := `INSERT INTO fixed_events (id, user_id, title, start_time, end_time)
 			  VALUES (?, ?, ?, ?, ?)`
 
-	_, err := r.db.Exec(query, event.ID, event.UserID, event.Title, event.StartTime, event.EndTime)
+	_, err := r.q.Exec(query, event.ID, event.UserID, event.Title, event.StartTime, event.EndTime)
+	return err
+}
+
+func (r *postgresRepository) GetFixedEventByID(eventID string) (*FixedEvent, error) {
+	query := `SELECT id, user_id, title, start_time, end_time
+			  FROM fixed_events WHERE id = ?`
+
+	var event FixedEvent
+	err := r.q.QueryRow(query, eventID).Scan(&event.ID, &event.UserID, &event.Title, &event.StartTime, &event.EndTime)
+	if err == sql.ErrNoRows {
+		return nil, nil // 見つからない
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func (r *postgresRepository) UpdateFixedEvent(event *FixedEvent) error {
+	query := `UPDATE fixed_events SET title = ?, start_time = ?, end_time = ? WHERE id = ?`
+	_, err := r.q.Exec(query, event.Title, event.StartTime, event.EndTime, event.ID)
 	return err
 }
 
@@ -74,7 +148,7 @@ func (r *postgresRepository) GetSchedulesByUserID(userID string, start time.Time
 			  WHERE user_id = ? AND start_time < ? AND end_time > ?
 			  ORDER BY start_time`
 
-	rows, err := r.db.Query(query, userID, end, start)
+	rows, err := r.q.Query(query, userID, end, start)
 	if err != nil {
 		return nil, err
 	}
@@ -96,39 +170,138 @@ func (r *postgresRepository) GetSchedulesByUserID(userID string, start time.Time
 	return schedules, nil
 }
 
-func (r *postgresRepository) CreateSchedules(schedules []Schedule) error {
-	if len(schedules) == 0 {
-		return nil
-	}
+func (r *postgresRepository) GetRecentSchedulesByStatus(userID string, status string, since time.Time) ([]Schedule, error) {
+	query := `SELECT id, user_id, game_id, start_time, end_time, status
+			  FROM schedules
+			  WHERE user_id = ? AND status = ? AND julianday(start_time) >= julianday(?)
+			  ORDER BY julianday(start_time) DESC`
 
-	// トランザクション開始
-	tx, err := r.db.Begin()
+	rows, err := r.q.Query(query, userID, status, since)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []Schedule
+	for rows.Next() {
+		var schedule Schedule
+		if err := rows.Scan(&schedule.ID, &schedule.UserID, &schedule.GameID, &schedule.StartTime, &schedule.EndTime, &schedule.Status); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
-	defer tx.Rollback()
 
-	query := `INSERT INTO schedules (id, user_id, game_id, start_time, end_time, status)
-			  VALUES (?, ?, ?, ?, ?, ?)`
+	return schedules, nil
+}
 
-	stmt, err := tx.Prepare(query)
-	if err != nil {
-		return err
+func (r *postgresRepository) CreateSchedules(schedules []Schedule) error {
+	if len(schedules) == 0 {
+		return nil
 	}
-	defer stmt.Close()
 
-	for _, schedule := range schedules {
-		_, err := stmt.Exec(schedule.ID, schedule.UserID, schedule.GameID, schedule.StartTime, schedule.EndTime, schedule.Status)
+	// トランザクション開始 (すでにトランザクション中ならその中で実行)
+	return r.RunInTx(func(tx *sql.Tx) error {
+		query := `INSERT INTO schedules (id, user_id, game_id, start_time, end_time, status)
+				  VALUES (?, ?, ?, ?, ?, ?)`
+
+		stmt, err := tx.Prepare(query)
 		if err != nil {
 			return err
 		}
+		defer stmt.Close()
+
+		for _, schedule := range schedules {
+			_, err := stmt.Exec(schedule.ID, schedule.UserID, schedule.GameID, schedule.StartTime, schedule.EndTime, schedule.Status)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *postgresRepository) GetScheduleByID(scheduleID string) (*Schedule, error) {
+	query := `SELECT id, user_id, game_id, start_time, end_time, status
+			  FROM schedules WHERE id = ?`
+
+	var schedule Schedule
+	err := r.q.QueryRow(query, scheduleID).Scan(&schedule.ID, &schedule.UserID, &schedule.GameID, &schedule.StartTime, &schedule.EndTime, &schedule.Status)
+	if err == sql.ErrNoRows {
+		return nil, nil // 見つからない
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return tx.Commit()
+	return &schedule, nil
 }
 
 func (r *postgresRepository) UpdateScheduleStatus(scheduleID string, status string) error {
 	query := `UPDATE schedules SET status = ? WHERE id = ?`
-	_, err := r.db.Exec(query, status, scheduleID)
+	_, err := r.q.Exec(query, status, scheduleID)
+	return err
+}
+
+func (r *postgresRepository) UpdateSchedule(schedule *Schedule) error {
+	query := `UPDATE schedules SET game_id = ?, start_time = ?, end_time = ?, status = ? WHERE id = ?`
+	_, err := r.q.Exec(query, schedule.GameID, schedule.StartTime, schedule.EndTime, schedule.Status, schedule.ID)
 	return err
 }
+
+func (r *postgresRepository) CancelPendingSchedulesByGameID(gameID string) (int64, error) {
+	query := `UPDATE schedules SET status = ? WHERE game_id = ? AND status = ?`
+	result, err := r.q.Exec(query, ScheduleStatusCancelled, gameID, ScheduleStatusPending)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *postgresRepository) DeletePendingSchedulesByGameID(gameID string) (int64, error) {
+	query := `DELETE FROM schedules WHERE game_id = ? AND status = ?`
+	result, err := r.q.Exec(query, gameID, ScheduleStatusPending)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// MoveSchedules ... 完了・スキップ済みの実績も含めて、すべてのスケジュールを付け替える
+func (r *postgresRepository) MoveSchedules(fromGameID string, toGameID string) (int64, error) {
+	query := `UPDATE schedules SET game_id = ? WHERE game_id = ?`
+	result, err := r.q.Exec(query, toGameID, fromGameID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetSessionCounts ... スケジュールの件数と時間を SQL で集計する
+func (r *postgresRepository) GetSessionCounts(userID string, from time.Time, to time.Time) (*SessionCounts, error) {
+	// 1件あたりの時間 (時間単位)。julianday の誤差が出るので、合計は小数第2位で丸める
+	const hours = `(julianday(end_time) - julianday(start_time)) * 24`
+	// タイマーで記録したスケジュールは、実際の時間を task パッケージ側で数えるので、予定の時間では数えない
+	const tracked = `SELECT 1 FROM play_sessions p WHERE p.schedule_id = schedules.id AND p.status IN ('finished', 'auto_closed')`
+	query := `SELECT
+			COALESCE(SUM(status = 'pending'), 0),
+			COALESCE(SUM(status = 'completed'), 0),
+			COALESCE(SUM(status = 'skipped'), 0),
+			COALESCE(SUM(status = 'cancelled'), 0),
+			ROUND(COALESCE(SUM(CASE WHEN status <> 'cancelled' THEN ` + hours + ` END), 0), 2),
+			ROUND(COALESCE(SUM(CASE WHEN status = 'completed' AND NOT EXISTS (` + tracked + `) THEN ` + hours + ` END), 0), 2)
+		FROM schedules
+		WHERE user_id = ? AND julianday(start_time) >= julianday(?) AND julianday(start_time) < julianday(?)`
+
+	var c SessionCounts
+	err := r.q.QueryRow(query, userID, from, to).Scan(
+		&c.Pending, &c.Completed, &c.Skipped, &c.Cancelled, &c.PlannedHours, &c.PlayedHours,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -2,37 +2,88 @@ package calendar
 
 import (
 	"TO-DO-IT/internal/game" // 担当Cのゲームパッケージ (仮)
+	"TO-DO-IT/internal/mergepatch"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log"
+	"sort"
 	"strconv"
 	"time"
 )
 
+var (
+	ErrScheduleNotFound      = errors.New("schedule not found")
+	ErrInvalidScheduleStatus = errors.New("status must be one of pending, completed, skipped, cancelled")
+	ErrFixedEventNotFound    = errors.New("fixed event not found")
+	ErrRequiredField         = errors.New("required field is missing or null")
+	ErrInvalidTimeRange      = errors.New("end_time must be after start_time")
+	ErrGameNotFound          = errors.New("game_id does not match any game")
+	ErrInvalidForecastOption = errors.New("session_minutes and daily_minutes must be 1-1440, horizon_days must be 1-1825")
+	ErrInvalidInsightsOption = errors.New("days must be 1-365")
+)
+
 // Service (インターフェース)
 type Service interface {
 	// 自動生成ロジック [cite: 71]
 	GenerateSchedule(userID string) ([]Schedule, error)
+	// Forecast ... 同じ枠の探し方で先まで割り当て、積みゲーがすべて終わる日とゲームごとの終わる日を見積もる (保存はしない)
+	Forecast(userID string, opts ForecastOptions) (*Forecast, error)
+	// Insights ... スケジュールの結果 (完了・スキップ・放置) から、曜日・時間帯ごとにプレイする確率を求める
+	// GenerateSchedule は、この確率の高い枠を優先する
+	Insights(userID string, opts InsightsOptions) (*Insights, error)
 
 	// スケジュール取得
 	GetSchedules(userID string, start time.Time, end time.Time) ([]Schedule, error)
 	// スケジュール進捗更新
 	UpdateScheduleStatus(scheduleID string, status string) error
+	// ReplaceSchedule ... スケジュールを置き換える (PUT)。すべての項目が必須
+	ReplaceSchedule(scheduleID string, req *ScheduleRequest) (*Schedule, error)
+	// PatchSchedule ... スケジュールを部分的に更新する (PATCH, JSON Merge Patch)
+	PatchSchedule(scheduleID string, patch *SchedulePatch) (*Schedule, error)
 
 	// 固定予定
 	GetFixedEvents(userID string, start time.Time, end time.Time) ([]FixedEvent, error)
 	CreateFixedEvent(event *FixedEvent) error
+	// ReplaceFixedEvent ... 固定予定を置き換える (PUT)。すべての項目が必須
+	ReplaceFixedEvent(eventID string, req *FixedEventRequest) (*FixedEvent, error)
+	// PatchFixedEvent ... 固定予定を部分的に更新する (PATCH, JSON Merge Patch)
+	PatchFixedEvent(eventID string, patch *FixedEventPatch) (*FixedEvent, error)
+
+	// game.StatusListener の実装
+	// ゲームがクリア・中断されたら、残りの予定を取り消す
+	OnGameStatusChanged(tx *sql.Tx, g *game.Game, from string, to string) error
+	// game.PurgeListener の実装
+	// ゲームが完全に削除されたら、未実施の予定を消す (完了・スキップ済みは実績として残す)
+	OnGamePurged(tx *sql.Tx, g *game.Game) error
+	// game.MergeListener の実装
+	// ゲームが統合されたら、統合元のスケジュールを統合先に付け替える
+	OnGamesMerged(tx *sql.Tx, target *game.Game, source *game.Game) error
+
+	// SetDropCandidateProvider ... 「やめる候補」のゲームの取得先を設定する (GenerateSchedule で後回しにする)
+	SetDropCandidateProvider(p DropCandidateProvider)
+}
+
+// DropCandidateProvider ... 楽しめていない・進んでいない「やめる候補」のゲームを、他パッケージ (prune) から取得する
+// prune は calendar のリポジトリを使うので、calendar から import すると循環する
+type DropCandidateProvider interface {
+	DropCandidateIDs(userID string) (map[int]bool, error)
 }
 
 // service (実装)
 type service struct {
 	calendarRepo Repository
-	gameRepo     game.Repository // 担当Cのゲームリポジトリ (仮)
+	gameRepo     game.Repository       // 担当Cのゲームリポジトリ (仮)
+	gameSvc      game.Service          // ステータス変更 (履歴つき) に使う
+	dropper      DropCandidateProvider // やめる候補の取得先。未設定なら後回しにしない
 }
 
 // NewService ... 必要なリポジトリを受け取り、サービスを初期化
-func NewService(calRepo Repository, gameRepo game.Repository) Service {
+func NewService(calRepo Repository, gameRepo game.Repository, gameSvc game.Service) Service {
 	return &service{
 		calendarRepo: calRepo,
 		gameRepo:     gameRepo,
+		gameSvc:      gameSvc,
 	}
 }
 
@@ -47,7 +98,7 @@ func (s *service) GenerateSchedule(userID string) ([]Schedule, error) {
 	// 未開始のゲームのみをフィルタリング
 	var unstartedGames []*game.Game
 	for _, g := range games {
-		if g.Status == "unstarted" {
+		if g.Status == game.StatusUnstarted {
 			unstartedGames = append(unstartedGames, g)
 		}
 	}
@@ -56,6 +107,23 @@ func (s *service) GenerateSchedule(userID string) ([]Schedule, error) {
 		return []Schedule{}, nil // 未開始ゲームがない場合は空を返す
 	}
 
+	// DLC・続編は、本編・前作がクリアされるまでスケジュールしない
+	blocked, err := s.gameSvc.BlockedGames()
+	if err != nil {
+		return nil, err
+	}
+
+	// やめる候補のゲームは、ほかのゲームが良い枠を取った後に回す
+	if s.dropper != nil {
+		flagged, err := s.dropper.DropCandidateIDs(userID)
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(unstartedGames, func(i, j int) bool {
+			return !flagged[unstartedGames[i].ID] && flagged[unstartedGames[j].ID]
+		})
+	}
+
 	// 2. 今後1週間分の固定予定を取得
 	start := time.Now()
 	end := start.Add(7 * 24 * time.Hour)
@@ -64,16 +132,34 @@ func (s *service) GenerateSchedule(userID string) ([]Schedule, error) {
 		return nil, err
 	}
 
+	// これまでのスケジュールの結果から、曜日・時間帯ごとにプレイする確率を求める
+	slots, err := s.learnSlots(userID, start.AddDate(0, 0, -DefaultInsightDays), start)
+	if err != nil {
+		return nil, err
+	}
+
 	// 3. スケジュールを生成（シンプルなアルゴリズム）
+	// 割り当て済みの枠は固定予定と同じように扱い、後のゲームと重ならないようにする
 	var newSchedules []Schedule
-	currentTime := start
+	busy := append([]FixedEvent{}, fixedEvents...)
 
 	for idx, g := range unstartedGames {
+		if before := blocked[g.ID]; len(before) > 0 {
+			log.Printf("Calendar: game %d is waiting for games %v to be completed, skipped", g.ID, before)
+			continue
+		}
+		// タグのルール（例: 週末タグは土日のみ）で、入れられる曜日が1つもないゲームは飛ばす
+		if !canScheduleAnyDay(g) {
+			log.Printf("Calendar: game %d has no schedulable weekday (tags conflict), skipped", g.ID)
+			continue
+		}
+
 		// 各ゲームに2時間のプレイ時間を割り当て
 		playDuration := 2 * time.Hour
 
-		// 固定予定と重ならない時間を探す
-		scheduleTime := findNextAvailableTime(currentTime, playDuration, fixedEvents)
+		// 固定予定・割り当て済みの枠と重ならず、タグで許可された曜日の時間のうち、
+		// よくスキップされる枠を避けて、プレイする確率の高い枠を探す
+		scheduleTime := findPreferredTime(start, end, playDuration, busy, g.CanScheduleOn, slots)
 
 		// スケジュールを作成
 		schedule := Schedule{
@@ -82,12 +168,12 @@ func (s *service) GenerateSchedule(userID string) ([]Schedule, error) {
 			GameID:    strconv.Itoa(g.ID), // intをstringに変換
 			StartTime: scheduleTime,
 			EndTime:   scheduleTime.Add(playDuration),
-			Status:    "pending",
+			Status:    ScheduleStatusPending,
 		}
 		newSchedules = append(newSchedules, schedule)
 
-		// 次のスケジュールは現在のスケジュール終了後から
-		currentTime = schedule.EndTime
+		// 次のゲームはこの枠を避ける
+		busy = append(busy, FixedEvent{StartTime: schedule.StartTime, EndTime: schedule.EndTime})
 	}
 
 	// 4. 生成したスケジュールをDBに保存
@@ -101,13 +187,20 @@ func (s *service) GenerateSchedule(userID string) ([]Schedule, error) {
 }
 
 // findNextAvailableTime は固定予定と重ならない次の利用可能な時間を見つける
-func findNextAvailableTime(startTime time.Time, duration time.Duration, fixedEvents []FixedEvent) time.Time {
+// allowed が false を返す曜日は避ける（タグの曜日ルール）。呼び出し側で、許可された曜日があることを確認しておくこと
+func findNextAvailableTime(startTime time.Time, duration time.Duration, fixedEvents []FixedEvent, allowed func(time.Weekday) bool) time.Time {
 	proposedTime := startTime
 
 	// 営業時間内に調整（9:00-23:00）
 	proposedTime = adjustToBusinessHours(proposedTime)
 
 	for {
+		// 許可されていない曜日なら翌日9時に
+		if !allowed(proposedTime.Weekday()) {
+			proposedTime = time.Date(proposedTime.Year(), proposedTime.Month(), proposedTime.Day()+1, 9, 0, 0, 0, proposedTime.Location())
+			continue
+		}
+
 		proposedEnd := proposedTime.Add(duration)
 		conflict := false
 
@@ -128,6 +221,16 @@ func findNextAvailableTime(startTime time.Time, duration time.Duration, fixedEve
 	}
 }
 
+// canScheduleAnyDay は、ゲームのタグのルール上、予定を入れられる曜日が1つでもあるかを返す
+func canScheduleAnyDay(g *game.Game) bool {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if g.CanScheduleOn(d) {
+			return true
+		}
+	}
+	return false
+}
+
 // timeOverlaps は2つの時間範囲が重なっているかチェック
 func timeOverlaps(start1, end1, start2, end2 time.Time) bool {
 	return start1.Before(end2) && end1.After(start2)
@@ -154,11 +257,135 @@ func (s *service) GetSchedules(userID string, start time.Time, end time.Time) ([
 	return s.calendarRepo.GetSchedulesByUserID(userID, start, end)
 }
 
+// UpdateScheduleStatus ... スケジュールの進捗を更新する
+// 未開始ゲームのスケジュールが完了したら、同じトランザクションでゲームを「プレイ中」にする
 func (s *service) UpdateScheduleStatus(scheduleID string, status string) error {
+	_, err := s.PatchSchedule(scheduleID, &SchedulePatch{Status: mergepatch.Value(status)})
+	return err
+}
+
+// ReplaceSchedule ... スケジュールを置き換える (PUT)
+func (s *service) ReplaceSchedule(scheduleID string, req *ScheduleRequest) (*Schedule, error) {
+	return s.PatchSchedule(scheduleID, &SchedulePatch{
+		GameID:    mergepatch.Value(req.GameID),
+		StartTime: mergepatch.Value(req.StartTime),
+		EndTime:   mergepatch.Value(req.EndTime),
+		Status:    mergepatch.Value(req.Status),
+	})
+}
+
+// PatchSchedule ... パッチに含まれる項目だけ更新する
+// スケジュールの項目はどれも消せないので、null や空の値は ErrRequiredField
+func (s *service) PatchSchedule(scheduleID string, patch *SchedulePatch) (*Schedule, error) {
+	if err := requireField("game_id", patch.GameID.Set, patch.GameID.Null || patch.GameID.Value == ""); err != nil {
+		return nil, err
+	}
+	if err := requireField("start_time", patch.StartTime.Set, patch.StartTime.Null || patch.StartTime.Value.IsZero()); err != nil {
+		return nil, err
+	}
+	if err := requireField("end_time", patch.EndTime.Set, patch.EndTime.Null || patch.EndTime.Value.IsZero()); err != nil {
+		return nil, err
+	}
+	if err := requireField("status", patch.Status.Set, patch.Status.Null || patch.Status.Value == ""); err != nil {
+		return nil, err
+	}
+	if patch.Status.Set {
+		switch patch.Status.Value {
+		case ScheduleStatusPending, ScheduleStatusCompleted, ScheduleStatusSkipped, ScheduleStatusCancelled:
+		default:
+			return nil, ErrInvalidScheduleStatus
+		}
+	}
+
 	// TODO: ステータス更新時に、scoreパッケージ(担当A)のサービスを呼び出し、
 	// ボーナス・ペナルティを発生させる必要がある [cite: 76]
 	// if status == "完了" { s.scoreService.ReportPlayResult(scheduleID, "success") }
-	return s.calendarRepo.UpdateScheduleStatus(scheduleID, status)
+	var schedule *Schedule
+	err := s.calendarRepo.RunInTx(func(tx *sql.Tx) error {
+		calRepo := s.calendarRepo.WithTx(tx)
+
+		var err error
+		schedule, err = calRepo.GetScheduleByID(scheduleID)
+		if err != nil {
+			return err
+		}
+		if schedule == nil {
+			return ErrScheduleNotFound
+		}
+
+		if patch.GameID.Set {
+			schedule.GameID = patch.GameID.Value
+		}
+		if patch.StartTime.Set {
+			schedule.StartTime = patch.StartTime.Value
+		}
+		if patch.EndTime.Set {
+			schedule.EndTime = patch.EndTime.Value
+		}
+		if !schedule.EndTime.After(schedule.StartTime) {
+			return ErrInvalidTimeRange
+		}
+
+		gameID, err := strconv.Atoi(schedule.GameID)
+		if err != nil {
+			if patch.GameID.Set {
+				return ErrGameNotFound
+			}
+			return fmt.Errorf("invalid game_id %q in schedule %s: %w", schedule.GameID, scheduleID, err)
+		}
+		g, err := s.gameRepo.WithTx(tx).GetGameByID(gameID)
+		if err != nil {
+			return err
+		}
+		if g == nil && patch.GameID.Set {
+			return ErrGameNotFound
+		}
+
+		completed := patch.Status.Set && patch.Status.Value == ScheduleStatusCompleted && schedule.Status != ScheduleStatusCompleted
+		if patch.Status.Set {
+			schedule.Status = patch.Status.Value
+		}
+		if err := calRepo.UpdateSchedule(schedule); err != nil {
+			return err
+		}
+
+		// 最初のセッションが終わったゲームは「未開始」ではなくなる
+		// ゲームが削除済みならスケジュールの更新だけ行う
+		if !completed || g == nil || g.Status != game.StatusUnstarted {
+			return nil
+		}
+		_, _, err = s.gameSvc.ChangeStatusInTx(tx, gameID, 0, game.StatusPlaying, "scheduled session completed")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// requireField ... 必須項目がパッチで null や空にされていないかチェックする
+func requireField(name string, set bool, empty bool) error {
+	if set && empty {
+		return fmt.Errorf("%w: %s", ErrRequiredField, name)
+	}
+	return nil
+}
+
+// OnGameStatusChanged ... ゲームがクリア・中断されたら、残っている予定を取り消す
+// game パッケージからステータス変更と同じトランザクションで呼ばれる
+func (s *service) OnGameStatusChanged(tx *sql.Tx, g *game.Game, from string, to string) error {
+	if to != game.StatusCompleted && to != game.StatusDropped {
+		return nil
+	}
+
+	cancelled, err := s.calendarRepo.WithTx(tx).CancelPendingSchedulesByGameID(strconv.Itoa(g.ID))
+	if err != nil {
+		return err
+	}
+	if cancelled > 0 {
+		log.Printf("Calendar: cancelled %d pending schedules for game %d (%s -> %s)", cancelled, g.ID, from, to)
+	}
+	return nil
 }
 
 func (s *service) GetFixedEvents(userID string, start time.Time, end time.Time) ([]FixedEvent, error) {
@@ -169,3 +396,83 @@ func (s *service) CreateFixedEvent(event *FixedEvent) error {
 	// TODO: バリデーションチェック (時間が重複していないか等)
 	return s.calendarRepo.CreateFixedEvent(event)
 }
+
+// ReplaceFixedEvent ... 固定予定を置き換える (PUT)
+func (s *service) ReplaceFixedEvent(eventID string, req *FixedEventRequest) (*FixedEvent, error) {
+	return s.PatchFixedEvent(eventID, &FixedEventPatch{
+		Title:     mergepatch.Value(req.Title),
+		StartTime: mergepatch.Value(req.StartTime),
+		EndTime:   mergepatch.Value(req.EndTime),
+	})
+}
+
+// PatchFixedEvent ... パッチに含まれる項目だけ更新する
+// 固定予定の項目はどれも消せないので、null や空の値は ErrRequiredField
+func (s *service) PatchFixedEvent(eventID string, patch *FixedEventPatch) (*FixedEvent, error) {
+	if err := requireField("title", patch.Title.Set, patch.Title.Null || patch.Title.Value == ""); err != nil {
+		return nil, err
+	}
+	if err := requireField("start_time", patch.StartTime.Set, patch.StartTime.Null || patch.StartTime.Value.IsZero()); err != nil {
+		return nil, err
+	}
+	if err := requireField("end_time", patch.EndTime.Set, patch.EndTime.Null || patch.EndTime.Value.IsZero()); err != nil {
+		return nil, err
+	}
+
+	event, err := s.calendarRepo.GetFixedEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, ErrFixedEventNotFound
+	}
+
+	if patch.Title.Set {
+		event.Title = patch.Title.Value
+	}
+	if patch.StartTime.Set {
+		event.StartTime = patch.StartTime.Value
+	}
+	if patch.EndTime.Set {
+		event.EndTime = patch.EndTime.Value
+	}
+	if !event.EndTime.After(event.StartTime) {
+		return nil, ErrInvalidTimeRange
+	}
+
+	if err := s.calendarRepo.UpdateFixedEvent(event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// OnGamePurged ... ゲームが完全に削除されたら、孤立する未実施の予定を消す
+// game パッケージから削除と同じトランザクションで呼ばれる
+func (s *service) OnGamePurged(tx *sql.Tx, g *game.Game) error {
+	deleted, err := s.calendarRepo.WithTx(tx).DeletePendingSchedulesByGameID(strconv.Itoa(g.ID))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Printf("Calendar: deleted %d pending schedules for purged game %d", deleted, g.ID)
+	}
+	return nil
+}
+
+// OnGamesMerged ... 統合元のゲームのスケジュールを統合先に付け替える
+// game パッケージから統合と同じトランザクションで呼ばれる
+func (s *service) OnGamesMerged(tx *sql.Tx, target *game.Game, source *game.Game) error {
+	moved, err := s.calendarRepo.WithTx(tx).MoveSchedules(strconv.Itoa(source.ID), strconv.Itoa(target.ID))
+	if err != nil {
+		return err
+	}
+	if moved > 0 {
+		log.Printf("Calendar: moved %d schedules from game %d to game %d", moved, source.ID, target.ID)
+	}
+	return nil
+}
+
+// SetDropCandidateProvider ... やめる候補の取得先を設定する
+func (s *service) SetDropCandidateProvider(p DropCandidateProvider) {
+	s.dropper = p
+}
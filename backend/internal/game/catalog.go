@@ -0,0 +1,76 @@
+package game
+
+// Genres は、登録できるジャンルの一覧です。フロントエンドの GameGenre と同じ値・順番にしてください。
+var Genres = []string{"RPG", "アクション", "アドベンチャー", "シミュレーション", "パズル", "スポーツ", "その他"}
+
+// genreAliases は、他サービスからのインポートなどで使われるジャンル名と、Genres の対応です。
+// キーは normalizePlatform と同じ方法で正規化した値です。
+var genreAliases = map[string]string{
+	"roleplaying":    "RPG",
+	"roleplayingrpg": "RPG",
+	"jrpg":           "RPG",
+	"actionrpg":      "RPG",
+	"ロールプレイング":       "RPG",
+	"action":         "アクション",
+	"shooter":        "アクション",
+	"fighting":       "アクション",
+	"platform":       "アクション",
+	"platformer":     "アクション",
+	"hackandslash":   "アクション",
+	"シューティング":        "アクション",
+	"格闘":             "アクション",
+	"adventure":      "アドベンチャー",
+	"visualnovel":    "アドベンチャー",
+	"pointclick":     "アドベンチャー",
+	"adv":            "アドベンチャー",
+	"simulation":     "シミュレーション",
+	"simulator":      "シミュレーション",
+	"strategy":       "シミュレーション",
+	"rts":            "シミュレーション",
+	"slg":            "シミュレーション",
+	"ストラテジー":         "シミュレーション",
+	"puzzle":         "パズル",
+	"sports":         "スポーツ",
+	"sport":          "スポーツ",
+	"racing":         "スポーツ",
+	"レース":            "スポーツ",
+	"other":          "その他",
+	"others":         "その他",
+	"misc":           "その他",
+}
+
+// NormalizeGenre は、ジャンルを Genres のいずれかにそろえます。
+// 空文字は未設定としてそのまま返し、対応するジャンルがなければ ErrInvalidGenre を返します。
+func NormalizeGenre(genre string) (string, error) {
+	key := normalizePlatform(genre)
+	if key == "" {
+		return "", nil
+	}
+	for _, g := range Genres {
+		if normalizePlatform(g) == key {
+			return g, nil
+		}
+	}
+	if g, ok := genreAliases[key]; ok {
+		return g, nil
+	}
+	return "", ErrInvalidGenre
+}
+
+// DefaultPlatforms は、platforms テーブルに最初から登録しておくプラットフォームです。
+// 起動時に Repository.EnsurePlatforms で追加されます（追加済みのものはそのまま）。
+var DefaultPlatforms = []*Platform{
+	{Name: "PC", Aliases: []string{"Windows", "PC (Windows)", "Microsoft Windows", "Steam", "Epic Games Store", "GOG"}},
+	{Name: "Mac", Aliases: []string{"macOS", "OS X"}},
+	{Name: "Linux", Aliases: []string{"SteamOS", "Steam Deck"}},
+	{Name: "PlayStation 5", Aliases: []string{"PS5", "Sony PlayStation 5"}},
+	{Name: "PlayStation 4", Aliases: []string{"PS4", "Sony PlayStation 4"}},
+	{Name: "PlayStation Vita", Aliases: []string{"PS Vita", "PSV", "Vita", "Sony PlayStation Vita"}},
+	{Name: "Nintendo Switch 2", Aliases: []string{"Switch 2", "NS2"}},
+	{Name: "Nintendo Switch", Aliases: []string{"Switch", "NS", "スイッチ"}},
+	{Name: "Nintendo 3DS", Aliases: []string{"3DS", "New Nintendo 3DS"}},
+	{Name: "Xbox Series X|S", Aliases: []string{"Xbox Series X", "Xbox Series S", "XSX", "Microsoft Xbox Series"}},
+	{Name: "Xbox One", Aliases: []string{"XONE", "XB1", "Microsoft Xbox One"}},
+	{Name: "iOS", Aliases: []string{"iPhone", "iPad", "Apple iOS"}},
+	{Name: "Android", Aliases: []string{"Google Android"}},
+}
@@ -0,0 +1,159 @@
+package game
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // GIF のデコーダを登録（サムネイルは1フレーム目から作る）
+	"image/jpeg"
+	_ "image/png" // PNG のデコーダを登録
+	"net/http"
+	"path"
+	"strings"
+)
+
+// MaxCoverSize は、アップロードできるカバー画像の上限サイズです。
+const MaxCoverSize = 5 << 20 // 5MB
+
+const (
+	// coverThumbnailSize は、サムネイルの長い辺のピクセル数です。
+	coverThumbnailSize = 320
+	// maxCoverPixels は、デコードする画像の上限ピクセル数です。
+	// ファイルは小さくても展開すると巨大になる画像でメモリを使い切らないようにします。
+	maxCoverPixels = 40_000_000
+
+	// coverKeyPrefix は、カバー画像を Storage に保存するときのキーの前置きです。
+	coverKeyPrefix = "covers/"
+	// CoverURLPrefix は、カバー画像を配信する URL の前置きです（GET /api/covers/:name）。
+	CoverURLPrefix = "/api/covers/"
+)
+
+// coverExtensions は、受け付ける画像の形式（中身から判定した Content-Type）と保存時の拡張子です。
+var coverExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// coverContentTypes は、配信するときの拡張子ごとの Content-Type です。
+var coverContentTypes = map[string]string{
+	".jpg": "image/jpeg",
+	".png": "image/png",
+	".gif": "image/gif",
+}
+
+// coverImage は、アップロードされた画像と、そこから作ったサムネイルです。
+type coverImage struct {
+	Name      string // 元の画像のファイル名（games.cover_key）
+	Data      []byte
+	Thumbnail []byte // JPEG
+}
+
+// setCoverURLs は CoverKey から CoverURL / CoverThumbnailURL を組み立てます。
+func (g *Game) setCoverURLs() {
+	if g.CoverKey == "" {
+		g.CoverURL = ""
+		g.CoverThumbnailURL = ""
+		return
+	}
+	g.CoverURL = CoverURLPrefix + g.CoverKey
+	g.CoverThumbnailURL = CoverURLPrefix + coverThumbnailName(g.CoverKey)
+}
+
+// coverThumbnailName は、元の画像のファイル名からサムネイルのファイル名を作ります。
+// 例: "12-0a1b2c3d4e5f6a7b.png" → "12-0a1b2c3d4e5f6a7b_thumb.jpg"
+func coverThumbnailName(name string) string {
+	return strings.TrimSuffix(name, path.Ext(name)) + "_thumb.jpg"
+}
+
+// CoverContentType は、配信するカバー画像のファイル名から Content-Type を返します。
+// カバー画像として扱わない名前なら空文字を返します。
+func CoverContentType(name string) string {
+	if strings.Contains(name, "/") {
+		return ""
+	}
+	return coverContentTypes[path.Ext(name)]
+}
+
+// processCover は、アップロードされた画像を検証してサムネイルを作ります。
+// 形式は拡張子や Content-Type ヘッダーではなく、ファイルの中身で判定します。
+// ファイル名には内容のハッシュを入れるので、画像を差し替えると URL も変わります（長期キャッシュできる）。
+func processCover(gameID int, data []byte) (*coverImage, error) {
+	if len(data) > MaxCoverSize {
+		return nil, ErrCoverTooLarge
+	}
+	ext, ok := coverExtensions[http.DetectContentType(data)]
+	if !ok {
+		return nil, ErrInvalidCoverImage
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCoverImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxCoverPixels {
+		return nil, fmt.Errorf("%w: %dx%d is too large", ErrInvalidCoverImage, cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCoverImage, err)
+	}
+
+	var thumb bytes.Buffer
+	if err := jpeg.Encode(&thumb, makeThumbnail(img, coverThumbnailSize), &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	return &coverImage{
+		Name:      fmt.Sprintf("%d-%x%s", gameID, sum[:8], ext),
+		Data:      data,
+		Thumbnail: thumb.Bytes(),
+	}, nil
+}
+
+// makeThumbnail は、長い辺が size ピクセルに収まるよう src を縮小します（元より大きくはしません）。
+// 縮小先の1ピクセルに対応する範囲の平均を取るので、単純な間引きよりもきれいに縮みます。
+// JPEG には透過がないため、透明な部分は白で塗ります。
+func makeThumbnail(src image.Image, size int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > size || h > size {
+		if w >= h {
+			w, h = size, max(1, h*size/b.Dx())
+		} else {
+			w, h = max(1, w*size/b.Dy()), size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		sy0 := b.Min.Y + y*b.Dy()/h
+		sy1 := max(sy0+1, b.Min.Y+(y+1)*b.Dy()/h)
+		for x := 0; x < w; x++ {
+			sx0 := b.Min.X + x*b.Dx()/w
+			sx1 := max(sx0+1, b.Min.X+(x+1)*b.Dx()/w)
+
+			var r, g, bl, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			// 乗算済みアルファなので、白の背景に重ねるには透明な分だけ白を足す
+			white := 0xffff*n - a
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8((r + white) / n >> 8),
+				G: uint8((g + white) / n >> 8),
+				B: uint8((bl + white) / n >> 8),
+				A: 0xff,
+			})
+		}
+	}
+	return dst
+}
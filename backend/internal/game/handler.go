@@ -1,11 +1,20 @@
 package game
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"path/filepath"
 	"strconv" // URLのIDを数値に変換するため
+	"strings"
 
 	"github.com/labstack/echo/v4" // ★GinからEchoに変更
+
+	"TO-DO-IT/internal/mergepatch"
+	"TO-DO-IT/internal/storage"
 )
 
 // Handler は、game のHTTPリクエスト処理に関するインターフェースです。
@@ -15,7 +24,61 @@ type Handler interface {
 	GetGames(c echo.Context) error
 	GetGameByID(c echo.Context) error
 	UpdateGame(c echo.Context) error
+	PatchGame(c echo.Context) error
 	DeleteGame(c echo.Context) error
+
+	// カバー画像
+	UploadCover(c echo.Context) error
+	DeleteCover(c echo.Context) error
+	GetCover(c echo.Context) error
+
+	// 購入金額の集計
+	GetSpending(c echo.Context) error
+
+	// ゲーム同士の関係（DLC・シリーズのプレイ順）
+	GetRelations(c echo.Context) error
+	AddRelation(c echo.Context) error
+	DeleteRelation(c echo.Context) error
+	GetGameGraph(c echo.Context) error
+
+	// 一括操作
+	BulkUpdate(c echo.Context) error
+
+	// 一括インポート・エクスポート
+	ExportGames(c echo.Context) error
+	ImportGames(c echo.Context) error
+
+	// ゴミ箱
+	RestoreGame(c echo.Context) error
+	PurgeGame(c echo.Context) error
+
+	// 重複チェック・統合
+	GetDuplicates(c echo.Context) error
+	MergeGames(c echo.Context) error
+
+	// メモ・感想
+	CreateNote(c echo.Context) error
+	GetNotes(c echo.Context) error
+	UpdateNote(c echo.Context) error
+	DeleteNote(c echo.Context) error
+
+	// 完了報告
+	CompleteGame(c echo.Context) error
+
+	// ステータス変更履歴
+	GetStatusHistory(c echo.Context) error
+
+	// タグ
+	GetTags(c echo.Context) error
+	CreateTag(c echo.Context) error
+	UpdateTag(c echo.Context) error
+	DeleteTag(c echo.Context) error
+	AssignTag(c echo.Context) error
+	UnassignTag(c echo.Context) error
+
+	// ジャンル・プラットフォームのカタログ
+	GetGenres(c echo.Context) error
+	GetPlatforms(c echo.Context) error
 }
 
 // handler は Handler インターフェースの具体的な実装です。
@@ -36,7 +99,69 @@ func (h *handler) RegisterRoutes(apiGroup *echo.Group) { // ★引数を *echo.G
 		gameRoutes.GET("", h.GetGames)       // GET /api/games
 		gameRoutes.GET("/:id", h.GetGameByID) // GET /api/games/:id
 		gameRoutes.PUT("/:id", h.UpdateGame)  // PUT /api/games/:id
+		gameRoutes.PATCH("/:id", h.PatchGame) // PATCH /api/games/:id (JSON Merge Patch)
 		gameRoutes.DELETE("/:id", h.DeleteGame) // DELETE /api/games/:id
+
+		// カバー画像
+		gameRoutes.POST("/:id/cover", h.UploadCover)   // POST /api/games/:id/cover
+		gameRoutes.DELETE("/:id/cover", h.DeleteCover) // DELETE /api/games/:id/cover
+
+		// 購入金額の集計
+		gameRoutes.GET("/spending", h.GetSpending) // GET /api/games/spending?currency=JPY
+
+		// ゲーム同士の関係（DLC・シリーズのプレイ順）
+		gameRoutes.GET("/graph", h.GetGameGraph)                          // GET /api/games/graph
+		gameRoutes.GET("/:id/relations", h.GetRelations)                  // GET /api/games/:id/relations
+		gameRoutes.POST("/:id/relations", h.AddRelation)                  // POST /api/games/:id/relations
+		gameRoutes.DELETE("/:id/relations/:relationId", h.DeleteRelation) // DELETE /api/games/:id/relations/:relationId
+
+		// 一括操作
+		gameRoutes.POST("/bulk", h.BulkUpdate) // POST /api/games/bulk
+
+		// 一括インポート・エクスポート
+		gameRoutes.GET("/export", h.ExportGames)  // GET /api/games/export?format=csv|json
+		gameRoutes.POST("/import", h.ImportGames) // POST /api/games/import?format=csv|json&dry_run=true
+
+		// ゴミ箱
+		gameRoutes.POST("/:id/restore", h.RestoreGame) // POST /api/games/:id/restore
+		gameRoutes.DELETE("/:id/purge", h.PurgeGame)   // DELETE /api/games/:id/purge
+
+		// 重複チェック・統合
+		gameRoutes.GET("/duplicates", h.GetDuplicates) // GET /api/games/duplicates
+		gameRoutes.POST("/:id/merge", h.MergeGames)    // POST /api/games/:id/merge
+
+		// メモ・感想
+		gameRoutes.POST("/:id/notes", h.CreateNote)           // POST /api/games/:id/notes
+		gameRoutes.GET("/:id/notes", h.GetNotes)              // GET /api/games/:id/notes
+		gameRoutes.PUT("/:id/notes/:noteId", h.UpdateNote)    // PUT /api/games/:id/notes/:noteId
+		gameRoutes.DELETE("/:id/notes/:noteId", h.DeleteNote) // DELETE /api/games/:id/notes/:noteId
+
+		// 完了報告（終了フラグ）
+		gameRoutes.POST("/:id/complete", h.CompleteGame) // POST /api/games/:id/complete
+
+		// ステータス変更履歴
+		gameRoutes.GET("/:id/history", h.GetStatusHistory) // GET /api/games/:id/history
+
+		// タグの割り当て
+		gameRoutes.POST("/:id/tags/:tagId", h.AssignTag)     // POST /api/games/:id/tags/:tagId
+		gameRoutes.DELETE("/:id/tags/:tagId", h.UnassignTag) // DELETE /api/games/:id/tags/:tagId
+	}
+
+	tagRoutes := apiGroup.Group("/tags") // /api/tags
+	{
+		tagRoutes.GET("", h.GetTags)             // GET /api/tags
+		tagRoutes.POST("", h.CreateTag)          // POST /api/tags
+		tagRoutes.PUT("/:tagId", h.UpdateTag)    // PUT /api/tags/:tagId
+		tagRoutes.DELETE("/:tagId", h.DeleteTag) // DELETE /api/tags/:tagId
+	}
+
+	// カバー画像の配信（ゲームの cover_url / cover_thumbnail_url）
+	apiGroup.GET("/covers/:name", h.GetCover) // GET /api/covers/:name
+
+	catalogRoutes := apiGroup.Group("/catalog") // /api/catalog
+	{
+		catalogRoutes.GET("/genres", h.GetGenres)       // GET /api/catalog/genres
+		catalogRoutes.GET("/platforms", h.GetPlatforms) // GET /api/catalog/platforms
 	}
 }
 
@@ -54,6 +179,66 @@ func getIDParam(c echo.Context) (int, error) {
 	return id, nil
 }
 
+// getNoteIDParam は URL から :noteId を数値として取得するヘルパー関数
+func getNoteIDParam(c echo.Context) (int, error) {
+	idStr := c.Param("noteId")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		log.Printf("Handler: Invalid note ID parameter: %s", idStr)
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "Invalid note ID format")
+	}
+	return id, nil
+}
+
+// getTagIDParam は URL から :tagId を数値として取得するヘルパー関数
+func getTagIDParam(c echo.Context) (int, error) {
+	idStr := c.Param("tagId")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		log.Printf("Handler: Invalid tag ID parameter: %s", idStr)
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "Invalid tag ID format")
+	}
+	return id, nil
+}
+
+// getRelationIDParam は URL から :relationId を数値として取得するヘルパー関数
+func getRelationIDParam(c echo.Context) (int, error) {
+	idStr := c.Param("relationId")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		log.Printf("Handler: Invalid relation ID parameter: %s", idStr)
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "Invalid relation ID format")
+	}
+	return id, nil
+}
+
+
+// SetETag は、ゲームの版を ETag ヘッダーに設定します。
+// クライアントは更新・削除のときに、この値を If-Match ヘッダーで送り返します。
+// ゲームを変更する他パッケージのハンドラ（prune の drop など）からも使います。
+func SetETag(c echo.Context, game *Game) {
+	c.Response().Header().Set("ETag", fmt.Sprintf(`"%d"`, game.Version))
+}
+
+// GetIfMatchVersion は If-Match ヘッダーから、クライアントが読み込んだゲームの版を取得するヘルパー関数
+// ヘッダーがなければ 428、形式が不正なら 400 のエラーを返します。"*" のときは 0（版を確認しない）を返します。
+// 返したエラーは echo.HTTPError なので、ハンドラはそのまま return してください（他パッケージのハンドラも同じ）。
+func GetIfMatchVersion(c echo.Context) (int, error) {
+	ifMatch := strings.TrimSpace(c.Request().Header.Get("If-Match"))
+	if ifMatch == "" {
+		return 0, echo.NewHTTPError(http.StatusPreconditionRequired, "If-Match header is required (use the ETag from GET /api/games/:id)")
+	}
+	if ifMatch == "*" {
+		return 0, nil
+	}
+
+	version, err := strconv.Atoi(strings.Trim(ifMatch, `"`))
+	if err != nil || version <= 0 || !strings.HasPrefix(ifMatch, `"`) || !strings.HasSuffix(ifMatch, `"`) {
+		log.Printf("Handler: Invalid If-Match header: %s", ifMatch)
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "If-Match must be a single ETag such as \"3\"")
+	}
+	return version, nil
+}
 
 // CreateGame は新しいゲームを作成します (POST /api/games)
 func (h *handler) CreateGame(c echo.Context) error {
@@ -69,17 +254,43 @@ func (h *handler) CreateGame(c echo.Context) error {
 	// 2. サービスを呼び出す
 	game, err := h.svc.CreateGame(&req)
 	if err != nil {
+		if errors.Is(err, ErrInvalidStatus) || errors.Is(err, ErrInvalidGenre) || errors.Is(err, ErrInvalidPlatform) ||
+			isPurchaseError(err) || isPlanError(err) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		// 重複の場合は既存のゲームを返し、allow_duplicate で登録するか統合するかを選べるようにする
+		var dupErr *DuplicateError
+		if errors.As(err, &dupErr) {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"error":      err.Error(),
+				"duplicates": dupErr.Duplicates,
+			})
+		}
 		log.Printf("Handler: Error creating game: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create game"})
 	}
 
 	// 3. 成功レスポンス（作成されたリソース）を返す
+	SetETag(c, game)
 	return c.JSON(http.StatusCreated, game)
 }
 
 // GetGames は（テストユーザーの）ゲーム一覧を取得します (GET /api/games)
+// ?include_deleted=true を付けるとゴミ箱のゲームも含めます。
+// ?tag=co-op&tag=short（または ?tag=co-op,short）で、指定したタグがすべて付いたゲームに絞り込みます。
 func (h *handler) GetGames(c echo.Context) error {
-	games, err := h.svc.GetGames()
+	q := &GameListQuery{
+		IncludeDeleted: c.QueryParam("include_deleted") == "true",
+	}
+	for _, param := range c.QueryParams()["tag"] {
+		for _, name := range strings.Split(param, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				q.Tags = append(q.Tags, name)
+			}
+		}
+	}
+
+	games, err := h.svc.GetGames(q)
 	if err != nil {
 		log.Printf("Handler: Error getting games: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get games"})
@@ -89,23 +300,25 @@ func (h *handler) GetGames(c echo.Context) error {
 
 
 // GetGameByID は ID でゲームを1件取得します (GET /api/games/:id)
+// 最新のプレイ日記（latest_journal）も一緒に返します。
 func (h *handler) GetGameByID(c echo.Context) error {
 	id, err := getIDParam(c)
 	if err != nil {
 		return err // getIDParamがHTTPErrorを返しているのでそのまま返す
 	}
 
-	game, err := h.svc.GetGame(id)
+	detail, err := h.svc.GetGameDetail(id)
 	if err != nil {
 		log.Printf("Handler: Error getting game by ID: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get game"})
 	}
 
-	if game == nil {
+	if detail == nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Game not found"})
 	}
 
-	return c.JSON(http.StatusOK, game)
+	SetETag(c, detail.Game)
+	return c.JSON(http.StatusOK, detail)
 }
 
 // UpdateGame はゲーム情報を更新します (PUT /api/games/:id)
@@ -121,31 +334,872 @@ func (h *handler) UpdateGame(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body: " + err.Error()})
 	}
 
-	game, err := h.svc.UpdateGame(id, &req)
+	version, err := GetIfMatchVersion(c)
+	if err != nil {
+		return err
+	}
+
+	game, err := h.svc.UpdateGame(id, version, &req)
 	if err != nil {
-		log.Printf("Handler: Error updating game: %v", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update game"})
+		return updateGameError(c, err)
 	}
 	
 	if game == nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Game not found to update"})
 	}
 
+	SetETag(c, game)
 	return c.JSON(http.StatusOK, game)
 }
 
-// DeleteGame は ID を指定してゲームを削除します (DELETE /api/games/:id)
+// PatchGame はゲーム情報を部分的に更新します (PATCH /api/games/:id)
+// ボディは JSON Merge Patch (RFC 7396) です。省略した項目は変更せず、null の項目は未設定に戻します。
+// 例: {"platform": null, "estimated_hours": 30}
+func (h *handler) PatchGame(c echo.Context) error {
+	id, err := getIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	// Content-Type が application/merge-patch+json でも読めるように、Bind ではなく直接デコードする
+	var patch GamePatch
+	if err := mergepatch.Decode(c.Request().Body, &patch); err != nil {
+		log.Printf("Handler: Failed to decode merge patch: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body: " + err.Error()})
+	}
+
+	version, err := GetIfMatchVersion(c)
+	if err != nil {
+		return err
+	}
+
+	game, err := h.svc.PatchGame(id, version, &patch)
+	if err != nil {
+		return updateGameError(c, err)
+	}
+
+	if game == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Game not found to update"})
+	}
+
+	SetETag(c, game)
+	return c.JSON(http.StatusOK, game)
+}
+
+// isPurchaseError は、購入情報（価格・通貨・購入日）の入力エラーかを返します。
+func isPurchaseError(err error) bool {
+	return errors.Is(err, ErrNegativePrice) || errors.Is(err, ErrInvalidCurrency) || errors.Is(err, ErrInvalidPurchaseDate)
+}
+
+// isPlanError は、遊ぶ順番の希望（優先度・期限）の入力エラーかを返します。
+func isPlanError(err error) bool {
+	return errors.Is(err, ErrInvalidPriority) || errors.Is(err, ErrInvalidDeadline)
+}
+
+// updateGameError は、PUT / PATCH の更新エラーをレスポンスに変換します。
+func updateGameError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidStatus), errors.Is(err, ErrInvalidGenre), errors.Is(err, ErrInvalidPlatform),
+		errors.Is(err, ErrRequiredField), errors.Is(err, ErrNegativeValue), isPurchaseError(err), isPlanError(err):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrInvalidTransition):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrVersionMismatch):
+		return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+	}
+	log.Printf("Handler: Error updating game: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update game"})
+}
+
+// DeleteGame は ID を指定してゲームをゴミ箱に移します (DELETE /api/games/:id)
 func (h *handler) DeleteGame(c echo.Context) error {
 	id, err := getIDParam(c)
 	if err != nil {
 		return err
 	}
 
-	err = h.svc.DeleteGame(id)
+	version, err := GetIfMatchVersion(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.svc.DeleteGame(id, version)
 	if err != nil {
+		if errors.Is(err, ErrVersionMismatch) {
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+		}
 		log.Printf("Handler: Error deleting game: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete game"})
 	}
 
 	return c.NoContent(http.StatusNoContent) // 中身なし
-}
\ No newline at end of file
+}
+
+// RestoreGame はゲームをゴミ箱から戻します (POST /api/games/:id/restore)
+// 削除と同じく If-Match が必要です（ゴミ箱のゲームの版は GET /api/games?include_deleted=true の version）。
+func (h *handler) RestoreGame(c echo.Context) error {
+	id, err := getIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	version, err := GetIfMatchVersion(c)
+	if err != nil {
+		return err
+	}
+
+	game, err := h.svc.RestoreGame(id, version)
+	if err != nil {
+		if errors.Is(err, ErrVersionMismatch) {
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Handler: Error restoring game: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to restore game"})
+	}
+
+	if game == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Game not found"})
+	}
+
+	SetETag(c, game)
+	return c.JSON(http.StatusOK, game)
+}
+
+// PurgeGame はゴミ箱のゲームを完全に削除します (DELETE /api/games/:id/purge)
+// ゴミ箱に入っていないゲームには 409 を返します。削除と同じく If-Match が必要です。
+func (h *handler) PurgeGame(c echo.Context) error {
+	id, err := getIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	version, err := GetIfMatchVersion(c)
+	if err != nil {
+		return err
+	}
+
+	game, err := h.svc.PurgeGame(id, version)
+	if err != nil {
+		if errors.Is(err, ErrNotInTrash) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, ErrVersionMismatch) {
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Handler: Error purging game: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to purge game"})
+	}
+
+	if game == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Game not found"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// --- 重複チェック・統合 ---
+
+// GetDuplicates は重複登録らしいゲームのまとまりを返します (GET /api/games/duplicates)
+func (h *handler) GetDuplicates(c echo.Context) error {
+	groups, err := h.svc.GetDuplicateGroups()
+	if err != nil {
+		log.Printf("Handler: Error getting duplicate games: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get duplicates"})
+	}
+	return c.JSON(http.StatusOK, groups)
+}
+
+// MergeGames は source_id のゲームを :id のゲームに統合します (POST /api/games/:id/merge)
+// source_id のゲームのメモ・履歴・スケジュールは :id のゲームに移り、source_id のゲームは削除されます。
+// If-Match には :id のゲームの ETag を指定します。
+func (h *handler) MergeGames(c echo.Context) error {
+	id, err := getIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	version, err := GetIfMatchVersion(c)
+	if err != nil {
+		return err
+	}
+
+	var req MergeGameRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Handler: Failed to bind JSON for merge: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body: " + err.Error()})
+	}
+	if req.SourceID == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "source_id is required"})
+	}
+
+	game, err := h.svc.MergeGames(id, req.SourceID, version)
+	if err != nil {
+		if errors.Is(err, ErrMergeSameGame) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, ErrRelationCycle) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, ErrVersionMismatch) {
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Handler: Error merging games: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to merge games"})
+	}
+
+	if game == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Game not found"})
+	}
+
+	SetETag(c, game)
+	return c.JSON(http.StatusOK, game)
+}
+
+// --- 一括操作 ---
+
+// BulkUpdate は複数のゲームへの操作をまとめて実行します (POST /api/games/bulk)
+// 例: {"mode": "best_effort", "operations": [{"op": "set_status", "ids": [1, 2], "status": "dropped"}]}
+// 結果は項目（操作 × ゲーム）ごとに返します。atomic モードで失敗して全体を取り消した場合は 409 です。
+func (h *handler) BulkUpdate(c echo.Context) error {
+	var req BulkRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Handler: Failed to bind JSON for bulk update: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body: " + err.Error()})
+	}
+
+	res, err := h.svc.BulkUpdate(&req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidBulkRequest) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Handler: Error running bulk update: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to run bulk update"})
+	}
+
+	if !res.Committed {
+		return c.JSON(http.StatusConflict, res)
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// --- 一括インポート・エクスポート ---
+
+// maxImportFileSize は、インポートできるファイルの上限サイズです。
+const maxImportFileSize = 5 << 20 // 5MB
+
+// ExportGames はゲーム一覧を CSV / JSON でダウンロードします (GET /api/games/export?format=csv|json)
+func (h *handler) ExportGames(c echo.Context) error {
+	format := strings.ToLower(c.QueryParam("format"))
+	if format == "" {
+		format = FormatCSV
+	}
+	if format != FormatCSV && format != FormatJSON {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": ErrUnsupportedFormat.Error()})
+	}
+
+	games, err := h.svc.GetGames(&GameListQuery{})
+	if err != nil {
+		log.Printf("Handler: Error getting games for export: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to export games"})
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeGames(&buf, format, games); err != nil {
+		log.Printf("Handler: Error encoding games: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to export games"})
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == FormatJSON {
+		contentType = echo.MIMEApplicationJSONCharsetUTF8
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="games.%s"`, format))
+	return c.Blob(http.StatusOK, contentType, buf.Bytes())
+}
+
+// ImportGames はアップロードされた CSV / JSON からゲームを一括登録します (POST /api/games/import)
+// ファイルは multipart の file フィールドで受け取ります。形式は ?format= か拡張子で判断します。
+// ?dry_run=true のときは検証結果だけを返し、ゲームは作成しません。
+func (h *handler) ImportGames(c echo.Context) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "file is required (multipart field \"file\")"})
+	}
+	if file.Size > maxImportFileSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "file is too large"})
+	}
+
+	format := strings.ToLower(c.QueryParam("format"))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		log.Printf("Handler: Failed to open uploaded file: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Failed to read uploaded file"})
+	}
+	defer src.Close()
+
+	dryRun := c.QueryParam("dry_run") == "true"
+	report, err := h.svc.ImportGames(format, src, dryRun)
+	if err != nil {
+		if errors.Is(err, ErrUnsupportedFormat) || errors.Is(err, ErrInvalidImportFile) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Handler: Error importing games: %v", err)
+		// 登録は全体でロールバックされているので、失敗した行を返して何も登録されていないことを伝える
+		var rowErr *ImportRowError
+		if errors.As(err, &rowErr) {
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"error": "Failed to import games; no games were imported",
+				"row":   rowErr.Row,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to import games"})
+	}
+
+	status := http.StatusOK
+	if report.Created > 0 {
+		status = http.StatusCreated
+	}
+	return c.JSON(status, report)
+}
+
+// --- カバー画像 ---
+
+// UploadCover はゲームのカバー画像をアップロードします (POST /api/games/:id/cover)
+// 画像は multipart の file フィールドで受け取ります。JPEG / PNG / GIF の 5MB までです。
+// ゲームの更新なので、PUT / PATCH と同じく If-Match が必要です。
+func (h *handler) UploadCover(c echo.Context) error {
+	id, err := getIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	version, err := GetIfMatchVersion(c)
+	if err != nil {
+		return err
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "file is required (multipart field \"file\")"})
+	}
+	if file.Size > MaxCoverSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": ErrCoverTooLarge.Error()})
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		log.Printf("Handler: Failed to open uploaded file: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Failed to read uploaded file"})
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(src, MaxCoverSize+1))
+	if err != nil {
+		log.Printf("Handler: Failed to read uploaded file: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Failed to read uploaded file"})
+	}
+
+	game, err := h.svc.SetCover(id, version, data)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrCoverTooLarge):
+			return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": err.Error()})
+		case errors.Is(err, ErrInvalidCoverImage):
+			return c.JSON(http.StatusUnsupportedMediaType, map[string]string{"error": err.Error()})
+		case errors.Is(err, ErrVersionMismatch):
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Handler: Error uploading cover: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to upload cover"})
+	}
+	if game == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Game not found"})
+	}
+
+	SetETag(c, game)
+	return c.JSON(http.StatusOK, game)
+}
+
+// DeleteCover はゲームのカバー画像を外します (DELETE /api/games/:id/cover)
+// アップロードと同じく If-Match が必要です。
+func (h *handler) DeleteCover(c echo.Context) error {
+	id, err := getIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	version, err := GetIfMatchVersion(c)
+	if err != nil {
+		return err
+	}
+
+	game, err := h.svc.DeleteCover(id, version)
+	if err != nil {
+		if errors.Is(err, ErrVersionMismatch) {
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Handler: Error deleting cover: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete cover"})
+	}
+	if game == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Game not found"})
+	}
+
+	SetETag(c, game)
+	return c.JSON(http.StatusOK, game)
+}
+
+// coverCacheControl は、カバー画像の Cache-Control です。
+// ファイル名に内容のハッシュが入っていて、画像を差し替えると URL が変わるので、ずっとキャッシュしてよい。
+const coverCacheControl = "public, max-age=31536000, immutable"
+
+// GetCover はカバー画像（またはサムネイル）を返します (GET /api/covers/:name)
+// If-None-Match / If-Modified-Since が一致すれば 304 を返します。
+func (h *handler) GetCover(c echo.Context) error {
+	name := c.Param("name")
+
+	obj, err := h.svc.OpenCover(name)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Cover not found"})
+		}
+		log.Printf("Handler: Error opening cover: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get cover"})
+	}
+	defer obj.Close()
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, CoverContentType(name))
+	header.Set("Cache-Control", coverCacheControl)
+	header.Set("ETag", `"`+name+`"`)
+	http.ServeContent(c.Response(), c.Request(), name, obj.ModTime, obj)
+	return nil
+}
+
+// --- 購入金額の集計 ---
+
+// GetSpending は購入金額の集計を返します (GET /api/games/spending?currency=JPY)
+// 積みゲーに眠っている金額・クリアしたゲームの1時間あたりの金額・積んでからの日数を、
+// 全体・プラットフォーム別・購入年別に返します。?currency= を省略すると、いちばん多い通貨で集計します。
+func (h *handler) GetSpending(c echo.Context) error {
+	report, err := h.svc.GetSpending(c.QueryParam("currency"))
+	if err != nil {
+		if errors.Is(err, ErrInvalidCurrency) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Handler: Error getting spending: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get spending"})
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// --- ゲーム同士の関係 (GameRelation) ---
+
+// GetRelations はゲームの関係（DLC・本編・続編・前作）の一覧を返します (GET /api/games/:id/relations)
+func (h *handler) GetRelations(c echo.Context) error {
+	id, err := getIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	rels, err := h.svc.GetRelations(id)
+	if err != nil {
+		log.Printf("Handler: Error getting relations: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get relations"})
+	}
+	if rels == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Game not found"})
+	}
+
+	return c.JSON(http.StatusOK, rels)
+}
+
+// AddRelation は、:id のゲームが別のゲームの DLC・続編であることを登録します (POST /api/games/:id/relations)
+// 例: {"type": "sequel_of", "related_game_id": 3} → :id は #3 の続編（#3 をクリアするまでスケジュールしない）
+func (h *handler) AddRelation(c echo.Context) error {
+	id, err := getIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	var req RelationRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Handler: Failed to bind JSON for relation: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body: " + err.Error()})
+	}
+
+	rel, err := h.svc.AddRelation(id, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidRelationType), errors.Is(err, ErrSelfRelation):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, ErrGameNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Related game not found"})
+		case errors.Is(err, ErrDuplicateRelation), errors.Is(err, ErrAlreadyDLC), errors.Is(err, ErrRelationCycle):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Handler: Error adding relation: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to add relation"})
+	}
+	if rel == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Game not found"})
+	}
+
+	return c.JSON(http.StatusCreated, rel)
+}
+
+// DeleteRelation は関係を削除します (DELETE /api/games/:id/relations/:relationId)
+func (h *handler) DeleteRelation(c echo.Context) error {
+	id, err := getIDParam(c)
+	if err != nil {
+		return err
+	}
+	relationID, err := getRelationIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.svc.DeleteRelation(id, relationID); err != nil {
+		if errors.Is(err, ErrRelationNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Handler: Error deleting relation: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete relation"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetGameGraph は、関係のあるゲームをプレイ順に並べたグラフを返します (GET /api/games/graph)
+func (h *handler) GetGameGraph(c echo.Context) error {
+	graph, err := h.svc.GetGameGraph()
+	if err != nil {
+		log.Printf("Handler: Error getting game graph: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get game graph"})
+	}
+
+	return c.JSON(http.StatusOK, graph)
+}
+
+// --- メモ・感想 (Note) ---
+
+// CreateNote はゲームにメモを追加します (POST /api/games/:id/notes)
+// 最初のメモで「未開始」から「プレイ中」に変わった場合は、レスポンスの status_changed が true になります。
+func (h *handler) CreateNote(c echo.Context) error {
+	id, err := getIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	var req NoteRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Handler: Failed to bind JSON for note: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body: " + err.Error()})
+	}
+
+	res, err := h.svc.CreateNote(id, &req)
+	if err != nil {
+		if errors.Is(err, ErrEmptyNote) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Handler: Error creating note: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create note"})
+	}
+
+	if res == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Game not found"})
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// GetNotes はゲームのメモ一覧を取得します (GET /api/games/:id/notes)
+func (h *handler) GetNotes(c echo.Context) error {
+	id, err := getIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	notes, err := h.svc.GetNotes(id)
+	if err != nil {
+		log.Printf("Handler: Error getting notes: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get notes"})
+	}
+
+	if notes == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Game not found"})
+	}
+
+	return c.JSON(http.StatusOK, notes)
+}
+
+// UpdateNote はメモを更新します (PUT /api/games/:id/notes/:noteId)
+func (h *handler) UpdateNote(c echo.Context) error {
+	id, err := getIDParam(c)
+	if err != nil {
+		return err
+	}
+	noteID, err := getNoteIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	var req NoteRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Handler: Failed to bind JSON for note update: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body: " + err.Error()})
+	}
+
+	note, err := h.svc.UpdateNote(id, noteID, &req)
+	if err != nil {
+		if errors.Is(err, ErrEmptyNote) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Handler: Error updating note: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update note"})
+	}
+
+	if note == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Note not found to update"})
+	}
+
+	return c.JSON(http.StatusOK, note)
+}
+
+// DeleteNote はメモを削除します (DELETE /api/games/:id/notes/:noteId)
+func (h *handler) DeleteNote(c echo.Context) error {
+	id, err := getIDParam(c)
+	if err != nil {
+		return err
+	}
+	noteID, err := getNoteIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.svc.DeleteNote(id, noteID); err != nil {
+		log.Printf("Handler: Error deleting note: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete note"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// --- 完了報告 (終了フラグ) ---
+
+// CompleteGame はゲームの完了報告を受け付けます (POST /api/games/:id/complete)
+// 完了報告済みのゲームには 409 を返します（報告のないまま完了にしたゲームには報告できます）。更新と同じく If-Match が必要です。
+func (h *handler) CompleteGame(c echo.Context) error {
+	id, err := getIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	version, err := GetIfMatchVersion(c)
+	if err != nil {
+		return err
+	}
+
+	var req CompleteGameRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Handler: Failed to bind JSON for completion: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body: " + err.Error()})
+	}
+
+	res, err := h.svc.CompleteGame(id, version, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidRating), errors.Is(err, ErrInvalidCompletionType):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, ErrAlreadyCompleted), errors.Is(err, ErrInvalidTransition):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		case errors.Is(err, ErrVersionMismatch):
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Handler: Error completing game: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to complete game"})
+	}
+
+	if res == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Game not found"})
+	}
+
+	SetETag(c, res.Game)
+	return c.JSON(http.StatusOK, res)
+}
+
+// --- ステータス変更履歴 ---
+
+// GetStatusHistory はゲームのステータス変更履歴を古い順に返します (GET /api/games/:id/history)
+func (h *handler) GetStatusHistory(c echo.Context) error {
+	id, err := getIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	histories, err := h.svc.GetStatusHistory(id)
+	if err != nil {
+		log.Printf("Handler: Error getting status history: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get status history"})
+	}
+
+	if histories == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Game not found"})
+	}
+
+	return c.JSON(http.StatusOK, histories)
+}
+
+// --- タグ (Tag) ---
+
+// GetTags はタグ一覧を取得します (GET /api/tags)
+func (h *handler) GetTags(c echo.Context) error {
+	tags, err := h.svc.GetTags()
+	if err != nil {
+		log.Printf("Handler: Error getting tags: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get tags"})
+	}
+	return c.JSON(http.StatusOK, tags)
+}
+
+// CreateTag はタグを作成します (POST /api/tags)
+// schedule_days を指定すると、そのタグのゲームは指定した曜日にだけスケジュールされます。
+func (h *handler) CreateTag(c echo.Context) error {
+	var req TagRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Handler: Failed to bind JSON for tag: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body: " + err.Error()})
+	}
+
+	tag, err := h.svc.CreateTag(&req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrEmptyTagName), errors.Is(err, ErrInvalidTagColor), errors.Is(err, ErrInvalidScheduleDay):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, ErrDuplicateTag):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Handler: Error creating tag: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create tag"})
+	}
+
+	return c.JSON(http.StatusCreated, tag)
+}
+
+// UpdateTag はタグを更新します (PUT /api/tags/:tagId)
+func (h *handler) UpdateTag(c echo.Context) error {
+	tagID, err := getTagIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	var req TagRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Handler: Failed to bind JSON for tag update: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body: " + err.Error()})
+	}
+
+	tag, err := h.svc.UpdateTag(tagID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrEmptyTagName), errors.Is(err, ErrInvalidTagColor), errors.Is(err, ErrInvalidScheduleDay):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, ErrDuplicateTag):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Handler: Error updating tag: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update tag"})
+	}
+
+	if tag == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Tag not found"})
+	}
+
+	return c.JSON(http.StatusOK, tag)
+}
+
+// DeleteTag はタグを削除します (DELETE /api/tags/:tagId)
+// ゲームに付いていたタグも外れます。
+func (h *handler) DeleteTag(c echo.Context) error {
+	tagID, err := getTagIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.svc.DeleteTag(tagID); err != nil {
+		log.Printf("Handler: Error deleting tag: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete tag"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// AssignTag はゲームにタグを付けます (POST /api/games/:id/tags/:tagId)
+func (h *handler) AssignTag(c echo.Context) error {
+	id, err := getIDParam(c)
+	if err != nil {
+		return err
+	}
+	tagID, err := getTagIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	game, err := h.svc.AssignTag(id, tagID)
+	if err != nil {
+		log.Printf("Handler: Error assigning tag: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to assign tag"})
+	}
+
+	if game == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Game or tag not found"})
+	}
+
+	return c.JSON(http.StatusOK, game)
+}
+
+// UnassignTag はゲームからタグを外します (DELETE /api/games/:id/tags/:tagId)
+func (h *handler) UnassignTag(c echo.Context) error {
+	id, err := getIDParam(c)
+	if err != nil {
+		return err
+	}
+	tagID, err := getTagIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	game, err := h.svc.UnassignTag(id, tagID)
+	if err != nil {
+		log.Printf("Handler: Error unassigning tag: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to unassign tag"})
+	}
+
+	if game == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Game or tag not found"})
+	}
+
+	return c.JSON(http.StatusOK, game)
+}
+
+// --- ジャンル・プラットフォームのカタログ ---
+
+// GetGenres は登録できるジャンルの一覧を返します (GET /api/catalog/genres)
+func (h *handler) GetGenres(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.svc.GetGenres())
+}
+
+// GetPlatforms はプラットフォームの一覧を別名つきで返します (GET /api/catalog/platforms)
+func (h *handler) GetPlatforms(c echo.Context) error {
+	platforms, err := h.svc.GetPlatforms()
+	if err != nil {
+		log.Printf("Handler: Error getting platforms: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get platforms"})
+	}
+	return c.JSON(http.StatusOK, platforms)
+}
@@ -1,6 +1,11 @@
 package game
 
-import "time"
+import (
+	"time"
+
+	"TO-DO-IT/internal/mergepatch"
+	"TO-DO-IT/internal/score"
+)
 
 // Game は、games テーブルのレコードを表す構造体です。
 type Game struct {
@@ -9,27 +14,469 @@ type Game struct {
 	Title       string    `json:"title" binding:"required"`
 	Platform    string    `json:"platform"`
 	Genre       string    `json:"genre"`
-	Status      string    `json:"status"`  // unstarted, playing, completed
+	Status      string    `json:"status"` // unstarted, playing, paused, completed, dropped
 	ReleaseDate time.Time `json:"release_date"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	// クリアまでの推定プレイ時間（時間）。0 は未設定
+	EstimatedHours float64 `json:"estimated_hours"`
+	// これまでの累計プレイ時間（分）。Steam などから取り込んだ値を含む
+	PlayedMinutes int `json:"played_minutes"`
+	// Steam の appid。Steam から取り込んだゲームのみ（0 は未連携）
+	SteamAppID int `json:"steam_app_id"`
+
+	// 購入情報。価格は通貨の最小単位（円・セント）で、未入力なら null
+	PurchasePrice *int   `json:"purchase_price"`
+	Currency      string `json:"currency"`      // ISO 4217 の通貨コード（JPY, USD など）
+	PurchaseDate  string `json:"purchase_date"` // YYYY-MM-DD。未入力は空文字
+	Store         string `json:"store"`         // 購入したストア（Steam, PlayStation Store など）
+
+	// 遊ぶ順番の希望（おすすめ・スケジュールで使う）
+	Priority int    `json:"priority"` // 0〜5。大きいほど優先。0 は未設定
+	Deadline string `json:"deadline"` // いつまでに遊びたいか（YYYY-MM-DD）。未入力は空文字
+
+	// 完了報告（終了フラグ）の内容。未報告のときは CompletionType が空文字
+	// CompletedAt は、完了報告がなくても完了にした日時が入る。一度も完了していなければ null
+	CompletedAt    *time.Time `json:"completed_at"`
+	Rating         int        `json:"rating"`          // 1〜5。0 は未評価
+	Review         string     `json:"review"`          // 感想（任意）
+	CompletionType string     `json:"completion_type"` // story_clear, full_clear, dropped
+
+	DeletedAt *time.Time `json:"deleted_at"` // ゴミ箱に入っていれば削除日時
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	// 更新のたびに1ずつ増える版番号。ETag として返し、更新時は If-Match で確認する
+	Version int `json:"version"`
+
+	Tags []*Tag `json:"tags"` // 付いているタグ（game_tags テーブル）
+
+	// カバー画像。未設定のときは空文字
+	CoverKey          string `json:"-"`                   // Storage 上のファイル名（cover_key カラム）
+	CoverURL          string `json:"cover_url"`           // 元の画像の URL
+	CoverThumbnailURL string `json:"cover_thumbnail_url"` // 一覧表示用のサムネイルの URL
+}
+
+// CanScheduleOn は、付いているタグのルール上、day にプレイの予定を入れてよいかを返します。
+// 曜日の指定があるタグが複数付いている場合は、すべてのタグが許可している曜日だけ予定を入れられます。
+func (g *Game) CanScheduleOn(day time.Weekday) bool {
+	for _, t := range g.Tags {
+		if !t.AllowsDay(day) {
+			return false
+		}
+	}
+	return true
+}
+
+// ゲームのステータス
+const (
+	StatusUnstarted = "unstarted" // 未開始（積みゲー）
+	StatusPlaying   = "playing"   // プレイ中
+	StatusPaused    = "paused"    // 一時中断
+	StatusCompleted = "completed" // クリア済み
+	StatusDropped   = "dropped"   // 途中でやめた
+)
+
+// statusTransitions は、各ステータスから変更できるステータスの一覧です。
+// クリア済みからは「もう一度遊ぶ」だけ、やめたゲームは再開かクリア報告のみ許可します。
+var statusTransitions = map[string][]string{
+	StatusUnstarted: {StatusPlaying, StatusCompleted, StatusDropped},
+	StatusPlaying:   {StatusPaused, StatusCompleted, StatusDropped},
+	StatusPaused:    {StatusPlaying, StatusCompleted, StatusDropped},
+	StatusCompleted: {StatusPlaying},
+	StatusDropped:   {StatusPlaying, StatusCompleted},
+}
+
+// IsValidStatus は、status が定義済みのステータスかどうかを返します。
+func IsValidStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok
+}
+
+// CanTransition は、from から to へのステータス変更が許可されているかを返します。
+func CanTransition(from, to string) bool {
+	for _, s := range statusTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusHistory は、ステータス変更履歴（game_status_history テーブル）を表す構造体です。
+// 作成時の記録は FromStatus が空文字になります。
+type StatusHistory struct {
+	ID         int       `json:"id"`
+	GameID     int       `json:"game_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// GameListQuery は、ゲーム一覧（GET /api/games）の絞り込み条件です。
+type GameListQuery struct {
+	IncludeDeleted bool     // ?include_deleted=true のときゴミ箱のゲームも含める
+	Tags           []string // ?tag=co-op&tag=short のとき、指定したタグがすべて付いたゲームだけ返す
 }
 
 // CreateGameRequest は、ゲーム作成時のリクエストボディです。
 type CreateGameRequest struct {
 	// UserIDを削除（serviceで固定値を入れるため）
-	Title       string    `json:"title" binding:"required"`
-	Platform    string    `json:"platform"`
-	Genre       string    `json:"genre"`
-	Status      string    `json:"status"`
-	ReleaseDate time.Time `json:"release_date"`
+	Title          string    `json:"title" binding:"required"`
+	Platform       string    `json:"platform"`
+	Genre          string    `json:"genre"`
+	Status         string    `json:"status"`
+	ReleaseDate    time.Time `json:"release_date"`
+	EstimatedHours float64   `json:"estimated_hours"`
+	PlayedMinutes  int       `json:"played_minutes"`
+	SteamAppID     int       `json:"steam_app_id"`
+	PurchasePrice  *int      `json:"purchase_price"`
+	Currency       string    `json:"currency"`
+	PurchaseDate   string    `json:"purchase_date"`
+	Store          string    `json:"store"`
+	Priority       int       `json:"priority"`
+	Deadline       string    `json:"deadline"`
+	// AllowDuplicate が true なら、同じプラットフォームに同名のゲームがあっても登録します
+	AllowDuplicate bool `json:"allow_duplicate"`
 }
 
-// UpdateGameRequest は、ゲーム更新時のリクエストボディです。
+// UpdateGameRequest は、ゲーム更新（PUT /api/games/:id）のリクエストボディです。
+// PUT は全体の置き換えなので、省略した項目は空（未設定）になります。title と status は必須です。
 type UpdateGameRequest struct {
-	Title       string    `json:"title"`
-	Platform    string    `json:"platform"`
-	Genre       string    `json:"genre"`
-	Status      string    `json:"status"`
-	ReleaseDate time.Time `json:"release_date"`
-}
\ No newline at end of file
+	Title          string    `json:"title"`
+	Platform       string    `json:"platform"`
+	Genre          string    `json:"genre"`
+	Status         string    `json:"status"`
+	ReleaseDate    time.Time `json:"release_date"`
+	EstimatedHours float64   `json:"estimated_hours"`
+	PlayedMinutes  int       `json:"played_minutes"`
+	PurchasePrice  *int      `json:"purchase_price"`
+	Currency       string    `json:"currency"`
+	PurchaseDate   string    `json:"purchase_date"`
+	Store          string    `json:"store"`
+	Priority       int       `json:"priority"`
+	Deadline       string    `json:"deadline"`
+	StatusReason   string    `json:"status_reason"` // ステータス変更の理由（履歴に記録）
+}
+
+// GamePatch は、ゲームの部分更新（PATCH /api/games/:id）のリクエストボディです。
+// JSON Merge Patch (RFC 7396) の形式で、省略した項目は変更せず、null を指定した項目は未設定に戻します。
+// title と status は消せないので、null を指定するとエラーになります。
+type GamePatch struct {
+	Title          mergepatch.Field[string]    `json:"title"`
+	Platform       mergepatch.Field[string]    `json:"platform"`
+	Genre          mergepatch.Field[string]    `json:"genre"`
+	Status         mergepatch.Field[string]    `json:"status"`
+	ReleaseDate    mergepatch.Field[time.Time] `json:"release_date"`
+	EstimatedHours mergepatch.Field[float64]   `json:"estimated_hours"`
+	PlayedMinutes  mergepatch.Field[int]       `json:"played_minutes"`
+	PurchasePrice  mergepatch.Field[int]       `json:"purchase_price"`
+	Currency       mergepatch.Field[string]    `json:"currency"`
+	PurchaseDate   mergepatch.Field[string]    `json:"purchase_date"`
+	Store          mergepatch.Field[string]    `json:"store"`
+	Priority       mergepatch.Field[int]       `json:"priority"`
+	Deadline       mergepatch.Field[string]    `json:"deadline"`
+	StatusReason   string                      `json:"status_reason"` // ステータス変更の理由（履歴に記録）
+}
+
+// Note は、ゲームごとのメモ・感想（game_notes テーブル）を表す構造体です。
+type Note struct {
+	ID        int       `json:"id"`
+	GameID    int       `json:"game_id"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// JournalEntry は、プレイ日記（journal_entries テーブル）の1件を表す構造体です。
+// スケジュールかプレイセッションに紐づけて書きます。保存・API は task パッケージが持ちますが、
+// ゲーム詳細に最新の日記を載せるため、型は game パッケージに置いています（task → game の import のみで済むように）。
+type JournalEntry struct {
+	ID           int       `json:"id"`
+	UserID       string    `json:"user_id"`
+	GameID       int       `json:"game_id"`
+	ScheduleID   string    `json:"schedule_id"`   // 紐づけたスケジュール。なければ空文字
+	SessionID    int       `json:"session_id"`    // 紐づけたプレイセッション。なければ 0
+	Text         string    `json:"text"`          // 自由記述
+	Enjoyment    int       `json:"enjoyment"`     // 楽しさ 1〜5。0 は未評価
+	Mood         string    `json:"mood"`          // 気分（task パッケージの JournalMoods のいずれか）。未入力は空文字
+	ProgressNote string    `json:"progress_note"` // 進み具合のメモ（「3章まで」など）
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// GameDetail は、ゲーム詳細（GET /api/games/:id）のレスポンスです。
+// Game の項目に、最新のプレイ日記を足したものです。
+type GameDetail struct {
+	*Game
+	LatestJournal []*JournalEntry `json:"latest_journal"` // 新しい順に DetailJournalLimit 件まで
+}
+
+// NoteRequest は、メモ作成・更新時のリクエストボディです。
+type NoteRequest struct {
+	Body string `json:"body"`
+}
+
+// CreateNoteResponse は、メモ作成時のレスポンスです。
+// 最初のメモで「未開始」から「プレイ中」に変わった場合は StatusChanged が true になります。
+type CreateNoteResponse struct {
+	Note           *Note  `json:"note"`
+	StatusChanged  bool   `json:"status_changed"`
+	PreviousStatus string `json:"previous_status"`
+	Status         string `json:"status"`
+}
+
+// 完了報告の種類
+const (
+	CompletionStoryClear = "story_clear" // ストーリークリア
+	CompletionFullClear  = "full_clear"  // やり込み（100%）
+	CompletionDropped    = "dropped"     // 途中でやめた
+)
+
+// CompleteGameRequest は、完了報告（POST /api/games/:id/complete）のリクエストボディです。
+type CompleteGameRequest struct {
+	CompletedAt    *time.Time `json:"completed_at"` // 省略時は現在時刻（完了にしてあれば、その日時）
+	Rating         int        `json:"rating"`       // 1〜5（必須）
+	Review         string     `json:"review"`
+	CompletionType string     `json:"completion_type"` // story_clear, full_clear, dropped
+}
+
+// CompleteGameResponse は、完了報告のレスポンスです。
+type CompleteGameResponse struct {
+	Game       *Game             `json:"game"`
+	Bonus      int               `json:"bonus"`      // 付与された完了ボーナス
+	Motivation *score.Motivation `json:"motivation"` // ボーナス反映後のポイント（dropped のときは null）
+}
+
+// ImportItem は、一括インポートで登録しようとする1件です（Service.ImportRows に渡します）。
+type ImportItem struct {
+	Request  *CreateGameRequest
+	Problems []string // 変換時に見つかった問題（空なら登録できる）
+	Skip     string   // 登録対象外の理由（ウィッシュリストなど）。空なら対象
+}
+
+// ImportRowResult は、インポートファイル1行分の結果です。
+type ImportRowResult struct {
+	Row    int      `json:"row"` // データ行の番号（ヘッダーを除いて1始まり）
+	Title  string   `json:"title"`
+	Result string   `json:"result"`           // valid（dry run で問題なし）, created, invalid, skipped
+	Reason string   `json:"reason,omitempty"` // skipped の理由
+	Errors []string `json:"errors,omitempty"`
+	Game   *Game    `json:"game,omitempty"` // 作成したゲーム（created のときのみ）
+}
+
+// ImportReport は、一括インポート（POST /api/games/import）の結果です。
+// dry run のときは検証だけ行い、ゲームは作成しません。
+type ImportReport struct {
+	DryRun  bool              `json:"dry_run"`
+	Total   int               `json:"total"`
+	Valid   int               `json:"valid"`
+	Invalid int               `json:"invalid"`
+	Skipped int               `json:"skipped"` // 取り込み対象外（ウィッシュリストなど）
+	Created int               `json:"created"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+// DuplicateGroup は、重複登録らしいゲームのまとまりです（GET /api/games/duplicates）。
+type DuplicateGroup struct {
+	NormalizedTitle string  `json:"normalized_title"`
+	Games           []*Game `json:"games"`
+}
+
+// MergeGameRequest は、ゲームの統合（POST /api/games/:id/merge）のリクエストボディです。
+// source_id のゲームを :id のゲームに統合し、source_id のゲームは削除します。
+type MergeGameRequest struct {
+	SourceID int `json:"source_id"`
+}
+
+// Tag は、ユーザーが自由に作れるタグ（tags テーブル）を表す構造体です。
+// ジャンルとは別に「co-op」「short」「週末向け」のような分類に使います。
+type Tag struct {
+	ID     int    `json:"id"`
+	UserID int    `json:"user_id"`
+	Name   string `json:"name"`  // ユーザーごとに一意（大文字・小文字は区別しない）
+	Color  string `json:"color"` // #RRGGBB
+	// スケジュールを入れてよい曜日（sun, mon, ... sat）。空ならいつでも
+	ScheduleDays []string  `json:"schedule_days"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// weekdayNames は、Tag.ScheduleDays で使う曜日の表記です。
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// AllowsDay は、このタグのゲームを day にスケジュールしてよいかを返します。
+func (t *Tag) AllowsDay(day time.Weekday) bool {
+	if len(t.ScheduleDays) == 0 {
+		return true
+	}
+	for _, name := range t.ScheduleDays {
+		if weekdayNames[name] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultTagColor は、色を指定せずに作ったタグの色です。
+const DefaultTagColor = "#9ca3af"
+
+// TagRequest は、タグ作成・更新時のリクエストボディです。
+type TagRequest struct {
+	Name         string   `json:"name"`
+	Color        string   `json:"color"`         // 省略時は DefaultTagColor
+	ScheduleDays []string `json:"schedule_days"` // 例: ["sat", "sun"]
+}
+
+// Platform は、プラットフォームのカタログ（platforms テーブル）を表す構造体です。
+// Aliases のどれかで登録されたゲームは、Name にそろえて保存します（「PS5」→「PlayStation 5」など）。
+type Platform struct {
+	ID      int      `json:"id"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+}
+
+// 一括操作の種類
+const (
+	BulkOpSetStatus   = "set_status"   // ステータスを変更する（遷移のルールは通常の更新と同じ）
+	BulkOpAddTag      = "add_tag"      // タグを付ける
+	BulkOpSetPlatform = "set_platform" // プラットフォームを変更する（空文字で未設定に戻す）
+	BulkOpDelete      = "delete"       // ゴミ箱に移す
+)
+
+// 一括操作の実行モード
+const (
+	BulkModeAtomic     = "atomic"      // 1件でも失敗したら全体を取り消す（既定）
+	BulkModeBestEffort = "best_effort" // 失敗した項目だけ取り消し、残りは反映する
+)
+
+// BulkOperation は、一括操作の1つの操作です。IDs のゲームそれぞれに同じ操作を行います。
+type BulkOperation struct {
+	Op       string `json:"op"`
+	IDs      []int  `json:"ids"`
+	Status   string `json:"status"`   // set_status のとき
+	Reason   string `json:"reason"`   // set_status のとき、履歴に記録する理由（省略時は "bulk update"）
+	TagID    int    `json:"tag_id"`   // add_tag のとき
+	Platform string `json:"platform"` // set_platform のとき
+}
+
+// BulkRequest は、一括操作（POST /api/games/bulk）のリクエストボディです。
+// すべての操作を1つのトランザクションで、書かれた順に実行します。
+type BulkRequest struct {
+	Mode       string          `json:"mode"` // atomic, best_effort
+	Operations []BulkOperation `json:"operations"`
+}
+
+// 一括操作の項目ごとの結果
+const (
+	BulkResultOK         = "ok"
+	BulkResultFailed     = "failed"
+	BulkResultRolledBack = "rolled_back" // 成功したが、atomic モードで他の項目が失敗したため取り消された
+	BulkResultNotRun     = "not_run"     // atomic モードで先に失敗した項目があったため実行しなかった
+)
+
+// BulkItemResult は、一括操作の1件（操作 × ゲーム）の結果です。
+type BulkItemResult struct {
+	Operation int    `json:"operation"` // Operations の何番目か（0始まり）
+	Op        string `json:"op"`
+	ID        int    `json:"id"`
+	Result    string `json:"result"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BulkResponse は、一括操作の結果です。
+type BulkResponse struct {
+	Mode      string           `json:"mode"`
+	Committed bool             `json:"committed"` // 変更が保存されたか（atomic で失敗した場合は false）
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// ゲーム同士の関係の種類
+const (
+	RelationDLCOf    = "dlc_of"    // game_id は related_game_id（本編）の DLC
+	RelationSequelOf = "sequel_of" // game_id は related_game_id（前作）の続編。シリーズのプレイ順
+)
+
+// GameRelation は、ゲーム同士の関係（game_relations テーブル）を表す構造体です。
+// どちらの種類も related_game_id が「先にクリアしておくゲーム」で、
+// related_game_id がクリアされるまで game_id のゲームはスケジュールされません。
+type GameRelation struct {
+	ID            int       `json:"id"`
+	GameID        int       `json:"game_id"`         // DLC・続編
+	RelatedGameID int       `json:"related_game_id"` // 本編・前作
+	Type          string    `json:"type"`            // dlc_of, sequel_of
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// RelationRequest は、関係の追加（POST /api/games/:id/relations）のリクエストボディです。
+// :id のゲームが related_game_id のゲームの DLC・続編であることを登録します。
+type RelationRequest struct {
+	Type          string `json:"type"`
+	RelatedGameID int    `json:"related_game_id"`
+}
+
+// GraphNode は、関係グラフ（GET /api/games/graph）のゲーム1つ分です。
+type GraphNode struct {
+	ID     int    `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	// まだクリアされていない本編・前作のID。空でなければスケジュールされません
+	BlockedBy []int `json:"blocked_by"`
+}
+
+// GameGraph は、関係のあるゲームとその関係の一覧です。
+// edges は related_game_id（本編・前作）→ game_id（DLC・続編）の向きに読みます。
+type GameGraph struct {
+	Nodes []*GraphNode    `json:"nodes"`
+	Edges []*GameRelation `json:"edges"`
+}
+
+// SpendingSummary は、購入金額の集計（GET /api/games/spending）の1グループ分です。
+// 金額はすべて SpendingReport.Currency の最小単位です。
+type SpendingSummary struct {
+	Platform string `json:"platform,omitempty"` // プラットフォーム別のときだけ
+	Year     *int   `json:"year,omitempty"`     // 購入年別のときだけ（購入日が未入力のグループは null）
+
+	Games int   `json:"games"` // 価格が入力されているゲームの数
+	Spent int64 `json:"spent"` // 購入金額の合計
+
+	// 未開始（積みゲー）のまま眠っているお金
+	UnplayedGames  int   `json:"unplayed_games"`
+	UnplayedAmount int64 `json:"unplayed_amount"`
+
+	// クリア済みのゲームの、プレイ1時間あたりの金額
+	// プレイ時間は played_minutes、未入力なら estimated_hours を使います。時間が0なら null
+	CompletedGames       int      `json:"completed_games"`
+	CompletedAmount      int64    `json:"completed_amount"`
+	CompletedHours       float64  `json:"completed_hours"`
+	CostPerCompletedHour *float64 `json:"cost_per_completed_hour"`
+
+	// 未開始のゲームの、購入日からの経過日数（購入日が入力されているものだけ）。該当なしなら null
+	AverageBacklogDays *float64 `json:"average_backlog_days"`
+	OldestBacklogDays  *int     `json:"oldest_backlog_days"`
+}
+
+// SpendingReport は、購入金額の集計結果です。
+// 通貨をまたいだ合計はできないので、1つの通貨のゲームだけを集計します。
+type SpendingReport struct {
+	Currency   string             `json:"currency"`
+	Total      *SpendingSummary   `json:"total"`
+	ByPlatform []*SpendingSummary `json:"by_platform"`
+	ByYear     []*SpendingSummary `json:"by_year"`
+	// 集計から外した（別の通貨で入力された）ゲームの通貨。?currency= で切り替えられます
+	OtherCurrencies []string `json:"other_currencies"`
+}
+
+// GameCount は、ゲーム数の集計（ステータス別・プラットフォーム別など）の1行です。
+type GameCount struct {
+	Key   string `json:"key"` // 未入力のプラットフォーム・ジャンルは空文字
+	Count int    `json:"count"`
+}
@@ -0,0 +1,136 @@
+package game
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// editionWords は、「Edition」の前か括弧の中にあるときだけエディション表記とみなす語です。
+// 「Super Smash Bros. Ultimate」のように、単独ではタイトルの一部であることが多いためです。
+const (
+	editionWords   = `game of the year|goty|digital|deluxe|definitive|complete|ultimate|gold|standard|premium|collector'?s|anniversary|enhanced|special|launch|day one`
+	editionWordsJa = `デラックス|ゴールド|コンプリート|アルティメット|スペシャル|プレミアム`
+)
+
+// editionSuffixes は、同じゲームの別エディションを表す末尾の表記です。
+// 重複チェックでは「Elden Ring」と「ELDEN RING Deluxe Edition」を同じゲームとみなします。
+var editionSuffixes = []*regexp.Regexp{
+	// 単独でもエディション表記とわかるもの（「GOTY」「Director's Cut」「完全版」など）
+	regexp.MustCompile(`(?i)[\s\-:‐–—]*[(\[]?\s*(game of the year|goty|director'?s cut)(\s+edition)?\s*[)\]]?$`),
+	regexp.MustCompile(`[\s\-:‐–—]*[(\[]?\s*(完全版|決定版|通常版|特別版|限定版|ディレクターズカット)\s*[)\]]?$`),
+	// 「Ultimate Edition」「Complete Deluxe Edition」「ゴールドエディション」
+	regexp.MustCompile(`(?i)[\s\-:‐–—]*[(\[]?\s*((` + editionWords + `)\s+)+edition\s*[)\]]?$`),
+	regexp.MustCompile(`[\s\-:‐–—]*[(\[]?\s*(` + editionWordsJa + `)+[・\s]?エディション\s*[)\]]?$`),
+	// 括弧の中だけのもの（「(Gold)」「[Deluxe]」「（デラックス）」）
+	regexp.MustCompile(`(?i)[\s\-:‐–—]*[(\[]\s*(` + editionWords + `)(\s+(` + editionWords + `))*\s*[)\]]$`),
+	regexp.MustCompile(`[\s\-:‐–—]*[(\[]\s*(` + editionWordsJa + `)\s*[)\]]$`),
+}
+
+// trademarkReplacer は、タイトルに付く商標記号を取り除きます。
+var trademarkReplacer = strings.NewReplacer("™", "", "®", "", "©", "")
+
+// NormalizeTitle は、重複チェック用にタイトルを正規化します。
+//   - 全角英数字は半角に、半角カナは全角にそろえる（NFKC）
+//   - 大文字・小文字を区別しない
+//   - 「Deluxe Edition」「完全版」などのエディション表記を取り除く
+//   - 記号・空白を取り除く（「™」「:」「・」など）
+func NormalizeTitle(title string) string {
+	// NFKC だと「™」が「TM」になってしまうので、先に取り除く
+	title = trademarkReplacer.Replace(title)
+	s := strings.ToLower(norm.NFKC.String(title))
+	s = strings.TrimSpace(s)
+
+	// 「Complete Deluxe Edition」のように重なっている場合もあるので、変わらなくなるまで繰り返す
+	for {
+		before := s
+		for _, re := range editionSuffixes {
+			s = strings.TrimSpace(re.ReplaceAllString(s, ""))
+		}
+		if s == before || s == "" {
+			break
+		}
+	}
+	if s == "" {
+		// エディション表記だけのタイトルは、そのまま比較する
+		s = strings.ToLower(norm.NFKC.String(title))
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// normalizePlatform は、重複チェック用にプラットフォーム名を正規化します。
+func normalizePlatform(platform string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(norm.NFKC.String(platform)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// IsLikelyDuplicate は、2つのゲームが同じゲームの重複登録らしいかを返します。
+// タイトルが正規化して一致し、プラットフォームが同じか片方が未設定の場合に重複とみなします。
+func IsLikelyDuplicate(title1, platform1, title2, platform2 string) bool {
+	if NormalizeTitle(title1) != NormalizeTitle(title2) {
+		return false
+	}
+	p1, p2 := normalizePlatform(platform1), normalizePlatform(platform2)
+	return p1 == "" || p2 == "" || p1 == p2
+}
+
+// DuplicateChecker は、一括インポート中の重複を調べます。
+// 登録済みのゲームに加えて、同じファイル内で先に出てきた行とも比べます（dry run でも同じ結果になるように）。
+type DuplicateChecker struct {
+	svc  Service
+	seen []seenRow
+}
+
+// seenRow は、インポート対象として受け付けた行です。
+type seenRow struct {
+	row      int
+	title    string
+	platform string
+}
+
+// NewDuplicateChecker は、新しい DuplicateChecker を作成します。
+func NewDuplicateChecker(svc Service) *DuplicateChecker {
+	return &DuplicateChecker{svc: svc}
+}
+
+// Check は、req が重複であればその理由（「duplicate of #12」など）を返します。重複でなければ空文字です。
+func (c *DuplicateChecker) Check(req *CreateGameRequest) (string, error) {
+	if req.AllowDuplicate {
+		return "", nil
+	}
+
+	duplicates, err := c.svc.FindDuplicates(req.Title, req.Platform, 0)
+	if err != nil {
+		return "", err
+	}
+	if len(duplicates) > 0 {
+		return fmt.Sprintf("duplicate of #%d", duplicates[0].ID), nil
+	}
+
+	for _, r := range c.seen {
+		if IsLikelyDuplicate(req.Title, req.Platform, r.title, r.platform) {
+			return fmt.Sprintf("duplicate of row %d", r.row), nil
+		}
+	}
+	return "", nil
+}
+
+// Add は、row 行目の req をインポート対象として受け付けたことを記録します。
+func (c *DuplicateChecker) Add(row int, req *CreateGameRequest) {
+	c.seen = append(c.seen, seenRow{row: row, title: req.Title, platform: req.Platform})
+}
@@ -0,0 +1,66 @@
+package game
+
+import "testing"
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		// エディション表記を取り除く
+		{title: "ELDEN RING Deluxe Edition", want: "eldenring"},
+		{title: "The Witcher 3: Wild Hunt – Game of the Year Edition", want: "thewitcher3wildhunt"},
+		{title: "Fallout 4 GOTY", want: "fallout4"},
+		{title: "Death Stranding Director's Cut", want: "deathstranding"},
+		{title: "Horizon Zero Dawn Complete Edition", want: "horizonzerodawn"},
+		{title: "Borderlands 3 Ultimate Edition", want: "borderlands3"},
+		{title: "Control (Ultimate Edition)", want: "control"},
+		{title: "Cyberpunk 2077 Complete Deluxe Edition", want: "cyberpunk2077"},
+		{title: "Resident Evil Village [Gold]", want: "residentevilvillage"},
+		{title: "Skyrim Anniversary Edition (GOTY)", want: "skyrim"},
+		{title: "ペルソナ5 ザ・ロイヤル 完全版", want: "ペルソナ5ザロイヤル"},
+		{title: "モンスターハンターライズ デラックスエディション", want: "モンスターハンターライズ"},
+		{title: "モンスターハンターライズ（デラックス）", want: "モンスターハンターライズ"},
+
+		// 単独の語はタイトルの一部として残す
+		{title: "Super Smash Bros. Ultimate", want: "supersmashbrosultimate"},
+		{title: "Pokémon Gold", want: "pokémongold"},
+		{title: "Mario Kart 8 Deluxe", want: "mariokart8deluxe"},
+		{title: "Tony Hawk's Pro Skater Complete", want: "tonyhawksproskatercomplete"},
+		{title: "Halo: The Master Chief Collection", want: "halothemasterchiefcollection"},
+		{title: "Minecraft: Java Edition", want: "minecraftjavaedition"},
+
+		// 全角・商標記号・大文字小文字
+		{title: "ＦＩＮＡＬ ＦＡＮＴＡＳＹ Ⅶ", want: "finalfantasyvii"},
+		{title: "Portal™ 2", want: "portal2"},
+		{title: "ｶﾞﾝﾀﾞﾑ", want: "ガンダム"},
+		// エディション表記だけのタイトルは、そのまま比較する
+		{title: "Deluxe Edition", want: "deluxeedition"},
+		{title: "GOTY", want: "goty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := NormalizeTitle(tt.title); got != tt.want {
+				t.Errorf("NormalizeTitle(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsLikelyDuplicate(t *testing.T) {
+	tests := []struct {
+		title1, platform1, title2, platform2 string
+		want                                 bool
+	}{
+		{"Elden Ring", "PS5", "ELDEN RING Deluxe Edition", "ps5", true},
+		{"Elden Ring", "", "Elden Ring", "PC", true},
+		{"Elden Ring", "PS5", "Elden Ring", "PC", false},
+		{"Super Smash Bros. Ultimate", "Switch", "Super Smash Bros.", "Switch", false},
+		{"Pokémon Gold", "", "Pokémon", "", false},
+	}
+	for _, tt := range tests {
+		if got := IsLikelyDuplicate(tt.title1, tt.platform1, tt.title2, tt.platform2); got != tt.want {
+			t.Errorf("IsLikelyDuplicate(%q, %q, %q, %q) = %v, want %v", tt.title1, tt.platform1, tt.title2, tt.platform2, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package game
+
+import (
+	"strings"
+	"time"
+)
+
+// MaxPriority は、優先度の最大値です（0 は未設定）。
+const MaxPriority = 5
+
+// normalizePlan は遊ぶ順番の希望（優先度・期限）を検証し、保存する形にそろえます。
+//   - 優先度は 0〜MaxPriority
+//   - 期限は YYYY-MM-DD（過ぎた日付も可。期限切れとして扱う）
+func normalizePlan(g *Game) error {
+	if g.Priority < 0 || g.Priority > MaxPriority {
+		return ErrInvalidPriority
+	}
+
+	g.Deadline = strings.TrimSpace(g.Deadline)
+	if g.Deadline != "" {
+		if _, err := time.ParseInLocation(dateLayout, g.Deadline, time.Local); err != nil {
+			return ErrInvalidDeadline
+		}
+	}
+	return nil
+}
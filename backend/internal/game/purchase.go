@@ -0,0 +1,42 @@
+package game
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultCurrency は、価格だけ入力して通貨を省略したときの通貨です。
+const DefaultCurrency = "JPY"
+
+// currencyPattern は、通貨コードとして受け付ける形式（ISO 4217 の3文字）です。
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// normalizePurchase は購入情報を検証し、保存する形にそろえます。
+//   - 価格は0以上（通貨の最小単位）
+//   - 通貨コードは大文字に。価格があって通貨が空なら DefaultCurrency
+//   - 購入日は YYYY-MM-DD で、未来の日付は不可
+func normalizePurchase(g *Game) error {
+	if g.PurchasePrice != nil && *g.PurchasePrice < 0 {
+		return ErrNegativePrice
+	}
+
+	g.Currency = strings.ToUpper(strings.TrimSpace(g.Currency))
+	if g.Currency == "" && g.PurchasePrice != nil {
+		g.Currency = DefaultCurrency
+	}
+	if g.Currency != "" && !currencyPattern.MatchString(g.Currency) {
+		return ErrInvalidCurrency
+	}
+
+	g.PurchaseDate = strings.TrimSpace(g.PurchaseDate)
+	if g.PurchaseDate != "" {
+		d, err := time.ParseInLocation(dateLayout, g.PurchaseDate, time.Local)
+		if err != nil || d.After(time.Now()) {
+			return ErrInvalidPurchaseDate
+		}
+	}
+
+	g.Store = strings.TrimSpace(g.Store)
+	return nil
+}
@@ -0,0 +1,63 @@
+package game
+
+import "sort"
+
+// IsValidRelationType は、関係の種類として使える値かを返します。
+func IsValidRelationType(t string) bool {
+	return t == RelationDLCOf || t == RelationSequelOf
+}
+
+// isFinished は、DLC・続編のスケジュールを止めなくてよい（クリア済み・途中でやめた）ステータスかを返します。
+// 前作を途中でやめた場合も、続編をずっと止めたままにはしません。
+func isFinished(status string) bool {
+	return status == StatusCompleted || status == StatusDropped
+}
+
+// blockedBy は、ゲームIDごとに、まだ終わっていない本編・前作のIDを返します。
+// games にないゲーム（ゴミ箱のゲームなど）との関係は無視します。
+func blockedBy(games map[int]*Game, rels []*GameRelation) map[int][]int {
+	blocked := map[int][]int{}
+	for _, rel := range rels {
+		before, after := games[rel.RelatedGameID], games[rel.GameID]
+		if before == nil || after == nil || isFinished(before.Status) {
+			continue
+		}
+		blocked[after.ID] = append(blocked[after.ID], before.ID)
+	}
+	return blocked
+}
+
+// playOrder は、関係のあるゲームを本編・前作が先になるように並べます（トポロジカルソート）。
+// 順番が決まらないものは ID の小さい順です。関係が循環していれば ok が false になります。
+func playOrder(rels []*GameRelation) (order []int, ok bool) {
+	next := map[int][]int{}
+	indegree := map[int]int{}
+	for _, rel := range rels {
+		next[rel.RelatedGameID] = append(next[rel.RelatedGameID], rel.GameID)
+		indegree[rel.GameID]++
+		if _, seen := indegree[rel.RelatedGameID]; !seen {
+			indegree[rel.RelatedGameID] = 0
+		}
+	}
+
+	var ready []int
+	for id, n := range indegree {
+		if n == 0 {
+			ready = append(ready, id)
+		}
+	}
+	for len(ready) > 0 {
+		sort.Ints(ready)
+		id := ready[0]
+		ready = ready[1:]
+		order = append(order, id)
+		for _, after := range next[id] {
+			indegree[after]--
+			if indegree[after] == 0 {
+				ready = append(ready, after)
+			}
+		}
+	}
+
+	return order, len(order) == len(indegree)
+}
@@ -0,0 +1,89 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+// rel は、after が before の続編（DLC）である関係を作ります。
+func rel(after, before int) *GameRelation {
+	return &GameRelation{GameID: after, RelatedGameID: before, Type: RelationSequelOf}
+}
+
+func TestPlayOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		rels   []*GameRelation
+		want   []int
+		wantOK bool
+	}{
+		{name: "no relations", rels: nil, want: nil, wantOK: true},
+		{name: "series", rels: []*GameRelation{rel(3, 2), rel(2, 1)}, want: []int{1, 2, 3}, wantOK: true},
+		{
+			name:   "base game with DLCs and a sequel",
+			rels:   []*GameRelation{rel(12, 10), rel(11, 10), rel(20, 10)},
+			want:   []int{10, 11, 12, 20},
+			wantOK: true,
+		},
+		{
+			name:   "independent series are ordered by smallest ready id",
+			rels:   []*GameRelation{rel(5, 4), rel(2, 1), rel(3, 2)},
+			want:   []int{1, 2, 3, 4, 5},
+			wantOK: true,
+		},
+		{
+			name:   "a sequel of two games waits for both",
+			rels:   []*GameRelation{rel(9, 1), rel(9, 8), rel(2, 1)},
+			want:   []int{1, 2, 8, 9},
+			wantOK: true,
+		},
+		{name: "cycle", rels: []*GameRelation{rel(1, 2), rel(2, 3), rel(3, 1)}, want: nil, wantOK: false},
+		{name: "cycle after a valid prefix", rels: []*GameRelation{rel(2, 1), rel(3, 2), rel(2, 3)}, want: []int{1}, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := playOrder(tt.rels)
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("playOrder() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestBlockedBy(t *testing.T) {
+	games := map[int]*Game{
+		1: {ID: 1, Status: StatusCompleted},
+		2: {ID: 2, Status: StatusPlaying},
+		3: {ID: 3, Status: StatusUnstarted},
+		4: {ID: 4, Status: StatusDropped},
+		5: {ID: 5, Status: StatusUnstarted},
+		6: {ID: 6, Status: StatusUnstarted},
+	}
+	tests := []struct {
+		name string
+		rels []*GameRelation
+		want map[int][]int
+	}{
+		{name: "no relations", rels: nil, want: map[int][]int{}},
+		{name: "finished predecessor does not block", rels: []*GameRelation{rel(2, 1)}, want: map[int][]int{}},
+		{name: "dropped predecessor does not block", rels: []*GameRelation{rel(5, 4)}, want: map[int][]int{}},
+		{name: "unfinished predecessor blocks", rels: []*GameRelation{rel(3, 2)}, want: map[int][]int{3: {2}}},
+		{
+			name: "only unfinished predecessors are listed",
+			rels: []*GameRelation{rel(6, 1), rel(6, 2), rel(6, 3)},
+			want: map[int][]int{6: {2, 3}},
+		},
+		{
+			name: "games not in the list are ignored",
+			rels: []*GameRelation{rel(3, 99), rel(99, 2)},
+			want: map[int][]int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blockedBy(games, tt.rels); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("blockedBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package game
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
 	"time"
 )
 
@@ -12,39 +15,254 @@ type Repository interface {
 	GetGameByID(id int) (*Game, error)
 	GetGamesByUserID(userID int) ([]*Game, error)
 	UpdateGame(game *Game) error
-	DeleteGame(id int) error
+	// AddPlayedMinutes は、累計プレイ時間に minutes を足します（プレイセッションの記録用。版も上げます）。
+	AddPlayedMinutes(id int, minutes int) error
+	DeleteGame(id int) error // 物理削除（ゴミ箱から完全に消すとき用）
+
+	// ゴミ箱（論理削除）
+	// GetGameByID / GetGamesByUserID はゴミ箱のゲームを返しません
+	GetGameByIDIncludingDeleted(id int) (*Game, error)
+	GetGamesByUserIDIncludingDeleted(userID int) ([]*Game, error)
+	SoftDeleteGame(id int, version int) error // version が一致しなければ ErrVersionMismatch
+	RestoreGame(id int, version int) error    // version が一致しなければ ErrVersionMismatch
+	DeleteNotesByGameID(gameID int) error
+	DeleteStatusHistoryByGameID(gameID int) error
+
+	// 統合（重複の解消）
+	MoveNotes(fromGameID int, toGameID int) error
+	MoveStatusHistory(fromGameID int, toGameID int) error
+	MoveGameTags(fromGameID int, toGameID int) error
+
+	// メモ・感想 (Note)
+	CreateNote(note *Note) (int, error)
+	GetNoteByID(id int) (*Note, error)
+	GetNotesByGameID(gameID int) ([]*Note, error)
+	CountNotesByGameID(gameID int) (int, error)
+	UpdateNote(note *Note) error
+	DeleteNote(id int) error
+
+	// ステータス変更履歴 (StatusHistory)
+	CreateStatusHistory(history *StatusHistory) error
+	GetStatusHistoryByGameID(gameID int) ([]*StatusHistory, error)
+	// GetStatusHistoryByUserID は、ユーザーのすべてのゲーム（ゴミ箱のゲームも含む）の変更履歴を古い順に返します。
+	GetStatusHistoryByUserID(userID int) ([]*StatusHistory, error)
+
+	// タグ (Tag)
+	// GetGameByID / GetGamesByUserID は、ゲームに付いているタグも Game.Tags に詰めて返します
+	CreateTag(tag *Tag) (int, error)
+	GetTagByID(id int) (*Tag, error)
+	GetTagByName(userID int, name string) (*Tag, error)
+	GetTagsByUserID(userID int) ([]*Tag, error)
+	UpdateTag(tag *Tag) error
+	DeleteTag(id int) error
+	AddGameTag(gameID int, tagID int) error
+	RemoveGameTag(gameID int, tagID int) error
+	DeleteGameTagsByGameID(gameID int) error
+	DeleteGameTagsByTagID(tagID int) error
+
+	// ゲーム同士の関係 (GameRelation)
+	CreateRelation(rel *GameRelation) (int, error)
+	GetRelationByID(id int) (*GameRelation, error)
+	// GetRelationsByUserID は、指定されたユーザーのゲームの関係をすべて返します（ゴミ箱のゲームの関係も含む）。
+	GetRelationsByUserID(userID int) ([]*GameRelation, error)
+	DeleteRelation(id int) error
+	DeleteRelationsByGameID(gameID int) error // gameID がどちら側でも削除します
+	MoveRelations(fromGameID int, toGameID int) error
+
+	// 購入金額の集計
+	// GetSpendingSummaries は、currency で価格が入力されたゲームを groupBy ごとに集計します。
+	// groupBy は SpendingByPlatform / SpendingByYear / SpendingTotal のどれかです。
+	GetSpendingSummaries(userID int, currency string, groupBy string) ([]*SpendingSummary, error)
+	// GetPurchaseCurrencies は、価格の入力に使われている通貨を、ゲームの多い順に返します。
+	GetPurchaseCurrencies(userID int) ([]string, error)
+
+	// 統計（GET /api/stats）
+	// from 以上 to 未満の期間で集計します。ゴミ箱のゲームは含みません。
+	// CountGamesBy は、期間内に登録したゲームの数を groupBy（status / platform / genre）ごとに数えます。
+	CountGamesBy(userID int, groupBy string, from time.Time, to time.Time) ([]*GameCount, error)
+	// CountCompletedGames は、期間内に登録したゲームの数と、そのうちクリア済みの数を返します。
+	CountCompletedGames(userID int, from time.Time, to time.Time) (games int, completed int, err error)
+	// AverageDaysToComplete は、期間内にクリアしたゲームの数と、登録からクリアまでの平均日数を返します。
+	// 該当するゲームがなければ平均は nil です。
+	AverageDaysToComplete(userID int, from time.Time, to time.Time) (games int, avgDays *float64, err error)
+
+	// プラットフォームのカタログ (Platform)
+	// EnsurePlatforms は、まだ登録されていないプラットフォームと別名を追加します（起動時に呼びます）。
+	EnsurePlatforms(platforms []*Platform) error
+	GetPlatforms() ([]*Platform, error)
+	// GetPlatformByAlias は、名前か別名でプラットフォームを探します。見つからなければ nil を返します。
+	GetPlatformByAlias(alias string) (*Platform, error)
+
+	// トランザクション
+	// WithTx は、指定したトランザクション上で動作するリポジトリを返します。
+	// calendar など他パッケージのリポジトリと同じトランザクションを共有するときに使います。
+	WithTx(tx *sql.Tx) Repository
+	// RunInTx は fn をトランザクション内で実行します。fn がエラーを返すとロールバックします。
+	// すでにトランザクション上のリポジトリから呼ばれた場合は、そのトランザクションをそのまま使います。
+	RunInTx(fn func(tx *sql.Tx) error) error
+	// RunInSavepoint は、トランザクションの中で fn をセーブポイントつきで実行します。
+	// fn がエラーを返すと fn の変更だけを取り消し、トランザクション自体は続けられます。
+	// トランザクション上のリポジトリ（WithTx）から呼んでください。
+	RunInSavepoint(fn func() error) error
+}
+
+// querier は *sql.DB と *sql.Tx に共通するメソッドをまとめたインターフェースです。
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
 }
 
 // repository は Repository インターフェースの具体的な実装です。
 // DB接続（*sql.DB）を持ちます。
 type repository struct {
 	db *sql.DB
+	tx *sql.Tx // トランザクション中のみ設定される
+	q  querier // 実際にSQLを発行する先（通常は db、トランザクション中は tx）
 }
 
 // NewRepository は、新しい repository インスタンスを作成します。
 // main.go などでDB接続を確立した後、それを渡して呼び出します。
 func NewRepository(db *sql.DB) Repository {
-	return &repository{db: db}
+	return &repository{db: db, q: db}
+}
+
+// WithTx は、指定したトランザクション上で動作するリポジトリを返します。
+func (r *repository) WithTx(tx *sql.Tx) Repository {
+	return &repository{db: r.db, tx: tx, q: tx}
+}
+
+// RunInTx は fn をトランザクション内で実行します。
+func (r *repository) RunInTx(fn func(tx *sql.Tx) error) error {
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RunInSavepoint は fn をセーブポイントつきで実行します。
+func (r *repository) RunInSavepoint(fn func() error) error {
+	if r.tx == nil {
+		return errors.New("RunInSavepoint must be called on a repository bound to a transaction")
+	}
+
+	if _, err := r.tx.Exec(`SAVEPOINT game_item`); err != nil {
+		log.Printf("Error creating savepoint: %v", err)
+		return err
+	}
+	if err := fn(); err != nil {
+		if _, rbErr := r.tx.Exec(`ROLLBACK TO game_item`); rbErr != nil {
+			log.Printf("Error rolling back to savepoint: %v", rbErr)
+			return rbErr
+		}
+		if _, relErr := r.tx.Exec(`RELEASE game_item`); relErr != nil {
+			log.Printf("Error releasing savepoint: %v", relErr)
+			return relErr
+		}
+		return err
+	}
+	_, err := r.tx.Exec(`RELEASE game_item`)
+	return err
 }
 
 // --- インターフェースの実装 ---
 
+// gameColumns は games テーブルから取得するカラムの一覧です。scanGame と順番を合わせてください。
+const gameColumns = `id, user_id, title, platform, genre, status, release_date, estimated_hours,
+	played_minutes, steam_app_id, completed_at, rating, review, completion_type, deleted_at, created_at, updated_at, version, cover_key,
+	purchase_price, currency, purchase_date, store, priority, deadline`
+
+// rowScanner は *sql.Row と *sql.Rows の共通メソッドです。
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanGame は gameColumns の順で1行を読み取り、Game に詰めます。
+func scanGame(row rowScanner) (*Game, error) {
+	var game Game
+	var completedAt, deletedAt sql.NullTime
+	var purchasePrice sql.NullInt64
+	if err := row.Scan(
+		&game.ID,
+		&game.UserID,
+		&game.Title,
+		&game.Platform,
+		&game.Genre,
+		&game.Status,
+		&game.ReleaseDate,
+		&game.EstimatedHours,
+		&game.PlayedMinutes,
+		&game.SteamAppID,
+		&completedAt,
+		&game.Rating,
+		&game.Review,
+		&game.CompletionType,
+		&deletedAt,
+		&game.CreatedAt,
+		&game.UpdatedAt,
+		&game.Version,
+		&game.CoverKey,
+		&purchasePrice,
+		&game.Currency,
+		&game.PurchaseDate,
+		&game.Store,
+		&game.Priority,
+		&game.Deadline,
+	); err != nil {
+		return nil, err
+	}
+	game.setCoverURLs()
+	if completedAt.Valid {
+		game.CompletedAt = &completedAt.Time
+	}
+	if deletedAt.Valid {
+		game.DeletedAt = &deletedAt.Time
+	}
+	if purchasePrice.Valid {
+		price := int(purchasePrice.Int64)
+		game.PurchasePrice = &price
+	}
+	return &game, nil
+}
+
 // CreateGame は新しいゲームをDBに作成します。作成したゲームのIDを返します。
 func (r *repository) CreateGame(game *Game) (int, error) {
 	// 認証なしの暫定対応として、game.UserID はサービス層で設定済みと仮定
-	query := `INSERT INTO games (user_id, title, platform, genre, status, release_date, created_at, updated_at)
-			  VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO games (user_id, title, platform, genre, status, release_date, estimated_hours,
+			  played_minutes, steam_app_id, purchase_price, currency, purchase_date, store, priority, deadline, completed_at, created_at, updated_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	// Go 1.22以降なら time.Now() でOK。それ以前なら time.Now().UTC() などDBの型に合わせる
 	now := time.Now()
 
-	result, err := r.db.Exec(query,
+	result, err := r.q.Exec(query,
 		game.UserID,
 		game.Title,
 		game.Platform,
 		game.Genre,
 		game.Status,
 		game.ReleaseDate,
+		game.EstimatedHours,
+		game.PlayedMinutes,
+		game.SteamAppID,
+		game.PurchasePrice,
+		game.Currency,
+		game.PurchaseDate,
+		game.Store,
+		game.Priority,
+		game.Deadline,
+		game.CompletedAt,
 		now, // CreatedAt
 		now, // UpdatedAt
 	)
@@ -62,24 +280,24 @@ func (r *repository) CreateGame(game *Game) (int, error) {
 	return int(id), nil
 }
 
-// GetGameByID は ID でゲームを1件取得します。
+// GetGameByID は ID でゲームを1件取得します。ゴミ箱のゲームは見つからない扱いです。
 func (r *repository) GetGameByID(id int) (*Game, error) {
-	query := `SELECT id, user_id, title, platform, genre, status, release_date, created_at, updated_at
-			  FROM games WHERE id = ?`
+	return r.getGameByID(id, false)
+}
 
-	var game Game
-	err := r.db.QueryRow(query, id).Scan(
-		&game.ID,
-		&game.UserID,
-		&game.Title,
-		&game.Platform,
-		&game.Genre,
-		&game.Status,
-		&game.ReleaseDate,
-		&game.CreatedAt,
-		&game.UpdatedAt,
-	)
+// GetGameByIDIncludingDeleted は、ゴミ箱のゲームも含めて ID で1件取得します。
+func (r *repository) GetGameByIDIncludingDeleted(id int) (*Game, error) {
+	return r.getGameByID(id, true)
+}
 
+func (r *repository) getGameByID(id int, includeDeleted bool) (*Game, error) {
+	query := `SELECT ` + gameColumns + `
+			  FROM games WHERE id = ?`
+	if !includeDeleted {
+		query += ` AND deleted_at IS NULL`
+	}
+
+	game, err := scanGame(r.q.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // 見つからなかった（エラーではない）
@@ -88,15 +306,34 @@ func (r *repository) GetGameByID(id int) (*Game, error) {
 		return nil, err
 	}
 
-	return &game, nil
+	tags, err := r.getGameTags(`gt.game_id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	game.Tags = tagsOf(tags, game.ID)
+
+	return game, nil
 }
 
-// GetGamesByUserID は、指定されたユーザーのゲーム一覧を取得します。
+// GetGamesByUserID は、指定されたユーザーのゲーム一覧を取得します。ゴミ箱のゲームは含みません。
 func (r *repository) GetGamesByUserID(userID int) ([]*Game, error) {
-	query := `SELECT id, user_id, title, platform, genre, status, release_date, created_at, updated_at
-			  FROM games WHERE user_id = ? ORDER BY created_at DESC`
+	return r.getGamesByUserID(userID, false)
+}
 
-	rows, err := r.db.Query(query, userID)
+// GetGamesByUserIDIncludingDeleted は、ゴミ箱のゲームも含めてゲーム一覧を取得します。
+func (r *repository) GetGamesByUserIDIncludingDeleted(userID int) ([]*Game, error) {
+	return r.getGamesByUserID(userID, true)
+}
+
+func (r *repository) getGamesByUserID(userID int, includeDeleted bool) ([]*Game, error) {
+	query := `SELECT ` + gameColumns + `
+			  FROM games WHERE user_id = ?`
+	if !includeDeleted {
+		query += ` AND deleted_at IS NULL`
+	}
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := r.q.Query(query, userID)
 	if err != nil {
 		log.Printf("Error querying games by user ID: %v", err)
 		return nil, err
@@ -105,55 +342,943 @@ func (r *repository) GetGamesByUserID(userID int) ([]*Game, error) {
 
 	var games []*Game
 	for rows.Next() {
-		var game Game
-		if err := rows.Scan(
-			&game.ID,
-			&game.UserID,
-			&game.Title,
-			&game.Platform,
-			&game.Genre,
-			&game.Status,
-			&game.ReleaseDate,
-			&game.CreatedAt,
-			&game.UpdatedAt,
-		); err != nil {
+		game, err := scanGame(rows)
+		if err != nil {
 			log.Printf("Error scanning game row: %v", err)
 			continue // 一部の行でエラーがあっても続行
 		}
-		games = append(games, &game)
+		games = append(games, game)
+	}
+	rows.Close()
+
+	tags, err := r.getGameTags(`t.user_id = ?`, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, game := range games {
+		game.Tags = tagsOf(tags, game.ID)
 	}
 
 	return games, nil
 }
 
 // UpdateGame はゲーム情報を更新します。
+// game.Version が DB の版と一致するときだけ更新し（compare-and-swap）、成功したら game.Version を1つ進めます。
+// 読み込んだ後に他のリクエストが更新していた場合は ErrVersionMismatch を返します。
 func (r *repository) UpdateGame(game *Game) error {
-	query := `UPDATE games SET title = ?, platform = ?, genre = ?, status = ?, release_date = ?, updated_at = ?
-			  WHERE id = ?`
+	query := `UPDATE games SET title = ?, platform = ?, genre = ?, status = ?, release_date = ?, estimated_hours = ?,
+			  played_minutes = ?, steam_app_id = ?, completed_at = ?, rating = ?, review = ?, completion_type = ?, updated_at = ?,
+			  cover_key = ?, purchase_price = ?, currency = ?, purchase_date = ?, store = ?, priority = ?, deadline = ?,
+			  version = version + 1
+			  WHERE id = ? AND version = ?`
 
-	_, err := r.db.Exec(query,
+	result, err := r.q.Exec(query,
 		game.Title,
 		game.Platform,
 		game.Genre,
 		game.Status,
 		game.ReleaseDate,
+		game.EstimatedHours,
+		game.PlayedMinutes,
+		game.SteamAppID,
+		game.CompletedAt,
+		game.Rating,
+		game.Review,
+		game.CompletionType,
 		time.Now(), // UpdatedAt
+		game.CoverKey,
+		game.PurchasePrice,
+		game.Currency,
+		game.PurchaseDate,
+		game.Store,
+		game.Priority,
+		game.Deadline,
 		game.ID,
+		game.Version,
 	)
 	
 	if err != nil {
 		log.Printf("Error updating game: %v", err)
+		return err
+	}
+	if err := checkVersionUpdated(result); err != nil {
+		return err
+	}
+	game.Version++
+	return nil
+}
+
+// AddPlayedMinutes は、累計プレイ時間に minutes を足し、版を上げます。
+func (r *repository) AddPlayedMinutes(id int, minutes int) error {
+	query := `UPDATE games SET played_minutes = played_minutes + ?, updated_at = ?, version = version + 1 WHERE id = ?`
+	_, err := r.q.Exec(query, minutes, time.Now(), id)
+	if err != nil {
+		log.Printf("Error adding played minutes: %v", err)
 	}
 	return err
 }
 
+// checkVersionUpdated は、版を条件にした UPDATE で1行も更新されなかった場合に ErrVersionMismatch を返します。
+func checkVersionUpdated(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrVersionMismatch
+	}
+	return nil
+}
+
 // DeleteGame は ID を指定してゲームを削除します。
 func (r *repository) DeleteGame(id int) error {
 	query := `DELETE FROM games WHERE id = ?`
 	
-	_, err := r.db.Exec(query, id)
+	_, err := r.q.Exec(query, id)
 	if err != nil {
 		log.Printf("Error deleting game: %v", err)
 	}
 	return err
-}
\ No newline at end of file
+}
+
+// --- ゴミ箱（論理削除） ---
+
+// SoftDeleteGame はゲームをゴミ箱に移します（deleted_at を設定）。
+// UpdateGame と同じく、version が DB の版と一致するときだけ更新します。
+func (r *repository) SoftDeleteGame(id int, version int) error {
+	query := `UPDATE games SET deleted_at = ?, updated_at = ?, version = version + 1
+			  WHERE id = ? AND version = ? AND deleted_at IS NULL`
+
+	now := time.Now()
+	result, err := r.q.Exec(query, now, now, id, version)
+	if err != nil {
+		log.Printf("Error soft deleting game: %v", err)
+		return err
+	}
+	return checkVersionUpdated(result)
+}
+
+// RestoreGame はゲームをゴミ箱から戻します（deleted_at を NULL に戻す）。
+// SoftDeleteGame と同じく、version が DB の版と一致するときだけ更新します。
+func (r *repository) RestoreGame(id int, version int) error {
+	query := `UPDATE games SET deleted_at = NULL, updated_at = ?, version = version + 1
+			  WHERE id = ? AND version = ? AND deleted_at IS NOT NULL`
+
+	result, err := r.q.Exec(query, time.Now(), id, version)
+	if err != nil {
+		log.Printf("Error restoring game: %v", err)
+		return err
+	}
+	return checkVersionUpdated(result)
+}
+
+// DeleteNotesByGameID は、指定されたゲームのメモをすべて削除します。
+func (r *repository) DeleteNotesByGameID(gameID int) error {
+	query := `DELETE FROM game_notes WHERE game_id = ?`
+
+	_, err := r.q.Exec(query, gameID)
+	if err != nil {
+		log.Printf("Error deleting notes by game ID: %v", err)
+	}
+	return err
+}
+
+// DeleteStatusHistoryByGameID は、指定されたゲームのステータス変更履歴をすべて削除します。
+func (r *repository) DeleteStatusHistoryByGameID(gameID int) error {
+	query := `DELETE FROM game_status_history WHERE game_id = ?`
+
+	_, err := r.q.Exec(query, gameID)
+	if err != nil {
+		log.Printf("Error deleting status history by game ID: %v", err)
+	}
+	return err
+}
+
+// --- 統合（重複の解消） ---
+
+// MoveNotes は、fromGameID のメモをすべて toGameID に付け替えます。
+func (r *repository) MoveNotes(fromGameID int, toGameID int) error {
+	query := `UPDATE game_notes SET game_id = ? WHERE game_id = ?`
+
+	_, err := r.q.Exec(query, toGameID, fromGameID)
+	if err != nil {
+		log.Printf("Error moving notes: %v", err)
+	}
+	return err
+}
+
+// MoveStatusHistory は、fromGameID のステータス変更履歴をすべて toGameID に付け替えます。
+func (r *repository) MoveStatusHistory(fromGameID int, toGameID int) error {
+	query := `UPDATE game_status_history SET game_id = ? WHERE game_id = ?`
+
+	_, err := r.q.Exec(query, toGameID, fromGameID)
+	if err != nil {
+		log.Printf("Error moving status history: %v", err)
+	}
+	return err
+}
+
+// MoveGameTags は、fromGameID のタグを toGameID に付け替えます（両方に付いているタグは1つにまとめます）。
+func (r *repository) MoveGameTags(fromGameID int, toGameID int) error {
+	query := `INSERT OR IGNORE INTO game_tags (game_id, tag_id, created_at)
+			  SELECT ?, tag_id, created_at FROM game_tags WHERE game_id = ?`
+
+	if _, err := r.q.Exec(query, toGameID, fromGameID); err != nil {
+		log.Printf("Error moving game tags: %v", err)
+		return err
+	}
+	return r.DeleteGameTagsByGameID(fromGameID)
+}
+
+// --- メモ・感想 (Note) ---
+
+// CreateNote は新しいメモをDBに作成します。作成したメモのIDを返します。
+func (r *repository) CreateNote(note *Note) (int, error) {
+	query := `INSERT INTO game_notes (game_id, body, created_at, updated_at)
+			  VALUES (?, ?, ?, ?)`
+
+	now := time.Now()
+
+	result, err := r.q.Exec(query, note.GameID, note.Body, now, now)
+	if err != nil {
+		log.Printf("Error creating note: %v", err)
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		log.Printf("Error getting last insert ID: %v", err)
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// GetNoteByID は ID でメモを1件取得します。
+func (r *repository) GetNoteByID(id int) (*Note, error) {
+	query := `SELECT id, game_id, body, created_at, updated_at
+			  FROM game_notes WHERE id = ?`
+
+	var note Note
+	err := r.q.QueryRow(query, id).Scan(
+		&note.ID,
+		&note.GameID,
+		&note.Body,
+		&note.CreatedAt,
+		&note.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // 見つからなかった（エラーではない）
+		}
+		log.Printf("Error scanning note by ID: %v", err)
+		return nil, err
+	}
+
+	return &note, nil
+}
+
+// GetNotesByGameID は、指定されたゲームのメモ一覧を新しい順に取得します。
+func (r *repository) GetNotesByGameID(gameID int) ([]*Note, error) {
+	query := `SELECT id, game_id, body, created_at, updated_at
+			  FROM game_notes WHERE game_id = ? ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.q.Query(query, gameID)
+	if err != nil {
+		log.Printf("Error querying notes by game ID: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []*Note{}
+	for rows.Next() {
+		var note Note
+		if err := rows.Scan(
+			&note.ID,
+			&note.GameID,
+			&note.Body,
+			&note.CreatedAt,
+			&note.UpdatedAt,
+		); err != nil {
+			log.Printf("Error scanning note row: %v", err)
+			return nil, err
+		}
+		notes = append(notes, &note)
+	}
+
+	return notes, rows.Err()
+}
+
+// CountNotesByGameID は、指定されたゲームのメモ件数を返します。
+func (r *repository) CountNotesByGameID(gameID int) (int, error) {
+	query := `SELECT COUNT(*) FROM game_notes WHERE game_id = ?`
+
+	var count int
+	if err := r.q.QueryRow(query, gameID).Scan(&count); err != nil {
+		log.Printf("Error counting notes: %v", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// UpdateNote はメモの本文を更新します。
+func (r *repository) UpdateNote(note *Note) error {
+	query := `UPDATE game_notes SET body = ?, updated_at = ? WHERE id = ?`
+
+	_, err := r.q.Exec(query, note.Body, time.Now(), note.ID)
+	if err != nil {
+		log.Printf("Error updating note: %v", err)
+	}
+	return err
+}
+
+// DeleteNote は ID を指定してメモを削除します。
+func (r *repository) DeleteNote(id int) error {
+	query := `DELETE FROM game_notes WHERE id = ?`
+
+	_, err := r.q.Exec(query, id)
+	if err != nil {
+		log.Printf("Error deleting note: %v", err)
+	}
+	return err
+}
+
+// --- ステータス変更履歴 (StatusHistory) ---
+
+// CreateStatusHistory はステータス変更を1件記録します。ChangedAt が未設定なら現在時刻を使います。
+func (r *repository) CreateStatusHistory(history *StatusHistory) error {
+	query := `INSERT INTO game_status_history (game_id, from_status, to_status, reason, changed_at)
+			  VALUES (?, ?, ?, ?, ?)`
+
+	if history.ChangedAt.IsZero() {
+		history.ChangedAt = time.Now()
+	}
+
+	result, err := r.q.Exec(query, history.GameID, history.FromStatus, history.ToStatus, history.Reason, history.ChangedAt)
+	if err != nil {
+		log.Printf("Error creating status history: %v", err)
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		log.Printf("Error getting last insert ID: %v", err)
+		return err
+	}
+	history.ID = int(id)
+
+	return nil
+}
+
+// GetStatusHistoryByGameID は、指定されたゲームのステータス変更履歴を古い順に取得します。
+func (r *repository) GetStatusHistoryByGameID(gameID int) ([]*StatusHistory, error) {
+	query := `SELECT id, game_id, from_status, to_status, reason, changed_at
+			  FROM game_status_history WHERE game_id = ? ORDER BY changed_at, id`
+	return r.getStatusHistory(query, gameID)
+}
+
+// GetStatusHistoryByUserID は、ユーザーのゲームの変更履歴をすべて、古い順に取得します。
+func (r *repository) GetStatusHistoryByUserID(userID int) ([]*StatusHistory, error) {
+	query := `SELECT h.id, h.game_id, h.from_status, h.to_status, h.reason, h.changed_at
+			  FROM game_status_history h JOIN games g ON g.id = h.game_id
+			  WHERE g.user_id = ? ORDER BY h.changed_at, h.id`
+	return r.getStatusHistory(query, userID)
+}
+
+func (r *repository) getStatusHistory(query string, args ...any) ([]*StatusHistory, error) {
+	rows, err := r.q.Query(query, args...)
+	if err != nil {
+		log.Printf("Error querying status history: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	histories := []*StatusHistory{}
+	for rows.Next() {
+		var h StatusHistory
+		if err := rows.Scan(&h.ID, &h.GameID, &h.FromStatus, &h.ToStatus, &h.Reason, &h.ChangedAt); err != nil {
+			log.Printf("Error scanning status history row: %v", err)
+			return nil, err
+		}
+		histories = append(histories, &h)
+	}
+
+	return histories, rows.Err()
+}
+
+// --- タグ (Tag) ---
+
+// tagColumns は tags テーブルから取得するカラムの一覧です。scanTag と順番を合わせてください。
+const tagColumns = `t.id, t.user_id, t.name, t.color, t.schedule_days, t.created_at, t.updated_at`
+
+// scanTag は tagColumns の順で1行を読み取り、Tag に詰めます。
+// 先頭に追加のカラムがある場合は extra で受け取ります。
+func scanTag(row rowScanner, extra ...any) (*Tag, error) {
+	var tag Tag
+	var days string
+	dest := append(extra, &tag.ID, &tag.UserID, &tag.Name, &tag.Color, &days, &tag.CreatedAt, &tag.UpdatedAt)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	tag.ScheduleDays = []string{}
+	if days != "" {
+		tag.ScheduleDays = strings.Split(days, ",")
+	}
+	return &tag, nil
+}
+
+// CreateTag は新しいタグをDBに作成します。作成したタグのIDを返します。
+func (r *repository) CreateTag(tag *Tag) (int, error) {
+	query := `INSERT INTO tags (user_id, name, color, schedule_days, created_at, updated_at)
+			  VALUES (?, ?, ?, ?, ?, ?)`
+
+	now := time.Now()
+
+	result, err := r.q.Exec(query, tag.UserID, tag.Name, tag.Color, strings.Join(tag.ScheduleDays, ","), now, now)
+	if err != nil {
+		log.Printf("Error creating tag: %v", err)
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		log.Printf("Error getting last insert ID: %v", err)
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// GetTagByID は ID でタグを1件取得します。
+func (r *repository) GetTagByID(id int) (*Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags t WHERE t.id = ?`
+	return r.getTag(query, id)
+}
+
+// GetTagByName は、名前（大文字・小文字は区別しない）でタグを1件取得します。
+func (r *repository) GetTagByName(userID int, name string) (*Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags t WHERE t.user_id = ? AND t.name = ?`
+	return r.getTag(query, userID, name)
+}
+
+func (r *repository) getTag(query string, args ...any) (*Tag, error) {
+	tag, err := scanTag(r.q.QueryRow(query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // 見つからなかった（エラーではない）
+		}
+		log.Printf("Error scanning tag: %v", err)
+		return nil, err
+	}
+	return tag, nil
+}
+
+// GetTagsByUserID は、指定されたユーザーのタグ一覧を名前順に取得します。
+func (r *repository) GetTagsByUserID(userID int) ([]*Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags t WHERE t.user_id = ? ORDER BY t.name`
+
+	rows, err := r.q.Query(query, userID)
+	if err != nil {
+		log.Printf("Error querying tags by user ID: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []*Tag{}
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			log.Printf("Error scanning tag row: %v", err)
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// UpdateTag はタグの名前・色・曜日を更新します。
+func (r *repository) UpdateTag(tag *Tag) error {
+	query := `UPDATE tags SET name = ?, color = ?, schedule_days = ?, updated_at = ? WHERE id = ?`
+
+	_, err := r.q.Exec(query, tag.Name, tag.Color, strings.Join(tag.ScheduleDays, ","), time.Now(), tag.ID)
+	if err != nil {
+		log.Printf("Error updating tag: %v", err)
+	}
+	return err
+}
+
+// DeleteTag は ID を指定してタグを削除します。ゲームへの割り当ては DeleteGameTagsByTagID で消してください。
+func (r *repository) DeleteTag(id int) error {
+	query := `DELETE FROM tags WHERE id = ?`
+
+	_, err := r.q.Exec(query, id)
+	if err != nil {
+		log.Printf("Error deleting tag: %v", err)
+	}
+	return err
+}
+
+// AddGameTag はゲームにタグを付けます。すでに付いている場合は何もしません。
+func (r *repository) AddGameTag(gameID int, tagID int) error {
+	query := `INSERT OR IGNORE INTO game_tags (game_id, tag_id, created_at) VALUES (?, ?, ?)`
+
+	_, err := r.q.Exec(query, gameID, tagID, time.Now())
+	if err != nil {
+		log.Printf("Error adding game tag: %v", err)
+	}
+	return err
+}
+
+// RemoveGameTag はゲームからタグを外します。
+func (r *repository) RemoveGameTag(gameID int, tagID int) error {
+	query := `DELETE FROM game_tags WHERE game_id = ? AND tag_id = ?`
+
+	_, err := r.q.Exec(query, gameID, tagID)
+	if err != nil {
+		log.Printf("Error removing game tag: %v", err)
+	}
+	return err
+}
+
+// DeleteGameTagsByGameID は、指定されたゲームのタグの割り当てをすべて削除します。
+func (r *repository) DeleteGameTagsByGameID(gameID int) error {
+	query := `DELETE FROM game_tags WHERE game_id = ?`
+
+	_, err := r.q.Exec(query, gameID)
+	if err != nil {
+		log.Printf("Error deleting game tags by game ID: %v", err)
+	}
+	return err
+}
+
+// DeleteGameTagsByTagID は、指定されたタグのゲームへの割り当てをすべて削除します。
+func (r *repository) DeleteGameTagsByTagID(tagID int) error {
+	query := `DELETE FROM game_tags WHERE tag_id = ?`
+
+	_, err := r.q.Exec(query, tagID)
+	if err != nil {
+		log.Printf("Error deleting game tags by tag ID: %v", err)
+	}
+	return err
+}
+
+// getGameTags は、条件に合うゲームとタグの組を取得し、ゲームIDごとにまとめて返します。
+func (r *repository) getGameTags(where string, args ...any) (map[int][]*Tag, error) {
+	query := `SELECT gt.game_id, ` + tagColumns + `
+			  FROM game_tags gt JOIN tags t ON t.id = gt.tag_id
+			  WHERE ` + where + ` ORDER BY t.name`
+
+	rows, err := r.q.Query(query, args...)
+	if err != nil {
+		log.Printf("Error querying game tags: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	byGame := map[int][]*Tag{}
+	for rows.Next() {
+		var gameID int
+		tag, err := scanTag(rows, &gameID)
+		if err != nil {
+			log.Printf("Error scanning game tag row: %v", err)
+			return nil, err
+		}
+		byGame[gameID] = append(byGame[gameID], tag)
+	}
+
+	return byGame, rows.Err()
+}
+
+// tagsOf は、getGameTags の結果からゲームのタグを取り出します。タグがなければ空のスライスです。
+func tagsOf(byGame map[int][]*Tag, gameID int) []*Tag {
+	if tags, ok := byGame[gameID]; ok {
+		return tags
+	}
+	return []*Tag{}
+}
+
+// --- プラットフォームのカタログ (Platform) ---
+
+// EnsurePlatforms は、platforms / platform_aliases に足りないものを追加します。
+// 正式名も別名として登録しておくので、GetPlatformByAlias は正式名でも引けます。
+func (r *repository) EnsurePlatforms(platforms []*Platform) error {
+	return r.RunInTx(func(tx *sql.Tx) error {
+		for _, p := range platforms {
+			if _, err := tx.Exec(`INSERT OR IGNORE INTO platforms (name, created_at) VALUES (?, ?)`, p.Name, time.Now()); err != nil {
+				log.Printf("Error inserting platform: %v", err)
+				return err
+			}
+
+			var id int
+			if err := tx.QueryRow(`SELECT id FROM platforms WHERE name = ?`, p.Name).Scan(&id); err != nil {
+				log.Printf("Error getting platform ID: %v", err)
+				return err
+			}
+
+			for _, alias := range append([]string{p.Name}, p.Aliases...) {
+				query := `INSERT OR IGNORE INTO platform_aliases (alias_key, alias, platform_id) VALUES (?, ?, ?)`
+				if _, err := tx.Exec(query, normalizePlatform(alias), alias, id); err != nil {
+					log.Printf("Error inserting platform alias: %v", err)
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// GetPlatforms は、プラットフォームの一覧を登録順に取得します。Aliases に正式名は含みません。
+func (r *repository) GetPlatforms() ([]*Platform, error) {
+	query := `SELECT p.id, p.name, a.alias
+			  FROM platforms p LEFT JOIN platform_aliases a ON a.platform_id = p.id AND a.alias <> p.name
+			  ORDER BY p.id, a.rowid`
+
+	rows, err := r.q.Query(query)
+	if err != nil {
+		log.Printf("Error querying platforms: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	platforms := []*Platform{}
+	var current *Platform
+	for rows.Next() {
+		var id int
+		var name string
+		var alias sql.NullString
+		if err := rows.Scan(&id, &name, &alias); err != nil {
+			log.Printf("Error scanning platform row: %v", err)
+			return nil, err
+		}
+		if current == nil || current.ID != id {
+			current = &Platform{ID: id, Name: name, Aliases: []string{}}
+			platforms = append(platforms, current)
+		}
+		if alias.Valid {
+			current.Aliases = append(current.Aliases, alias.String)
+		}
+	}
+
+	return platforms, rows.Err()
+}
+
+// GetPlatformByAlias は、名前か別名でプラットフォームを1件取得します（Aliases は空のまま返します）。
+func (r *repository) GetPlatformByAlias(alias string) (*Platform, error) {
+	query := `SELECT p.id, p.name
+			  FROM platform_aliases a JOIN platforms p ON p.id = a.platform_id
+			  WHERE a.alias_key = ?`
+
+	var p Platform
+	err := r.q.QueryRow(query, normalizePlatform(alias)).Scan(&p.ID, &p.Name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // 見つからなかった（エラーではない）
+		}
+		log.Printf("Error scanning platform by alias: %v", err)
+		return nil, err
+	}
+	return &p, nil
+}
+
+// --- ゲーム同士の関係 (GameRelation) ---
+
+// relationColumns は game_relations テーブルから取得するカラムの一覧です。scanRelation と順番を合わせてください。
+const relationColumns = `r.id, r.game_id, r.related_game_id, r.type, r.created_at`
+
+func scanRelation(row rowScanner) (*GameRelation, error) {
+	var rel GameRelation
+	if err := row.Scan(&rel.ID, &rel.GameID, &rel.RelatedGameID, &rel.Type, &rel.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+// CreateRelation は新しい関係をDBに作成します。作成した関係のIDを返します。
+func (r *repository) CreateRelation(rel *GameRelation) (int, error) {
+	query := `INSERT INTO game_relations (game_id, related_game_id, type, created_at) VALUES (?, ?, ?, ?)`
+
+	result, err := r.q.Exec(query, rel.GameID, rel.RelatedGameID, rel.Type, time.Now())
+	if err != nil {
+		log.Printf("Error creating game relation: %v", err)
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		log.Printf("Error getting last insert ID: %v", err)
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// GetRelationByID は ID で関係を1件取得します。
+func (r *repository) GetRelationByID(id int) (*GameRelation, error) {
+	query := `SELECT ` + relationColumns + ` FROM game_relations r WHERE r.id = ?`
+
+	rel, err := scanRelation(r.q.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // 見つからなかった（エラーではない）
+		}
+		log.Printf("Error scanning game relation: %v", err)
+		return nil, err
+	}
+	return rel, nil
+}
+
+// GetRelationsByUserID は、指定されたユーザーのゲームの関係を作成順に取得します。
+func (r *repository) GetRelationsByUserID(userID int) ([]*GameRelation, error) {
+	query := `SELECT ` + relationColumns + `
+			  FROM game_relations r JOIN games g ON g.id = r.game_id
+			  WHERE g.user_id = ? ORDER BY r.id`
+
+	rows, err := r.q.Query(query, userID)
+	if err != nil {
+		log.Printf("Error querying game relations: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	rels := []*GameRelation{}
+	for rows.Next() {
+		rel, err := scanRelation(rows)
+		if err != nil {
+			log.Printf("Error scanning game relation row: %v", err)
+			return nil, err
+		}
+		rels = append(rels, rel)
+	}
+
+	return rels, rows.Err()
+}
+
+// DeleteRelation は ID を指定して関係を削除します。
+func (r *repository) DeleteRelation(id int) error {
+	query := `DELETE FROM game_relations WHERE id = ?`
+
+	_, err := r.q.Exec(query, id)
+	if err != nil {
+		log.Printf("Error deleting game relation: %v", err)
+	}
+	return err
+}
+
+// DeleteRelationsByGameID は、指定されたゲームが DLC・続編・本編・前作のどれかになっている関係をすべて削除します。
+func (r *repository) DeleteRelationsByGameID(gameID int) error {
+	query := `DELETE FROM game_relations WHERE game_id = ? OR related_game_id = ?`
+
+	_, err := r.q.Exec(query, gameID, gameID)
+	if err != nil {
+		log.Printf("Error deleting game relations by game ID: %v", err)
+	}
+	return err
+}
+
+// MoveRelations は、fromGameID の関係を toGameID に付け替えます。
+// 付け替えると重複する関係や、自分自身との関係になるもの（from と to の間の関係）は削除します。
+func (r *repository) MoveRelations(fromGameID int, toGameID int) error {
+	queries := []string{
+		`UPDATE OR IGNORE game_relations SET game_id = ? WHERE game_id = ?`,
+		`UPDATE OR IGNORE game_relations SET related_game_id = ? WHERE related_game_id = ?`,
+	}
+	for _, query := range queries {
+		if _, err := r.q.Exec(query, toGameID, fromGameID); err != nil {
+			log.Printf("Error moving game relations: %v", err)
+			return err
+		}
+	}
+
+	query := `DELETE FROM game_relations WHERE game_id = related_game_id`
+	if _, err := r.q.Exec(query); err != nil {
+		log.Printf("Error deleting self relations: %v", err)
+		return err
+	}
+	// UPDATE OR IGNORE で付け替えられなかった（重複した）ものが残っていれば消す
+	return r.DeleteRelationsByGameID(fromGameID)
+}
+
+// --- 購入金額の集計 ---
+
+// 購入金額の集計の単位
+const (
+	SpendingTotal      = "total"
+	SpendingByPlatform = "platform"
+	SpendingByYear     = "year"
+)
+
+// spendingGroupKeys は、集計の単位ごとの GROUP BY の式です。
+var spendingGroupKeys = map[string]string{
+	SpendingTotal:      `''`,
+	SpendingByPlatform: `platform`,
+	SpendingByYear:     `CAST(strftime('%Y', NULLIF(purchase_date, '')) AS INTEGER)`,
+}
+
+// GetSpendingSummaries は、ゴミ箱にないゲームの購入金額を集計します。
+func (r *repository) GetSpendingSummaries(userID int, currency string, groupBy string) ([]*SpendingSummary, error) {
+	key, ok := spendingGroupKeys[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown spending group %q", groupBy)
+	}
+
+	// クリアまでの時間は、実際のプレイ時間があればそれを、なければ推定時間を使う
+	const hours = `CASE WHEN played_minutes > 0 THEN played_minutes / 60.0 ELSE estimated_hours END`
+	// 未開始のゲームの、購入日からの経過日数
+	const backlogDays = `CASE WHEN status = 'unstarted' AND purchase_date <> ''
+			  THEN julianday('now', 'localtime', 'start of day') - julianday(purchase_date) END`
+
+	query := `SELECT ` + key + ` AS group_key,
+			  COUNT(*),
+			  COALESCE(SUM(purchase_price), 0),
+			  COALESCE(SUM(status = 'unstarted'), 0),
+			  COALESCE(SUM(CASE WHEN status = 'unstarted' THEN purchase_price END), 0),
+			  COALESCE(SUM(status = 'completed'), 0),
+			  COALESCE(SUM(CASE WHEN status = 'completed' THEN purchase_price END), 0),
+			  COALESCE(SUM(CASE WHEN status = 'completed' THEN ` + hours + ` END), 0),
+			  AVG(` + backlogDays + `),
+			  MAX(` + backlogDays + `)
+			  FROM games
+			  WHERE user_id = ? AND deleted_at IS NULL AND purchase_price IS NOT NULL AND currency = ?
+			  GROUP BY group_key
+			  ORDER BY group_key`
+
+	rows, err := r.q.Query(query, userID, currency)
+	if err != nil {
+		log.Printf("Error querying spending summaries: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := []*SpendingSummary{}
+	for rows.Next() {
+		var (
+			s          SpendingSummary
+			groupKey   any
+			avgBacklog sql.NullFloat64
+			maxBacklog sql.NullFloat64
+		)
+		if err := rows.Scan(&groupKey, &s.Games, &s.Spent, &s.UnplayedGames, &s.UnplayedAmount,
+			&s.CompletedGames, &s.CompletedAmount, &s.CompletedHours, &avgBacklog, &maxBacklog); err != nil {
+			log.Printf("Error scanning spending summary: %v", err)
+			return nil, err
+		}
+
+		switch groupBy {
+		case SpendingByPlatform:
+			s.Platform, _ = groupKey.(string)
+		case SpendingByYear:
+			if year, ok := groupKey.(int64); ok {
+				y := int(year)
+				s.Year = &y
+			}
+		}
+		if avgBacklog.Valid {
+			s.AverageBacklogDays = &avgBacklog.Float64
+		}
+		if maxBacklog.Valid {
+			days := int(maxBacklog.Float64)
+			s.OldestBacklogDays = &days
+		}
+		summaries = append(summaries, &s)
+	}
+
+	return summaries, rows.Err()
+}
+
+// GetPurchaseCurrencies は、価格の入力に使われている通貨を、ゲームの多い順に返します。
+func (r *repository) GetPurchaseCurrencies(userID int) ([]string, error) {
+	query := `SELECT currency FROM games
+			  WHERE user_id = ? AND deleted_at IS NULL AND purchase_price IS NOT NULL
+			  GROUP BY currency ORDER BY COUNT(*) DESC, currency`
+
+	rows, err := r.q.Query(query, userID)
+	if err != nil {
+		log.Printf("Error querying purchase currencies: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	currencies := []string{}
+	for rows.Next() {
+		var c string
+		if err := rows.Scan(&c); err != nil {
+			return nil, err
+		}
+		currencies = append(currencies, c)
+	}
+	return currencies, rows.Err()
+}
+
+// --- 統計 ---
+
+// gameCountColumns は、CountGamesBy で集計できるカラムです。
+var gameCountColumns = map[string]string{
+	"status":   "status",
+	"platform": "platform",
+	"genre":    "genre",
+}
+
+// CountGamesBy は、期間内に登録したゲームの数を groupBy ごとに数えます（多い順）。
+func (r *repository) CountGamesBy(userID int, groupBy string, from time.Time, to time.Time) ([]*GameCount, error) {
+	column, ok := gameCountColumns[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown game count column %q", groupBy)
+	}
+
+	query := `SELECT COALESCE(` + column + `, ''), COUNT(*) FROM games
+			  WHERE user_id = ? AND deleted_at IS NULL
+			  AND julianday(created_at) >= julianday(?) AND julianday(created_at) < julianday(?)
+			  GROUP BY 1 ORDER BY 2 DESC, 1`
+
+	rows, err := r.q.Query(query, userID, from, to)
+	if err != nil {
+		log.Printf("Error counting games by %s: %v", groupBy, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []*GameCount{}
+	for rows.Next() {
+		var c GameCount
+		if err := rows.Scan(&c.Key, &c.Count); err != nil {
+			log.Printf("Error scanning game count: %v", err)
+			return nil, err
+		}
+		counts = append(counts, &c)
+	}
+	return counts, rows.Err()
+}
+
+// CountCompletedGames は、期間内に登録したゲームの数と、そのうちクリア済みの数を返します。
+func (r *repository) CountCompletedGames(userID int, from time.Time, to time.Time) (int, int, error) {
+	query := `SELECT COUNT(*), COALESCE(SUM(status = 'completed'), 0) FROM games
+			  WHERE user_id = ? AND deleted_at IS NULL
+			  AND julianday(created_at) >= julianday(?) AND julianday(created_at) < julianday(?)`
+
+	var games, completed int
+	if err := r.q.QueryRow(query, userID, from, to).Scan(&games, &completed); err != nil {
+		log.Printf("Error counting completed games: %v", err)
+		return 0, 0, err
+	}
+	return games, completed, nil
+}
+
+// AverageDaysToComplete は、期間内にクリアした（completed_at が期間内の）ゲームの、登録からクリアまでの平均日数を返します。
+func (r *repository) AverageDaysToComplete(userID int, from time.Time, to time.Time) (int, *float64, error) {
+	query := `SELECT COUNT(*), AVG(julianday(completed_at) - julianday(created_at)) FROM games
+			  WHERE user_id = ? AND deleted_at IS NULL AND status = 'completed' AND completed_at IS NOT NULL
+			  AND julianday(completed_at) >= julianday(?) AND julianday(completed_at) < julianday(?)`
+
+	var games int
+	var avg sql.NullFloat64
+	if err := r.q.QueryRow(query, userID, from, to).Scan(&games, &avg); err != nil {
+		log.Printf("Error averaging days to complete: %v", err)
+		return 0, nil, err
+	}
+	if !avg.Valid {
+		return games, nil, nil
+	}
+	return games, &avg.Float64, nil
+}
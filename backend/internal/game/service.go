@@ -1,65 +1,356 @@
 package game
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"TO-DO-IT/internal/mergepatch"
+	"TO-DO-IT/internal/score"
+	"TO-DO-IT/internal/storage"
 )
 
 // testUserID は認証をスキップするための仮のユーザーID
 const testUserID = 1 
 
+// testMotivationUserID は score パッケージ側の仮ユーザーID（calendar/score のハンドラと同じ値）
+const testMotivationUserID = "user_123"
+
+// サービス層が返すエラー。handler はこれらを見てHTTPステータスを決めます。
+var (
+	ErrEmptyNote             = errors.New("note body must not be empty")
+	ErrInvalidRating         = errors.New("rating must be between 1 and 5")
+	ErrInvalidCompletionType = errors.New("completion_type must be one of story_clear, full_clear, dropped")
+	ErrAlreadyCompleted      = errors.New("game is already completed")
+	ErrInvalidStatus         = errors.New("status must be one of unstarted, playing, paused, completed, dropped")
+	ErrInvalidTransition     = errors.New("status transition is not allowed")
+	ErrNotInTrash            = errors.New("game must be moved to the trash before purging")
+	ErrUnsupportedFormat     = errors.New("format must be csv or json")
+	ErrInvalidImportFile     = errors.New("import file could not be read")
+	ErrDuplicateGame         = errors.New("a game with the same title already exists on this platform")
+	ErrMergeSameGame         = errors.New("cannot merge a game into itself")
+	ErrEmptyTagName          = errors.New("tag name must not be empty")
+	ErrInvalidTagColor       = errors.New("tag color must be in #RRGGBB format")
+	ErrInvalidScheduleDay    = errors.New("schedule_days must be sun, mon, tue, wed, thu, fri or sat")
+	ErrDuplicateTag          = errors.New("a tag with the same name already exists")
+	ErrInvalidGenre          = errors.New("unknown genre (see GET /api/catalog/genres)")
+	ErrInvalidPlatform       = errors.New("unknown platform (see GET /api/catalog/platforms)")
+	ErrRequiredField         = errors.New("required field is missing or null")
+	ErrNegativeValue         = errors.New("estimated_hours and played_minutes must not be negative")
+	ErrNegativePrice         = errors.New("purchase_price must not be negative")
+	ErrInvalidCurrency       = errors.New("currency must be a 3-letter ISO 4217 code such as JPY or USD")
+	ErrInvalidPurchaseDate   = errors.New("purchase_date must be a past date in YYYY-MM-DD format")
+	ErrInvalidPriority       = errors.New("priority must be between 0 and 5")
+	ErrInvalidDeadline       = errors.New("deadline must be a date in YYYY-MM-DD format")
+	ErrVersionMismatch       = errors.New("game has been modified by another request")
+	ErrInvalidBulkRequest    = errors.New("invalid bulk request")
+	ErrGameNotFound          = errors.New("game not found")
+	ErrTagNotFound           = errors.New("tag not found")
+	ErrForbidden             = errors.New("game belongs to another user")
+	ErrInvalidCoverImage     = errors.New("cover must be a JPEG, PNG or GIF image")
+	ErrCoverTooLarge         = errors.New("cover image must be 5MB or smaller")
+	ErrInvalidRelationType   = errors.New("relation type must be dlc_of or sequel_of")
+	ErrSelfRelation          = errors.New("a game cannot be related to itself")
+	ErrDuplicateRelation     = errors.New("the relation already exists")
+	ErrAlreadyDLC            = errors.New("game is already a DLC of another game")
+	ErrRelationCycle         = errors.New("the relation would make the play order circular")
+	ErrRelationNotFound      = errors.New("relation not found")
+)
+
+// DuplicateError は、重複登録を拒否したときのエラーです。重複している既存のゲームを持ちます。
+// errors.Is(err, ErrDuplicateGame) で判定できます。
+type DuplicateError struct {
+	Duplicates []*Game
+}
+
+func (e *DuplicateError) Error() string {
+	return ErrDuplicateGame.Error()
+}
+
+func (e *DuplicateError) Unwrap() error {
+	return ErrDuplicateGame
+}
+
+// ImportRowError は、一括インポートの登録中に Row 行目で失敗したことを表します。
+// 登録はまとめてロールバックされるので、このエラーのときはどの行も登録されていません。
+type ImportRowError struct {
+	Row int
+	Err error
+}
+
+func (e *ImportRowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e *ImportRowError) Unwrap() error {
+	return e.Err
+}
+
 // Service は、game のビジネスロジックに関するインターフェースです。
 type Service interface {
 	// 認証がないため、UserIDはサービス内で固定値(1)を使います
 	CreateGame(req *CreateGameRequest) (*Game, error)
 	GetGame(id int) (*Game, error)
-	GetGames() ([]*Game, error) // UserIDを引数に取らず、固定値(1)で検索
-	UpdateGame(id int, req *UpdateGameRequest) (*Game, error)
-	DeleteGame(id int) error
+	// GetGameDetail は、ゲームに最新のプレイ日記を足して返します。見つからない場合は nil を返します。
+	GetGameDetail(id int) (*GameDetail, error)
+	GetGames(q *GameListQuery) ([]*Game, error) // UserIDを引数に取らず、固定値(1)で検索
+	// 更新・削除の version は、クライアントが読み込んだときの版（If-Match）です。
+	// DB の版と違えば ErrVersionMismatch を返します。0 を渡すと版の確認をしません（If-Match: *）。
+	UpdateGame(id int, version int, req *UpdateGameRequest) (*Game, error) // 全体の置き換え（PUT）
+	// PatchGame は JSON Merge Patch で部分的に更新します。存在しないゲームの場合は nil を返します。
+	PatchGame(id int, version int, patch *GamePatch) (*Game, error)
+	DeleteGame(id int, version int) error // ゴミ箱に移す（論理削除）
+
+	// ゴミ箱
+	// 存在しないゲームの場合は nil を返します（エラーではない）
+	// version は更新・削除と同じく If-Match の版です（ゴミ箱のゲームの版は ?include_deleted=true で取得できます）。
+	RestoreGame(id int, version int) (*Game, error)
+	// PurgeGame はゴミ箱のゲームを完全に削除し、メモ・履歴・未実施のスケジュールも消します。
+	PurgeGame(id int, version int) (*Game, error)
+
+	// ジャンル・プラットフォームのカタログ
+	GetGenres() []string
+	GetPlatforms() ([]*Platform, error)
+	// NormalizeCatalog は、req のジャンル・プラットフォームをカタログの正式名にそろえます。
+	// カタログにない値なら ErrInvalidGenre / ErrInvalidPlatform を返します（CreateGame も同じチェックをします）。
+	NormalizeCatalog(req *CreateGameRequest) error
+
+	// 重複チェック・統合
+	// FindDuplicates は、同じゲームらしい既存のゲームを返します（excludeID のゲームは除く）。
+	FindDuplicates(title string, platform string, excludeID int) ([]*Game, error)
+	// GetDuplicateGroups は、登録済みのゲームのうち重複らしいもののまとまりを返します。
+	GetDuplicateGroups() ([]*DuplicateGroup, error)
+	// MergeGames は sourceID のゲームを targetID のゲームに統合します。
+	// どちらかが存在しない場合は nil を返します（エラーではない）。version は targetID のゲームの版です。
+	MergeGames(targetID int, sourceID int, version int) (*Game, error)
+
+	// カバー画像
+	// SetCover は画像を検証してサムネイルを作り、ゲームのカバー画像にします（前の画像は削除します）。
+	// 存在しないゲームの場合は nil を返します（エラーではない）。
+	// version は If-Match の版で、DB の版と違えば ErrVersionMismatch を返します（0 なら確認しない）。
+	SetCover(id int, version int, data []byte) (*Game, error)
+	// DeleteCover はカバー画像を外してファイルを削除します。存在しないゲームの場合は nil を返します。
+	DeleteCover(id int, version int) (*Game, error)
+	// OpenCover は、配信用にカバー画像（またはサムネイル）を開きます。name は URL の末尾のファイル名です。
+	OpenCover(name string) (*storage.Object, error)
+
+	// 購入金額の集計
+	// GetSpending は、currency で価格が入力されたゲームの購入金額を、全体・プラットフォーム別・購入年別に集計します。
+	// currency が空なら、いちばん多く使われている通貨で集計します。
+	GetSpending(currency string) (*SpendingReport, error)
+
+	// ゲーム同士の関係（DLC・シリーズのプレイ順）
+	// GetRelations は、ゲームが DLC・続編・本編・前作のどれかになっている関係を返します。
+	// 存在しないゲームの場合は nil を返します（エラーではない）。
+	GetRelations(gameID int) ([]*GameRelation, error)
+	// AddRelation は、gameID のゲームが req.RelatedGameID のゲームの DLC・続編であることを登録します。
+	// gameID のゲームが存在しない場合は nil を返し、related_game_id のゲームがなければ ErrGameNotFound を返します。
+	AddRelation(gameID int, req *RelationRequest) (*GameRelation, error)
+	// DeleteRelation は関係を削除します。gameID のゲームの関係でなければ ErrRelationNotFound を返します。
+	DeleteRelation(gameID int, relationID int) error
+	// GetGameGraph は、関係のあるゲームをプレイ順に並べたグラフを返します。
+	GetGameGraph() (*GameGraph, error)
+	// BlockedGames は、まだクリアされていない本編・前作があるゲームのIDと、その本編・前作のIDを返します。
+	// calendar はここに含まれるゲームをスケジュールしません。
+	BlockedGames() (map[int][]int, error)
+
+	// 一括操作
+	// BulkUpdate は、複数のゲームへの操作を1つのトランザクションで実行します。
+	// リクエスト自体が不正な場合は ErrInvalidBulkRequest を返し、何も実行しません。
+	BulkUpdate(req *BulkRequest) (*BulkResponse, error)
+
+	// 一括インポート
+	// 問題のある行は作成せずに行ごとに報告します。dryRun のときは検証だけ行います。
+	ImportGames(format string, r io.Reader, dryRun bool) (*ImportReport, error)
+	// ImportRows は、各インポート元で変換済みの行を検証・登録します（ImportGames と /api/import/:source で共通）。
+	// 登録は全体で1つのトランザクションです。途中で失敗したら *ImportRowError を返し、どの行も登録しません。
+	ImportRows(items []*ImportItem, dryRun bool) (*ImportReport, error)
+
+	// メモ・感想
+	// 存在しないゲーム・メモの場合は nil を返します（エラーではない）
+	CreateNote(gameID int, req *NoteRequest) (*CreateNoteResponse, error)
+	GetNotes(gameID int) ([]*Note, error)
+	UpdateNote(gameID int, noteID int, req *NoteRequest) (*Note, error)
+	DeleteNote(gameID int, noteID int) error
+
+	// 完了報告（終了フラグ）
+	// 存在しないゲームの場合は nil を返します（エラーではない）
+	// version が DB の版と違えば ErrVersionMismatch を返します（0 なら確認しない）。
+	CompleteGame(id int, version int, req *CompleteGameRequest) (*CompleteGameResponse, error)
+
+	// ステータス変更履歴
+	// 存在しないゲームの場合は nil を返します（エラーではない）
+	GetStatusHistory(id int) ([]*StatusHistory, error)
+
+	// タグ
+	GetTags() ([]*Tag, error)
+	CreateTag(req *TagRequest) (*Tag, error)
+	// UpdateTag / AssignTag / UnassignTag は、タグ（やゲーム）が存在しない場合に nil を返します（エラーではない）
+	UpdateTag(id int, req *TagRequest) (*Tag, error)
+	DeleteTag(id int) error
+	AssignTag(gameID int, tagID int) (*Game, error)
+	UnassignTag(gameID int, tagID int) (*Game, error)
+
+	// ChangeStatusInTx は、他パッケージ（calendar など）が自分のトランザクション内で
+	// ゲームのステータスを変更するときに使います。存在しないゲームの場合は nil を返します。
+	// version はユーザーの操作（prune の drop など）のときの If-Match の版で、0 なら版を確認しません。
+	ChangeStatusInTx(tx *sql.Tx, id int, version int, to string, reason string) (*Game, bool, error)
+	// AddStatusListener は、ステータス変更を受け取るリスナーを登録します。
+	AddStatusListener(l StatusListener)
+	// AddPurgeListener は、ゲームの完全削除を受け取るリスナーを登録します。
+	AddPurgeListener(l PurgeListener)
+	// AddMergeListener は、ゲームの統合を受け取るリスナーを登録します。
+	AddMergeListener(l MergeListener)
+	// SetJournalProvider は、ゲーム詳細に載せるプレイ日記の取得先を設定します。
+	SetJournalProvider(p JournalProvider)
+}
+
+// StatusListener は、ゲームのステータス変更を他パッケージに通知するためのインターフェースです。
+// tx はステータス変更と同じトランザクションで、エラーを返すと変更全体がロールバックされます。
+// game パッケージから calendar を import すると循環するため、この形で受け取ります。
+type StatusListener interface {
+	OnGameStatusChanged(tx *sql.Tx, game *Game, from string, to string) error
+}
+
+// PurgeListener は、ゲームの完全削除を他パッケージに通知するためのインターフェースです。
+// 削除と同じトランザクションで呼ばれるので、ゲームに紐づくデータの後片付けに使います。
+type PurgeListener interface {
+	OnGamePurged(tx *sql.Tx, game *Game) error
+}
+
+// MergeListener は、ゲームの統合を他パッケージに通知するためのインターフェースです。
+// 統合と同じトランザクションで呼ばれるので、source に紐づくデータを target に付け替えるのに使います。
+type MergeListener interface {
+	OnGamesMerged(tx *sql.Tx, target *Game, source *Game) error
+}
+
+// JournalProvider は、ゲーム詳細に載せるプレイ日記を他パッケージ（task）から取得するためのインターフェースです。
+type JournalProvider interface {
+	// LatestJournalEntries は、ゲームの日記を新しい順に limit 件まで返します。
+	LatestJournalEntries(gameID int, limit int) ([]*JournalEntry, error)
 }
 
+// DetailJournalLimit は、ゲーム詳細に載せるプレイ日記の件数です。
+const DetailJournalLimit = 3
+
 // service は Service インターフェースの具体的な実装です。
 // repository（DB操作）を持ちます。
 type service struct {
-	repo Repository
+	repo      Repository
+	scoreSvc  score.Service    // 完了ボーナスの付与に使う（担当A）
+	listeners []StatusListener // ステータス変更の通知先（calendar など）
+	purgers   []PurgeListener  // 完全削除の通知先（calendar など）
+	mergers   []MergeListener  // 統合の通知先（calendar など）
+	journals  JournalProvider  // プレイ日記の取得先（task）。未設定なら日記は空
+	covers    storage.Storage  // カバー画像の保存先
 }
 
 // NewService は、新しい service インスタンスを作成します。
 // handler が repository を渡して呼び出します。
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, scoreSvc score.Service, covers storage.Storage) Service {
+	return &service{repo: repo, scoreSvc: scoreSvc, covers: covers}
 }
 
 // --- インターフェースの実装 ---
 
 // CreateGame は新しいゲームを作成します。
 func (s *service) CreateGame(req *CreateGameRequest) (*Game, error) {
+	createdGame, err := s.createGame(s.repo, req)
+	if err != nil {
+		log.Printf("Service: Error creating game: %v", err)
+		return nil, err
+	}
+	return createdGame, nil
+}
+
+// createGame は repo を使ってゲームを作成します。
+// トランザクション上のリポジトリを渡すと、そのトランザクションの中で作成します（一括インポート用）。
+func (s *service) createGame(repo Repository, req *CreateGameRequest) (*Game, error) {
 	// リクエスト(Request)からDBモデル(Game)へ変換
 	status := req.Status
 	if status == "" {
-		status = "unstarted" // デフォルト値
+		status = StatusUnstarted // デフォルト値
+	}
+	if !IsValidStatus(status) {
+		return nil, ErrInvalidStatus
+	}
+	if err := s.NormalizeCatalog(req); err != nil {
+		return nil, err
 	}
+	purchase := &Game{PurchasePrice: req.PurchasePrice, Currency: req.Currency, PurchaseDate: req.PurchaseDate, Store: req.Store}
+	if err := normalizePurchase(purchase); err != nil {
+		return nil, err
+	}
+	plan := &Game{Priority: req.Priority, Deadline: req.Deadline}
+	if err := normalizePlan(plan); err != nil {
+		return nil, err
+	}
+
+	// 手動登録・Steam・CSV などから同じゲームが二重に登録されないようにする
+	if !req.AllowDuplicate {
+		duplicates, err := findDuplicates(repo, req.Title, req.Platform, 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(duplicates) > 0 {
+			return nil, &DuplicateError{Duplicates: duplicates}
+		}
+	}
+
 	game := &Game{
-		UserID:      testUserID, // ★認証の代わりに固定IDを設定
-		Title:       req.Title,
-		Platform:    req.Platform,
-		Genre:       req.Genre,
-		Status:      status,
-		ReleaseDate: req.ReleaseDate,
+		UserID:         testUserID, // ★認証の代わりに固定IDを設定
+		Title:          req.Title,
+		Platform:       req.Platform,
+		Genre:          req.Genre,
+		Status:         status,
+		ReleaseDate:    req.ReleaseDate,
+		EstimatedHours: req.EstimatedHours,
+		PlayedMinutes:  req.PlayedMinutes,
+		SteamAppID:     req.SteamAppID,
+		PurchasePrice:  purchase.PurchasePrice,
+		Currency:       purchase.Currency,
+		PurchaseDate:   purchase.PurchaseDate,
+		Store:          purchase.Store,
+		Priority:       plan.Priority,
+		Deadline:       plan.Deadline,
 		// CreatedAt/UpdatedAt は repository 層のSQLで設定
 	}
-
-	// リポジトリを呼び出してDBに保存
-	id, err := s.repo.CreateGame(game)
-	if err != nil {
-		log.Printf("Service: Error creating game: %v", err)
-		return nil, err
+	if status == StatusCompleted {
+		// 完了の状態で登録するゲーム（インポートなど）も、登録した時点で完了したとみなす
+		now := time.Now()
+		game.CompletedAt = &now
 	}
 
-	// DBに保存された最新の情報を取得して返す（IDなどが確定するため）
-	// ※CreateGame が Game オブジェクトを丸ごと返せば不要だが、今回はIDのみ返すと仮定
-	createdGame, err := s.repo.GetGameByID(id)
+	// リポジトリを呼び出してDBに保存（作成時のステータスも履歴に残す）
+	var createdGame *Game
+	err := repo.RunInTx(func(tx *sql.Tx) error {
+		repo := repo.WithTx(tx)
+
+		id, err := repo.CreateGame(game)
+		if err != nil {
+			return err
+		}
+
+		// DBに保存された最新の情報を取得して返す（IDなどが確定するため）
+		// ※CreateGame が Game オブジェクトを丸ごと返せば不要だが、今回はIDのみ返すと仮定
+		createdGame, err = repo.GetGameByID(id)
+		if err != nil {
+			return err
+		}
+
+		return repo.CreateStatusHistory(&StatusHistory{
+			GameID:    id,
+			ToStatus:  createdGame.Status,
+			Reason:    "created",
+			ChangedAt: createdGame.CreatedAt,
+		})
+	})
 	if err != nil {
-		log.Printf("Service: Error fetching created game: %v", err)
 		return nil, err
 	}
 	
@@ -86,65 +377,223 @@ func (s *service) GetGame(id int) (*Game, error) {
 	return game, nil
 }
 
+// GetGameDetail は、ゲームと最新のプレイ日記をまとめて取得します。
+func (s *service) GetGameDetail(id int) (*GameDetail, error) {
+	game, err := s.GetGame(id)
+	if err != nil || game == nil {
+		return nil, err
+	}
+
+	detail := &GameDetail{Game: game, LatestJournal: []*JournalEntry{}}
+	if s.journals != nil {
+		entries, err := s.journals.LatestJournalEntries(id, DetailJournalLimit)
+		if err != nil {
+			log.Printf("Service: Error getting journal entries: %v", err)
+			return nil, err
+		}
+		detail.LatestJournal = entries
+	}
+	return detail, nil
+}
+
 // GetGames は（テストユーザーの）ゲーム一覧を取得します。
-func (s *service) GetGames() ([]*Game, error) {
+func (s *service) GetGames(q *GameListQuery) ([]*Game, error) {
 	// 認証の代わりに固定IDで検索
-	games, err := s.repo.GetGamesByUserID(testUserID)
+	var games []*Game
+	var err error
+	if q.IncludeDeleted {
+		games, err = s.repo.GetGamesByUserIDIncludingDeleted(testUserID)
+	} else {
+		games, err = s.repo.GetGamesByUserID(testUserID)
+	}
 	if err != nil {
 		log.Printf("Service: Error getting games by UserID: %v", err)
 		return nil, err
 	}
-	return games, nil
-}
+	if len(q.Tags) == 0 {
+		return games, nil
+	}
 
-// UpdateGame はゲーム情報を更新します。
-func (s *service) UpdateGame(id int, req *UpdateGameRequest) (*Game, error) {
-	// 1. まず対象のゲームが存在するか確認
-	game, err := s.repo.GetGameByID(id)
-	if err != nil {
-		return nil, err
+	filtered := []*Game{}
+	for _, g := range games {
+		if hasAllTags(g, q.Tags) {
+			filtered = append(filtered, g)
+		}
 	}
-	if game == nil {
-		return nil, nil // 見つからない
+	return filtered, nil
+}
+
+// hasAllTags は、ゲームに names のタグがすべて付いているかを返します（大文字・小文字は区別しない）。
+func hasAllTags(g *Game, names []string) bool {
+	for _, name := range names {
+		found := false
+		for _, t := range g.Tags {
+			if strings.EqualFold(t.Name, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
 	}
+	return true
+}
 
-	// TODO: 本来はここで「取得した game.UserID」と「認証ユーザーID」が一致するかチェックする
-	// if game.UserID != testUserID {
-	// 	return nil, errors.New("forbidden") // 他人のゲーム
-	// }
+// UpdateGame はゲーム情報を置き換えます（PUT）。
+// 省略した項目は未設定に戻ります。title と status は必須です。
+// 実際の更新は、すべての項目を指定したパッチとして PatchGame で行います。
+func (s *service) UpdateGame(id int, version int, req *UpdateGameRequest) (*Game, error) {
+	if strings.TrimSpace(req.Title) == "" {
+		return nil, fmt.Errorf("%w: title", ErrRequiredField)
+	}
+	if req.Status == "" {
+		return nil, fmt.Errorf("%w: status", ErrRequiredField)
+	}
 
-	// 2. リクエスト(req)の内容で、取得した game オブジェクトを更新
-	// ※リクエストで値が省略された場合（例：Title=""）にどうするかは要件次第
-	// ここでは単純に上書きする
-	if req.Title != "" {
-		game.Title = req.Title
+	// 価格は null（未入力）にもできるので、PUT で省略されたら消す
+	price := mergepatch.Field[int]{Set: true, Null: true}
+	if req.PurchasePrice != nil {
+		price = mergepatch.Value(*req.PurchasePrice)
 	}
-	if req.Platform != "" {
-		game.Platform = req.Platform
+
+	return s.PatchGame(id, version, &GamePatch{
+		Title:          mergepatch.Value(req.Title),
+		Platform:       mergepatch.Value(req.Platform),
+		Genre:          mergepatch.Value(req.Genre),
+		Status:         mergepatch.Value(req.Status),
+		ReleaseDate:    mergepatch.Value(req.ReleaseDate),
+		EstimatedHours: mergepatch.Value(req.EstimatedHours),
+		PlayedMinutes:  mergepatch.Value(req.PlayedMinutes),
+		PurchasePrice:  price,
+		Currency:       mergepatch.Value(req.Currency),
+		PurchaseDate:   mergepatch.Value(req.PurchaseDate),
+		Store:          mergepatch.Value(req.Store),
+		Priority:       mergepatch.Value(req.Priority),
+		Deadline:       mergepatch.Value(req.Deadline),
+		StatusReason:   req.StatusReason,
+	})
+}
+
+// PatchGame はゲーム情報を部分的に更新します（PATCH, JSON Merge Patch）。
+// パッチに含まれない項目はそのまま、null の項目は未設定に戻します。
+// ステータスを変更する場合は、許可された遷移かを確認して履歴に記録します。
+func (s *service) PatchGame(id int, version int, patch *GamePatch) (*Game, error) {
+	// 1. DBに触る前に、パッチの中身だけで分かるチェックをする
+	if patch.Title.Set && (patch.Title.Null || strings.TrimSpace(patch.Title.Value) == "") {
+		return nil, fmt.Errorf("%w: title", ErrRequiredField)
 	}
-	if req.Genre != "" {
-		game.Genre = req.Genre
+	if patch.Status.Set {
+		if patch.Status.Null {
+			return nil, fmt.Errorf("%w: status", ErrRequiredField)
+		}
+		if !IsValidStatus(patch.Status.Value) {
+			return nil, ErrInvalidStatus
+		}
 	}
-	if req.Status != "" {
-		game.Status = req.Status
+	if patch.EstimatedHours.Value < 0 || patch.PlayedMinutes.Value < 0 {
+		return nil, ErrNegativeValue
 	}
-	if !req.ReleaseDate.IsZero() {
-		game.ReleaseDate = req.ReleaseDate
+	platform, genre, err := s.normalizeCatalog(patch.Platform.Value, patch.Genre.Value)
+	if err != nil {
+		return nil, err
 	}
-	// UpdatedAt は repository 層で更新
 
-	// 3. DBを更新
-	err = s.repo.UpdateGame(game)
+	var game *Game
+	err = s.repo.RunInTx(func(tx *sql.Tx) error {
+		repo := s.repo.WithTx(tx)
+
+		// 2. まず対象のゲームが存在するか確認
+		var err error
+		game, err = repo.GetGameByID(id)
+		if err != nil || game == nil {
+			return err // game == nil なら見つからない
+		}
+		// クライアントが読み込んだ後に他で更新されていたら、上書きせずにエラーにする
+		if version != 0 && game.Version != version {
+			return ErrVersionMismatch
+		}
+
+		// TODO: 本来はここで「取得した game.UserID」と「認証ユーザーID」が一致するかチェックする
+		// if game.UserID != testUserID {
+		// 	return errors.New("forbidden") // 他人のゲーム
+		// }
+
+		// 3. パッチに含まれる項目だけ反映する（null なら Value はゼロ値なので、そのまま代入すれば消える）
+		if patch.Title.Set {
+			game.Title = strings.TrimSpace(patch.Title.Value)
+		}
+		if patch.Platform.Set {
+			game.Platform = platform
+		}
+		if patch.Genre.Set {
+			game.Genre = genre
+		}
+		if patch.ReleaseDate.Set {
+			game.ReleaseDate = patch.ReleaseDate.Value
+		}
+		if patch.EstimatedHours.Set {
+			game.EstimatedHours = patch.EstimatedHours.Value
+		}
+		if patch.PlayedMinutes.Set {
+			game.PlayedMinutes = patch.PlayedMinutes.Value
+		}
+		if patch.PurchasePrice.Set {
+			game.PurchasePrice = nil
+			if !patch.PurchasePrice.Null {
+				price := patch.PurchasePrice.Value
+				game.PurchasePrice = &price
+			}
+		}
+		if patch.Currency.Set {
+			game.Currency = patch.Currency.Value
+		}
+		if patch.PurchaseDate.Set {
+			game.PurchaseDate = patch.PurchaseDate.Value
+		}
+		if patch.Store.Set {
+			game.Store = patch.Store.Value
+		}
+		// 価格と通貨の組み合わせは、反映した後の値で確認する
+		if err := normalizePurchase(game); err != nil {
+			return err
+		}
+		if patch.Priority.Set {
+			game.Priority = patch.Priority.Value
+		}
+		if patch.Deadline.Set {
+			game.Deadline = patch.Deadline.Value
+		}
+		if err := normalizePlan(game); err != nil {
+			return err
+		}
+		if patch.Status.Set {
+			reason := patch.StatusReason
+			if reason == "" {
+				reason = "manual update"
+			}
+			if _, err := s.changeStatus(tx, game, patch.Status.Value, reason); err != nil {
+				return err
+			}
+		}
+		// UpdatedAt は repository 層で更新
+
+		// 4. DBを更新
+		return repo.UpdateGame(game)
+	})
 	if err != nil {
 		log.Printf("Service: Error updating game: %v", err)
 		return nil, err
 	}
-	
-	return game, nil
+	if game == nil {
+		return nil, nil
+	}
+
+	return s.repo.GetGameByID(id)
 }
 
 // DeleteGame は ID を指定してゲームを削除します。
-func (s *service) DeleteGame(id int) error {
+func (s *service) DeleteGame(id int, version int) error {
 	// 1. まず対象のゲームが存在するか確認（しなくてもDBエラーにはなるが、権限チェックのため）
 	game, err := s.repo.GetGameByID(id)
 	if err != nil {
@@ -159,6 +608,1331 @@ func (s *service) DeleteGame(id int) error {
 	// 	return errors.New("forbidden") // 他人のゲーム
 	// }
 
-	// 2. 削除実行
-	return s.repo.DeleteGame(id)
-}
\ No newline at end of file
+	if version == 0 {
+		version = game.Version // If-Match: * のときは、読み込んだ版で削除する
+	}
+
+	// 2. ゴミ箱に移す（スケジュールやメモは復元できるよう残しておく）
+	// 版が変わっていれば（他で更新・削除されていれば）ErrVersionMismatch
+	return s.repo.SoftDeleteGame(id, version)
+}
+
+// RestoreGame はゲームをゴミ箱から戻します。ゴミ箱に入っていないゲームはそのまま返します。
+func (s *service) RestoreGame(id int, version int) (*Game, error) {
+	game, err := s.repo.GetGameByIDIncludingDeleted(id)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil || game.DeletedAt == nil {
+		return game, nil // 見つからない、またはゴミ箱に入っていない
+	}
+
+	if version == 0 {
+		version = game.Version // If-Match: * のときは、読み込んだ版で戻す
+	}
+	if err := s.repo.RestoreGame(id, version); err != nil {
+		log.Printf("Service: Error restoring game: %v", err)
+		return nil, err
+	}
+	return s.repo.GetGameByID(id)
+}
+
+// PurgeGame はゴミ箱のゲームを完全に削除します。
+// メモ・ステータス履歴を消し、PurgeListener（calendar）が未実施のスケジュールを消します。
+// 完了・スキップ済みのスケジュールはプレイ実績として残します。
+func (s *service) PurgeGame(id int, version int) (*Game, error) {
+	var game *Game
+	err := s.repo.RunInTx(func(tx *sql.Tx) error {
+		repo := s.repo.WithTx(tx)
+
+		var err error
+		game, err = repo.GetGameByIDIncludingDeleted(id)
+		if err != nil || game == nil {
+			return err
+		}
+		if game.DeletedAt == nil {
+			return ErrNotInTrash
+		}
+		if version != 0 && game.Version != version {
+			return ErrVersionMismatch
+		}
+
+		for _, l := range s.purgers {
+			if err := l.OnGamePurged(tx, game); err != nil {
+				return err
+			}
+		}
+		if err := repo.DeleteNotesByGameID(id); err != nil {
+			return err
+		}
+		if err := repo.DeleteStatusHistoryByGameID(id); err != nil {
+			return err
+		}
+		if err := repo.DeleteGameTagsByGameID(id); err != nil {
+			return err
+		}
+		if err := repo.DeleteRelationsByGameID(id); err != nil {
+			return err
+		}
+		return repo.DeleteGame(id)
+	})
+	if err != nil {
+		log.Printf("Service: Error purging game: %v", err)
+		return nil, err
+	}
+	// ファイルは DB の削除が確定してから消す（ロールバックされたときに画像だけ消えないように）
+	if game != nil {
+		s.removeCoverFiles(game.CoverKey)
+	}
+
+	return game, nil
+}
+
+// --- 一括インポート ---
+
+// ImportGames は CSV / JSON のファイルからゲームをまとめて登録します。
+func (s *service) ImportGames(format string, r io.Reader, dryRun bool) (*ImportReport, error) {
+	rows, err := decodeImportRows(format, r)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*ImportItem, len(rows))
+	for i, row := range rows {
+		req, problems := rowToCreateRequest(row)
+		items[i] = &ImportItem{Request: req, Problems: problems}
+	}
+	return s.ImportRows(items, dryRun)
+}
+
+// ImportRows は、変換済みの行を検証し、dryRun でなければ問題のない行を登録します。
+// 登録には CreateGame と同じ処理を使うので、ステータスの初期値や履歴の記録は手動登録と同じです。
+// 登録は1つのトランザクションで行い、途中の行で失敗したらどの行も登録しません（*ImportRowError を返します）。
+func (s *service) ImportRows(items []*ImportItem, dryRun bool) (*ImportReport, error) {
+	report := &ImportReport{
+		DryRun: dryRun,
+		Total:  len(items),
+		Rows:   make([]ImportRowResult, 0, len(items)),
+	}
+
+	// 1. すべての行を検証する（ここではまだ登録しない）
+	dups := NewDuplicateChecker(s)
+	for i, item := range items {
+		req, problems := item.Request, append([]string{}, item.Problems...)
+		result := ImportRowResult{Row: i + 1, Title: req.Title}
+
+		// ジャンル・プラットフォームをカタログの正式名にそろえる（「PS5」→「PlayStation 5」など）
+		if item.Skip == "" && len(problems) == 0 {
+			if err := s.NormalizeCatalog(req); err != nil {
+				if !errors.Is(err, ErrInvalidGenre) && !errors.Is(err, ErrInvalidPlatform) {
+					return nil, err
+				}
+				problems = append(problems, err.Error())
+			}
+		}
+
+		var duplicate string
+		if item.Skip == "" && len(problems) == 0 {
+			var err error
+			duplicate, err = dups.Check(req)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		switch {
+		case item.Skip != "":
+			result.Result = "skipped"
+			result.Reason = item.Skip
+			report.Skipped++
+		case len(problems) > 0:
+			result.Result = "invalid"
+			result.Errors = problems
+			report.Invalid++
+		case duplicate != "":
+			result.Result = "skipped"
+			result.Reason = duplicate
+			report.Skipped++
+		default:
+			result.Result = "valid"
+			report.Valid++
+			dups.Add(result.Row, req)
+		}
+		report.Rows = append(report.Rows, result)
+	}
+
+	if dryRun || report.Valid == 0 {
+		return report, nil
+	}
+
+	// 2. 問題のない行をまとめて登録する
+	err := s.repo.RunInTx(func(tx *sql.Tx) error {
+		repo := s.repo.WithTx(tx)
+		for i := range report.Rows {
+			result := &report.Rows[i]
+			if result.Result != "valid" {
+				continue
+			}
+			game, err := s.createGame(repo, items[i].Request)
+			if err != nil {
+				return &ImportRowError{Row: result.Row, Err: err}
+			}
+			result.Result = "created"
+			result.Game = game
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Service: Error importing games: %v", err)
+		return nil, err
+	}
+	report.Created = report.Valid
+
+	return report, nil
+}
+
+// --- メモ・感想 (Note) ---
+
+// CreateNote はゲームにメモを追加します。
+// 「未開始」のゲームに最初のメモが追加された場合は、同じトランザクション内で
+// ステータスを「プレイ中」に変更します。
+func (s *service) CreateNote(gameID int, req *NoteRequest) (*CreateNoteResponse, error) {
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil, ErrEmptyNote
+	}
+
+	var res *CreateNoteResponse
+	err := s.repo.RunInTx(func(tx *sql.Tx) error {
+		repo := s.repo.WithTx(tx)
+
+		game, err := repo.GetGameByID(gameID)
+		if err != nil || game == nil {
+			return err
+		}
+
+		count, err := repo.CountNotesByGameID(gameID)
+		if err != nil {
+			return err
+		}
+
+		noteID, err := repo.CreateNote(&Note{GameID: gameID, Body: body})
+		if err != nil {
+			return err
+		}
+		note, err := repo.GetNoteByID(noteID)
+		if err != nil {
+			return err
+		}
+
+		res = &CreateNoteResponse{
+			Note:           note,
+			PreviousStatus: game.Status,
+			Status:         game.Status,
+		}
+
+		// 最初のメモ（感想）が入ったら「未開始」→「プレイ中」
+		if count == 0 && game.Status == StatusUnstarted {
+			if _, err := s.changeStatus(tx, game, StatusPlaying, "first note"); err != nil {
+				return err
+			}
+			if err := repo.UpdateGame(game); err != nil {
+				return err
+			}
+			res.StatusChanged = true
+			res.Status = game.Status
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Service: Error creating note: %v", err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetNotes はゲームのメモ一覧を取得します。
+func (s *service) GetNotes(gameID int) ([]*Note, error) {
+	game, err := s.repo.GetGameByID(gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil {
+		return nil, nil // 見つからない
+	}
+
+	notes, err := s.repo.GetNotesByGameID(gameID)
+	if err != nil {
+		log.Printf("Service: Error getting notes: %v", err)
+		return nil, err
+	}
+	return notes, nil
+}
+
+// UpdateNote はメモの本文を更新します。
+func (s *service) UpdateNote(gameID int, noteID int, req *NoteRequest) (*Note, error) {
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil, ErrEmptyNote
+	}
+
+	note, err := s.repo.GetNoteByID(noteID)
+	if err != nil {
+		return nil, err
+	}
+	if note == nil || note.GameID != gameID {
+		return nil, nil // 見つからない（別のゲームのメモも含む）
+	}
+
+	note.Body = body
+	if err := s.repo.UpdateNote(note); err != nil {
+		log.Printf("Service: Error updating note: %v", err)
+		return nil, err
+	}
+
+	return s.repo.GetNoteByID(noteID)
+}
+
+// DeleteNote はメモを削除します。メモを消してもゲームのステータスは戻しません。
+func (s *service) DeleteNote(gameID int, noteID int) error {
+	note, err := s.repo.GetNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+	if note == nil || note.GameID != gameID {
+		return nil // 削除対象が見つからない（エラーではない）
+	}
+
+	return s.repo.DeleteNote(noteID)
+}
+
+// --- 完了報告 (終了フラグ) ---
+
+// CompleteGame はゲームの完了報告を記録し、score パッケージ経由で完了ボーナスを付与します。
+// ゲームの更新とポイント加算は同じトランザクションで行います。
+// 途中でやめた（dropped）場合はステータスを dropped にし、ボーナスは付与しません。
+func (s *service) CompleteGame(id int, version int, req *CompleteGameRequest) (*CompleteGameResponse, error) {
+	if req.Rating < 1 || req.Rating > 5 {
+		return nil, ErrInvalidRating
+	}
+	switch req.CompletionType {
+	case CompletionStoryClear, CompletionFullClear, CompletionDropped:
+	default:
+		return nil, ErrInvalidCompletionType
+	}
+
+	var res *CompleteGameResponse
+	err := s.repo.RunInTx(func(tx *sql.Tx) error {
+		repo := s.repo.WithTx(tx)
+
+		game, err := repo.GetGameByID(id)
+		if err != nil || game == nil {
+			return err
+		}
+		if version != 0 && game.Version != version {
+			return ErrVersionMismatch
+		}
+		// 完了報告が済んでいなければ、PATCH やインポートで完了にしたゲームにも報告できる
+		if game.Status == StatusCompleted && game.CompletionType != "" {
+			return ErrAlreadyCompleted
+		}
+
+		status := StatusCompleted
+		if req.CompletionType == CompletionDropped {
+			status = StatusDropped
+		}
+		changed, err := s.changeStatus(tx, game, status, "completion report: "+req.CompletionType)
+		if err != nil {
+			return err
+		}
+		// 省略時は現在時刻。すでに完了にしていたゲームは、完了にした日時のままにする
+		switch {
+		case req.CompletedAt != nil && !req.CompletedAt.IsZero():
+			game.CompletedAt = req.CompletedAt
+		case changed || game.CompletedAt == nil:
+			now := time.Now()
+			game.CompletedAt = &now
+		}
+		game.Rating = req.Rating
+		game.Review = strings.TrimSpace(req.Review)
+		game.CompletionType = req.CompletionType
+
+		if err := repo.UpdateGame(game); err != nil {
+			return err
+		}
+
+		res = &CompleteGameResponse{Game: game}
+		if req.CompletionType == CompletionDropped {
+			return nil
+		}
+
+		motivation, bonus, err := s.scoreSvc.WithTx(tx).ReportCompletion(testMotivationUserID, score.CompletionReport{
+			GameID:         game.ID,
+			EstimatedHours: game.EstimatedHours,
+			FullClear:      req.CompletionType == CompletionFullClear,
+		})
+		if err != nil {
+			return err
+		}
+		res.Bonus = bonus
+		res.Motivation = motivation
+		return nil
+	})
+	if err != nil {
+		log.Printf("Service: Error completing game: %v", err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// --- ステータス変更履歴 ---
+
+// changeStatus は game のステータスを to に変更し、履歴を記録してリスナーに通知します。
+// 同じステータスへの変更は何もせず false を返します。game 自体の保存は呼び出し側で行ってください。
+// tx は呼び出し側のトランザクションで、履歴の記録とリスナーの処理も同じトランザクションで行います。
+func (s *service) changeStatus(tx *sql.Tx, game *Game, to string, reason string) (bool, error) {
+	if game.Status == to {
+		return false, nil
+	}
+	if !CanTransition(game.Status, to) {
+		return false, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, game.Status, to)
+	}
+	if to == StatusCompleted {
+		game.CompletedAt = nil // クリアし直したときは、新しい完了日時にする
+	}
+	return true, s.applyStatus(tx, game, to, reason)
+}
+
+// applyStatus は遷移のチェックをせずにステータスを変更し、履歴の記録とリスナーへの通知を行います。
+// 統合のようにデータを直す操作からだけ使い、通常は changeStatus を使ってください。
+func (s *service) applyStatus(tx *sql.Tx, game *Game, to string, reason string) error {
+	from := game.Status
+	history := &StatusHistory{
+		GameID:     game.ID,
+		FromStatus: from,
+		ToStatus:   to,
+		Reason:     reason,
+	}
+	if err := s.repo.WithTx(tx).CreateStatusHistory(history); err != nil {
+		return err
+	}
+
+	game.Status = to
+	// 完了報告 (/complete) を経ずに完了にしたときも、完了日時を残す（統合では引き継いだ日時を優先）
+	if to == StatusCompleted && game.CompletedAt == nil {
+		changedAt := history.ChangedAt
+		game.CompletedAt = &changedAt
+	}
+	for _, l := range s.listeners {
+		if err := l.OnGameStatusChanged(tx, game, from, to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ChangeStatusInTx は、他パッケージのトランザクション内からゲームのステータスを変更します。
+// 遷移チェック・履歴の記録・リスナーへの通知は UpdateGame と同じです。
+func (s *service) ChangeStatusInTx(tx *sql.Tx, id int, version int, to string, reason string) (*Game, bool, error) {
+	repo := s.repo.WithTx(tx)
+
+	game, err := repo.GetGameByID(id)
+	if err != nil || game == nil {
+		return nil, false, err
+	}
+	if version != 0 && game.Version != version {
+		return nil, false, ErrVersionMismatch
+	}
+
+	changed, err := s.changeStatus(tx, game, to, reason)
+	if err != nil || !changed {
+		return game, false, err
+	}
+	if err := repo.UpdateGame(game); err != nil {
+		return nil, false, err
+	}
+	return game, true, nil
+}
+
+// AddStatusListener は、ステータス変更を受け取るリスナーを登録します。
+func (s *service) AddStatusListener(l StatusListener) {
+	s.listeners = append(s.listeners, l)
+}
+
+// AddPurgeListener は、ゲームの完全削除を受け取るリスナーを登録します。
+func (s *service) AddPurgeListener(l PurgeListener) {
+	s.purgers = append(s.purgers, l)
+}
+
+// AddMergeListener は、ゲームの統合を受け取るリスナーを登録します。
+func (s *service) AddMergeListener(l MergeListener) {
+	s.mergers = append(s.mergers, l)
+}
+
+// SetJournalProvider は、ゲーム詳細に載せるプレイ日記の取得先を設定します。
+func (s *service) SetJournalProvider(p JournalProvider) {
+	s.journals = p
+}
+
+// GetStatusHistory はゲームのステータス変更履歴を古い順に取得します。
+func (s *service) GetStatusHistory(id int) ([]*StatusHistory, error) {
+	game, err := s.repo.GetGameByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil {
+		return nil, nil // 見つからない
+	}
+
+	histories, err := s.repo.GetStatusHistoryByGameID(id)
+	if err != nil {
+		log.Printf("Service: Error getting status history: %v", err)
+		return nil, err
+	}
+	return histories, nil
+}
+
+// --- 重複チェック・統合 ---
+
+// FindDuplicates は、同じゲームらしい既存のゲーム（ゴミ箱を除く）を返します。
+func (s *service) FindDuplicates(title string, platform string, excludeID int) ([]*Game, error) {
+	return findDuplicates(s.repo, title, platform, excludeID)
+}
+
+// findDuplicates は、repo から読んだゲームのうち重複らしいものを返します。
+func findDuplicates(repo Repository, title string, platform string, excludeID int) ([]*Game, error) {
+	games, err := repo.GetGamesByUserID(testUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var duplicates []*Game
+	for _, g := range games {
+		if g.ID != excludeID && IsLikelyDuplicate(title, platform, g.Title, g.Platform) {
+			duplicates = append(duplicates, g)
+		}
+	}
+	return duplicates, nil
+}
+
+// GetDuplicateGroups は、正規化したタイトルが同じゲームのうち、重複とみなせる組を含むまとまりを返します。
+func (s *service) GetDuplicateGroups() ([]*DuplicateGroup, error) {
+	games, err := s.repo.GetGamesByUserID(testUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	byTitle := map[string][]*Game{}
+	var keys []string
+	for _, g := range games {
+		key := NormalizeTitle(g.Title)
+		if _, ok := byTitle[key]; !ok {
+			keys = append(keys, key)
+		}
+		byTitle[key] = append(byTitle[key], g)
+	}
+
+	groups := []*DuplicateGroup{}
+	for _, key := range keys {
+		candidates := byTitle[key]
+		if len(candidates) < 2 {
+			continue
+		}
+		// 別々のプラットフォームで持っているだけのゲームは重複ではない
+		var members []*Game
+		for _, g := range candidates {
+			for _, other := range candidates {
+				if g.ID != other.ID && IsLikelyDuplicate(g.Title, g.Platform, other.Title, other.Platform) {
+					members = append(members, g)
+					break
+				}
+			}
+		}
+		if len(members) >= 2 {
+			groups = append(groups, &DuplicateGroup{NormalizedTitle: key, Games: members})
+		}
+	}
+	return groups, nil
+}
+
+// statusProgress は、統合時にどちらのステータスを残すかを決めるための進み具合です。
+var statusProgress = map[string]int{
+	StatusUnstarted: 0,
+	StatusPlaying:   1,
+	StatusPaused:    1,
+	StatusDropped:   2,
+	StatusCompleted: 3,
+}
+
+// MergeGames は sourceID のゲームを targetID のゲームに統合します。
+//   - target の未設定の項目は source の値で埋める（プレイ時間は大きい方）
+//   - source の方が進んでいればステータスも引き継ぐ
+//   - メモ・ステータス履歴・タグは target に付け替え、スケジュールは MergeListener（calendar）が付け替える
+//   - source は完全に削除する
+func (s *service) MergeGames(targetID int, sourceID int, version int) (*Game, error) {
+	if targetID == sourceID {
+		return nil, ErrMergeSameGame
+	}
+
+	var target *Game
+	var staleCover string // 統合後に使われなくなる source のカバー画像
+	err := s.repo.RunInTx(func(tx *sql.Tx) error {
+		repo := s.repo.WithTx(tx)
+
+		var err error
+		target, err = repo.GetGameByID(targetID)
+		if err != nil || target == nil {
+			return err
+		}
+		if version != 0 && target.Version != version {
+			return ErrVersionMismatch
+		}
+		source, err := repo.GetGameByIDIncludingDeleted(sourceID)
+		if err != nil {
+			return err
+		}
+		if source == nil {
+			target = nil
+			return nil
+		}
+
+		if target.Platform == "" {
+			target.Platform = source.Platform
+		}
+		if target.Genre == "" {
+			target.Genre = source.Genre
+		}
+		if target.ReleaseDate.IsZero() {
+			target.ReleaseDate = source.ReleaseDate
+		}
+		if target.EstimatedHours == 0 {
+			target.EstimatedHours = source.EstimatedHours
+		}
+		if source.PlayedMinutes > target.PlayedMinutes {
+			target.PlayedMinutes = source.PlayedMinutes
+		}
+		if target.SteamAppID == 0 {
+			target.SteamAppID = source.SteamAppID
+		}
+		if target.CompletedAt == nil && source.CompletedAt != nil {
+			target.CompletedAt = source.CompletedAt
+			target.Rating = source.Rating
+			target.Review = source.Review
+			target.CompletionType = source.CompletionType
+		}
+		if target.PurchasePrice == nil && source.PurchasePrice != nil {
+			target.PurchasePrice = source.PurchasePrice
+			target.Currency = source.Currency
+		}
+		if target.PurchaseDate == "" {
+			target.PurchaseDate = source.PurchaseDate
+		}
+		if target.Store == "" {
+			target.Store = source.Store
+		}
+		target.Priority = max(target.Priority, source.Priority)
+		if target.Deadline == "" || (source.Deadline != "" && source.Deadline < target.Deadline) {
+			target.Deadline = source.Deadline // 早いほうの期限に合わせる
+		}
+		if target.CoverKey == "" {
+			target.CoverKey = source.CoverKey
+		} else {
+			staleCover = source.CoverKey
+		}
+
+		// 付け替えを先に行う（ステータス変更で target の予定が取り消される場合に source の予定も含めるため）
+		if err := repo.MoveNotes(sourceID, targetID); err != nil {
+			return err
+		}
+		if err := repo.MoveStatusHistory(sourceID, targetID); err != nil {
+			return err
+		}
+		if err := repo.MoveGameTags(sourceID, targetID); err != nil {
+			return err
+		}
+		if err := repo.MoveRelations(sourceID, targetID); err != nil {
+			return err
+		}
+		// 例: 1 → 2 → 3 の 3 を 1 に統合すると 1 と 2 が互いに前作になるので、統合できない
+		rels, err := repo.GetRelationsByUserID(testUserID)
+		if err != nil {
+			return err
+		}
+		if _, ok := playOrder(rels); !ok {
+			return ErrRelationCycle
+		}
+		for _, l := range s.mergers {
+			if err := l.OnGamesMerged(tx, target, source); err != nil {
+				return err
+			}
+		}
+
+		if statusProgress[source.Status] > statusProgress[target.Status] {
+			reason := fmt.Sprintf("merged from #%d", sourceID)
+			if err := s.applyStatus(tx, target, source.Status, reason); err != nil {
+				return err
+			}
+		}
+
+		if err := repo.UpdateGame(target); err != nil {
+			return err
+		}
+		return repo.DeleteGame(sourceID)
+	})
+	if err != nil {
+		log.Printf("Service: Error merging games: %v", err)
+		return nil, err
+	}
+	if target == nil {
+		return nil, nil
+	}
+	s.removeCoverFiles(staleCover)
+
+	return s.repo.GetGameByID(targetID)
+}
+
+// --- カバー画像 ---
+
+// SetCover はアップロードされた画像をゲームのカバー画像にします。
+// 元の画像とサムネイルを Storage に保存してから games.cover_key を更新し、前の画像のファイルを削除します。
+func (s *service) SetCover(id int, version int, data []byte) (*Game, error) {
+	game, err := s.repo.GetGameByID(id)
+	if err != nil || game == nil {
+		return nil, err
+	}
+	if version != 0 && game.Version != version {
+		return nil, ErrVersionMismatch
+	}
+
+	cover, err := processCover(id, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.covers.Put(coverKeyPrefix+cover.Name, cover.Data); err != nil {
+		log.Printf("Service: Error saving cover: %v", err)
+		return nil, err
+	}
+	if err := s.covers.Put(coverKeyPrefix+coverThumbnailName(cover.Name), cover.Thumbnail); err != nil {
+		log.Printf("Service: Error saving cover thumbnail: %v", err)
+		s.removeCoverFiles(cover.Name)
+		return nil, err
+	}
+
+	old := game.CoverKey
+	game.CoverKey = cover.Name
+	if err := s.repo.UpdateGame(game); err != nil {
+		log.Printf("Service: Error updating cover: %v", err)
+		if cover.Name != old { // 同じ画像の再アップロードなら、今のカバーのファイルなので消さない
+			s.removeCoverFiles(cover.Name)
+		}
+		return nil, err
+	}
+	if old != cover.Name {
+		s.removeCoverFiles(old)
+	}
+
+	return s.repo.GetGameByID(id)
+}
+
+// DeleteCover はカバー画像を外し、ファイルを削除します。
+func (s *service) DeleteCover(id int, version int) (*Game, error) {
+	game, err := s.repo.GetGameByID(id)
+	if err != nil || game == nil {
+		return nil, err
+	}
+	if version != 0 && game.Version != version {
+		return nil, ErrVersionMismatch
+	}
+	if game.CoverKey == "" {
+		return game, nil
+	}
+
+	old := game.CoverKey
+	game.CoverKey = ""
+	if err := s.repo.UpdateGame(game); err != nil {
+		log.Printf("Service: Error removing cover: %v", err)
+		return nil, err
+	}
+	s.removeCoverFiles(old)
+
+	return s.repo.GetGameByID(id)
+}
+
+// OpenCover はカバー画像（またはサムネイル）を開きます。見つからなければ storage.ErrNotFound を返します。
+func (s *service) OpenCover(name string) (*storage.Object, error) {
+	if CoverContentType(name) == "" {
+		return nil, storage.ErrNotFound
+	}
+	return s.covers.Open(coverKeyPrefix + name)
+}
+
+// removeCoverFiles は、カバー画像とサムネイルのファイルを削除します。
+// 削除に失敗してもゲームの操作は終わっているので、ログに残すだけにします。
+func (s *service) removeCoverFiles(name string) {
+	if name == "" {
+		return
+	}
+	for _, key := range []string{coverKeyPrefix + name, coverKeyPrefix + coverThumbnailName(name)} {
+		if err := s.covers.Delete(key); err != nil {
+			log.Printf("Service: Error deleting cover file %s: %v", key, err)
+		}
+	}
+}
+
+// --- 購入金額の集計 ---
+
+// GetSpending は購入金額の集計を返します。集計は repository の SQL で行います。
+func (s *service) GetSpending(currency string) (*SpendingReport, error) {
+	currencies, err := s.repo.GetPurchaseCurrencies(testUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		currency = DefaultCurrency
+		if len(currencies) > 0 {
+			currency = currencies[0]
+		}
+	}
+	if !currencyPattern.MatchString(currency) {
+		return nil, ErrInvalidCurrency
+	}
+
+	report := &SpendingReport{Currency: currency, OtherCurrencies: []string{}}
+	for _, c := range currencies {
+		if c != currency {
+			report.OtherCurrencies = append(report.OtherCurrencies, c)
+		}
+	}
+
+	total, err := s.repo.GetSpendingSummaries(testUserID, currency, SpendingTotal)
+	if err != nil {
+		return nil, err
+	}
+	report.Total = &SpendingSummary{}
+	if len(total) > 0 {
+		report.Total = total[0]
+	}
+	if report.ByPlatform, err = s.repo.GetSpendingSummaries(testUserID, currency, SpendingByPlatform); err != nil {
+		return nil, err
+	}
+	if report.ByYear, err = s.repo.GetSpendingSummaries(testUserID, currency, SpendingByYear); err != nil {
+		return nil, err
+	}
+
+	for _, summary := range append([]*SpendingSummary{report.Total}, append(report.ByPlatform, report.ByYear...)...) {
+		if summary.CompletedHours > 0 {
+			cost := float64(summary.CompletedAmount) / summary.CompletedHours
+			summary.CostPerCompletedHour = &cost
+		}
+	}
+
+	return report, nil
+}
+
+// --- ゲーム同士の関係 (GameRelation) ---
+
+// GetRelations は、ゲームに関係する（どちら側でも）関係の一覧を返します。
+func (s *service) GetRelations(gameID int) ([]*GameRelation, error) {
+	game, err := s.repo.GetGameByID(gameID)
+	if err != nil || game == nil {
+		return nil, err
+	}
+
+	rels, err := s.repo.GetRelationsByUserID(testUserID)
+	if err != nil {
+		return nil, err
+	}
+	result := []*GameRelation{}
+	for _, rel := range rels {
+		if rel.GameID == gameID || rel.RelatedGameID == gameID {
+			result = append(result, rel)
+		}
+	}
+	return result, nil
+}
+
+// AddRelation は関係を追加します。
+//   - DLC の本編は1つだけ
+//   - 関係をたどって自分に戻ってくる（プレイ順が循環する）関係は追加できない
+func (s *service) AddRelation(gameID int, req *RelationRequest) (*GameRelation, error) {
+	if !IsValidRelationType(req.Type) {
+		return nil, ErrInvalidRelationType
+	}
+	if req.RelatedGameID == gameID {
+		return nil, ErrSelfRelation
+	}
+
+	var rel *GameRelation
+	err := s.repo.RunInTx(func(tx *sql.Tx) error {
+		repo := s.repo.WithTx(tx)
+
+		game, err := repo.GetGameByID(gameID)
+		if err != nil || game == nil {
+			return err
+		}
+		related, err := repo.GetGameByID(req.RelatedGameID)
+		if err != nil {
+			return err
+		}
+		if related == nil || related.UserID != game.UserID {
+			return ErrGameNotFound
+		}
+
+		rels, err := repo.GetRelationsByUserID(game.UserID)
+		if err != nil {
+			return err
+		}
+		for _, r := range rels {
+			if r.GameID != gameID {
+				continue
+			}
+			if r.RelatedGameID == req.RelatedGameID && r.Type == req.Type {
+				return ErrDuplicateRelation
+			}
+			if r.Type == RelationDLCOf && req.Type == RelationDLCOf {
+				return ErrAlreadyDLC
+			}
+		}
+
+		rel = &GameRelation{GameID: gameID, RelatedGameID: req.RelatedGameID, Type: req.Type}
+		if _, ok := playOrder(append(rels, rel)); !ok {
+			return ErrRelationCycle
+		}
+
+		id, err := repo.CreateRelation(rel)
+		if err != nil {
+			return err
+		}
+		rel, err = repo.GetRelationByID(id)
+		return err
+	})
+	if err != nil {
+		log.Printf("Service: Error adding relation: %v", err)
+		return nil, err
+	}
+
+	return rel, nil
+}
+
+// DeleteRelation は関係を削除します。
+func (s *service) DeleteRelation(gameID int, relationID int) error {
+	rel, err := s.repo.GetRelationByID(relationID)
+	if err != nil {
+		return err
+	}
+	if rel == nil || (rel.GameID != gameID && rel.RelatedGameID != gameID) {
+		return ErrRelationNotFound
+	}
+	return s.repo.DeleteRelation(relationID)
+}
+
+// GetGameGraph は、関係のあるゲームと関係の一覧を返します。
+// ノードはプレイ順（本編・前作が先）に並べます。ゴミ箱のゲームとその関係は含めません。
+func (s *service) GetGameGraph() (*GameGraph, error) {
+	games, rels, err := s.activeRelations()
+	if err != nil {
+		return nil, err
+	}
+
+	order, _ := playOrder(rels) // 追加時に循環は防いでいるので、順番は必ず決まる
+	blocked := blockedBy(games, rels)
+
+	graph := &GameGraph{Nodes: []*GraphNode{}, Edges: rels}
+	for _, id := range order {
+		g := games[id]
+		node := &GraphNode{ID: g.ID, Title: g.Title, Status: g.Status, BlockedBy: blocked[g.ID]}
+		if node.BlockedBy == nil {
+			node.BlockedBy = []int{}
+		}
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	return graph, nil
+}
+
+// BlockedGames は、まだクリアされていない本編・前作があるゲームを返します。
+func (s *service) BlockedGames() (map[int][]int, error) {
+	games, rels, err := s.activeRelations()
+	if err != nil {
+		return nil, err
+	}
+	return blockedBy(games, rels), nil
+}
+
+// activeRelations は、ゴミ箱にないゲームと、そのゲーム同士の関係を返します。
+func (s *service) activeRelations() (map[int]*Game, []*GameRelation, error) {
+	list, err := s.repo.GetGamesByUserID(testUserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	games := make(map[int]*Game, len(list))
+	for _, g := range list {
+		games[g.ID] = g
+	}
+
+	all, err := s.repo.GetRelationsByUserID(testUserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	rels := []*GameRelation{}
+	for _, rel := range all {
+		if games[rel.GameID] != nil && games[rel.RelatedGameID] != nil {
+			rels = append(rels, rel)
+		}
+	}
+	return games, rels, nil
+}
+
+// --- タグ (Tag) ---
+
+// tagColorPattern は、タグの色として受け付ける形式（#RRGGBB）です。
+var tagColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// GetTags は（テストユーザーの）タグ一覧を返します。
+func (s *service) GetTags() ([]*Tag, error) {
+	return s.repo.GetTagsByUserID(testUserID)
+}
+
+// CreateTag は新しいタグを作成します。
+func (s *service) CreateTag(req *TagRequest) (*Tag, error) {
+	tag := &Tag{UserID: testUserID}
+	if err := s.applyTagRequest(tag, req); err != nil {
+		return nil, err
+	}
+
+	id, err := s.repo.CreateTag(tag)
+	if err != nil {
+		log.Printf("Service: Error creating tag: %v", err)
+		return nil, err
+	}
+	return s.repo.GetTagByID(id)
+}
+
+// UpdateTag はタグの名前・色・曜日を更新します。
+func (s *service) UpdateTag(id int, req *TagRequest) (*Tag, error) {
+	tag, err := s.repo.GetTagByID(id)
+	if err != nil || tag == nil {
+		return nil, err
+	}
+	if err := s.applyTagRequest(tag, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateTag(tag); err != nil {
+		log.Printf("Service: Error updating tag: %v", err)
+		return nil, err
+	}
+	return s.repo.GetTagByID(id)
+}
+
+// applyTagRequest は、req を検証して tag に反映します。
+func (s *service) applyTagRequest(tag *Tag, req *TagRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return ErrEmptyTagName
+	}
+	existing, err := s.repo.GetTagByName(tag.UserID, name)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != tag.ID {
+		return ErrDuplicateTag
+	}
+
+	color := strings.ToLower(strings.TrimSpace(req.Color))
+	if color == "" {
+		color = DefaultTagColor
+	}
+	if !tagColorPattern.MatchString(color) {
+		return ErrInvalidTagColor
+	}
+
+	days := []string{}
+	seen := map[string]bool{}
+	for _, d := range req.ScheduleDays {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if _, ok := weekdayNames[d]; !ok {
+			return ErrInvalidScheduleDay
+		}
+		if !seen[d] {
+			seen[d] = true
+			days = append(days, d)
+		}
+	}
+
+	tag.Name = name
+	tag.Color = color
+	tag.ScheduleDays = days
+	return nil
+}
+
+// DeleteTag はタグを削除します。ゲームへの割り当ても一緒に消えます。
+func (s *service) DeleteTag(id int) error {
+	return s.repo.RunInTx(func(tx *sql.Tx) error {
+		repo := s.repo.WithTx(tx)
+		if err := repo.DeleteGameTagsByTagID(id); err != nil {
+			return err
+		}
+		return repo.DeleteTag(id)
+	})
+}
+
+// AssignTag はゲームにタグを付け、タグ反映後のゲームを返します。すでに付いている場合もそのまま返します。
+func (s *service) AssignTag(gameID int, tagID int) (*Game, error) {
+	game, tag, err := s.getGameAndTag(gameID, tagID)
+	if err != nil || game == nil || tag == nil {
+		return nil, err
+	}
+
+	if err := s.repo.AddGameTag(gameID, tagID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetGameByID(gameID)
+}
+
+// UnassignTag はゲームからタグを外し、反映後のゲームを返します。
+func (s *service) UnassignTag(gameID int, tagID int) (*Game, error) {
+	game, tag, err := s.getGameAndTag(gameID, tagID)
+	if err != nil || game == nil || tag == nil {
+		return nil, err
+	}
+
+	if err := s.repo.RemoveGameTag(gameID, tagID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetGameByID(gameID)
+}
+
+// getGameAndTag は、ゲームとタグを取得します。どちらかが見つからなければ nil です。
+func (s *service) getGameAndTag(gameID int, tagID int) (*Game, *Tag, error) {
+	game, err := s.repo.GetGameByID(gameID)
+	if err != nil || game == nil {
+		return nil, nil, err
+	}
+	tag, err := s.repo.GetTagByID(tagID)
+	if err != nil || tag == nil || tag.UserID != game.UserID {
+		return nil, nil, err
+	}
+	return game, tag, nil
+}
+
+// --- ジャンル・プラットフォームのカタログ ---
+
+// GetGenres は、登録できるジャンルの一覧を返します。
+func (s *service) GetGenres() []string {
+	return Genres
+}
+
+// GetPlatforms は、プラットフォームのカタログ（別名つき）を返します。
+func (s *service) GetPlatforms() ([]*Platform, error) {
+	return s.repo.GetPlatforms()
+}
+
+// NormalizeCatalog は、req のジャンル・プラットフォームをカタログの正式名にそろえます。
+func (s *service) NormalizeCatalog(req *CreateGameRequest) error {
+	platform, genre, err := s.normalizeCatalog(req.Platform, req.Genre)
+	if err != nil {
+		return err
+	}
+	req.Platform = platform
+	req.Genre = genre
+	return nil
+}
+
+// normalizeCatalog は、プラットフォームとジャンルを正式名に変換します。空文字は未設定のまま返します。
+func (s *service) normalizeCatalog(platform string, genre string) (string, string, error) {
+	canonicalGenre, err := NormalizeGenre(genre)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %q", err, genre)
+	}
+
+	if strings.TrimSpace(platform) == "" {
+		return "", canonicalGenre, nil
+	}
+	p, err := s.repo.GetPlatformByAlias(platform)
+	if err != nil {
+		return "", "", err
+	}
+	if p == nil {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidPlatform, platform)
+	}
+	return p.Name, canonicalGenre, nil
+}
+
+// --- 一括操作 ---
+
+// maxBulkItems は、1回の一括操作で扱える項目（操作 × ゲーム）の上限です。
+const maxBulkItems = 1000
+
+// BulkUpdate は、複数のゲームへの操作を1つのトランザクションで実行します。
+//   - atomic: 1件でも失敗したらそこで止め、全体を取り消す
+//   - best_effort: 項目ごとにセーブポイントを置き、失敗した項目だけ取り消して続ける
+//
+// どのモードでも、項目ごとにゲームの持ち主を確認します。
+func (s *service) BulkUpdate(req *BulkRequest) (*BulkResponse, error) {
+	if err := s.validateBulkRequest(req); err != nil {
+		return nil, err
+	}
+	mode := req.Mode
+	if mode == "" {
+		mode = BulkModeAtomic
+	}
+
+	res := &BulkResponse{Mode: mode, Results: []BulkItemResult{}}
+	for i, op := range req.Operations {
+		for _, id := range op.IDs {
+			res.Results = append(res.Results, BulkItemResult{Operation: i, Op: op.Op, ID: id, Result: BulkResultNotRun})
+		}
+	}
+
+	// atomic で失敗したときにロールバックさせるためのエラー（呼び出し元には返さない）
+	errRollback := errors.New("bulk update rolled back")
+
+	err := s.repo.RunInTx(func(tx *sql.Tx) error {
+		repo := s.repo.WithTx(tx)
+
+		for i := range res.Results {
+			item := &res.Results[i]
+			op := &req.Operations[item.Operation]
+
+			var err error
+			if mode == BulkModeBestEffort {
+				err = repo.RunInSavepoint(func() error {
+					return s.applyBulkOperation(tx, op, item.ID)
+				})
+			} else {
+				err = s.applyBulkOperation(tx, op, item.ID)
+			}
+
+			if err != nil {
+				item.Result = BulkResultFailed
+				item.Error = err.Error()
+				res.Failed++
+				if mode == BulkModeAtomic {
+					return errRollback
+				}
+				continue
+			}
+			item.Result = BulkResultOK
+			res.Succeeded++
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRollback) {
+		log.Printf("Service: Error running bulk update: %v", err)
+		return nil, err
+	}
+
+	res.Committed = err == nil
+	if !res.Committed {
+		// 取り消された成功分は rolled_back として返す
+		for i := range res.Results {
+			if res.Results[i].Result == BulkResultOK {
+				res.Results[i].Result = BulkResultRolledBack
+			}
+		}
+		res.Succeeded = 0
+	}
+	return res, nil
+}
+
+// validateBulkRequest は、実行前に分かるリクエストの誤りをチェックします。
+func (s *service) validateBulkRequest(req *BulkRequest) error {
+	switch req.Mode {
+	case "", BulkModeAtomic, BulkModeBestEffort:
+	default:
+		return fmt.Errorf("%w: mode must be %s or %s", ErrInvalidBulkRequest, BulkModeAtomic, BulkModeBestEffort)
+	}
+	if len(req.Operations) == 0 {
+		return fmt.Errorf("%w: operations must not be empty", ErrInvalidBulkRequest)
+	}
+
+	total := 0
+	for i := range req.Operations {
+		op := &req.Operations[i]
+		if len(op.IDs) == 0 {
+			return fmt.Errorf("%w: operations[%d].ids must not be empty", ErrInvalidBulkRequest, i)
+		}
+		total += len(op.IDs)
+
+		switch op.Op {
+		case BulkOpSetStatus:
+			if !IsValidStatus(op.Status) {
+				return fmt.Errorf("%w: operations[%d]: %v", ErrInvalidBulkRequest, i, ErrInvalidStatus)
+			}
+		case BulkOpAddTag:
+			if op.TagID <= 0 {
+				return fmt.Errorf("%w: operations[%d].tag_id is required", ErrInvalidBulkRequest, i)
+			}
+		case BulkOpSetPlatform:
+			// カタログの正式名にそろえる（カタログにない値はここでエラーにする）
+			platform, _, err := s.normalizeCatalog(op.Platform, "")
+			if err != nil {
+				return fmt.Errorf("%w: operations[%d]: %v", ErrInvalidBulkRequest, i, err)
+			}
+			op.Platform = platform
+		case BulkOpDelete:
+		default:
+			return fmt.Errorf("%w: operations[%d].op must be one of %s, %s, %s, %s",
+				ErrInvalidBulkRequest, i, BulkOpSetStatus, BulkOpAddTag, BulkOpSetPlatform, BulkOpDelete)
+		}
+	}
+	if total > maxBulkItems {
+		return fmt.Errorf("%w: too many items (max %d)", ErrInvalidBulkRequest, maxBulkItems)
+	}
+	return nil
+}
+
+// applyBulkOperation は、1つのゲームに1つの操作を行います。
+func (s *service) applyBulkOperation(tx *sql.Tx, op *BulkOperation, id int) error {
+	repo := s.repo.WithTx(tx)
+
+	game, err := repo.GetGameByID(id)
+	if err != nil {
+		return err
+	}
+	if game == nil {
+		return ErrGameNotFound
+	}
+	// 他人のゲームは、ID を知っていても操作できない
+	if game.UserID != testUserID {
+		return ErrForbidden
+	}
+
+	switch op.Op {
+	case BulkOpSetStatus:
+		reason := op.Reason
+		if reason == "" {
+			reason = "bulk update"
+		}
+		changed, err := s.changeStatus(tx, game, op.Status, reason)
+		if err != nil || !changed {
+			return err
+		}
+		return repo.UpdateGame(game)
+
+	case BulkOpAddTag:
+		tag, err := repo.GetTagByID(op.TagID)
+		if err != nil {
+			return err
+		}
+		if tag == nil || tag.UserID != game.UserID {
+			return ErrTagNotFound
+		}
+		return repo.AddGameTag(id, op.TagID)
+
+	case BulkOpSetPlatform:
+		if game.Platform == op.Platform {
+			return nil
+		}
+		game.Platform = op.Platform
+		return repo.UpdateGame(game)
+
+	case BulkOpDelete:
+		return repo.SoftDeleteGame(id, game.Version)
+	}
+	return nil
+}
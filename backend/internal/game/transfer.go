@@ -0,0 +1,279 @@
+package game
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// インポート・エクスポートのファイル形式
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// exportColumns は CSV エクスポートの列です。インポートでは importColumns 以外の列は無視します
+// （エクスポートしたファイルをそのまま読み込めるようにするため）。
+var exportColumns = []string{
+	"id", "title", "platform", "genre", "status", "release_date", "estimated_hours", "played_minutes",
+	"steam_app_id", "rating", "review", "completion_type", "completed_at", "created_at",
+	"purchase_price", "currency", "purchase_date", "store", "priority", "deadline",
+}
+
+// importColumns は、インポート時に CreateGameRequest に反映する列です。
+var importColumns = map[string]bool{
+	"title": true, "platform": true, "genre": true, "status": true, "release_date": true,
+	"estimated_hours": true, "played_minutes": true, "steam_app_id": true,
+	"purchase_price": true, "currency": true, "purchase_date": true, "store": true,
+	"priority": true, "deadline": true,
+}
+
+// dateLayout は、エクスポートする日付の形式です（インポートでは RFC3339 も受け付けます）。
+const dateLayout = "2006-01-02"
+
+// gameToRecord は、Game を exportColumns 順の文字列に変換します。
+func gameToRecord(g *Game) []string {
+	releaseDate := ""
+	if !g.ReleaseDate.IsZero() {
+		releaseDate = g.ReleaseDate.Format(dateLayout)
+	}
+	completedAt := ""
+	if g.CompletedAt != nil {
+		completedAt = g.CompletedAt.Format(time.RFC3339)
+	}
+	purchasePrice := ""
+	if g.PurchasePrice != nil {
+		purchasePrice = strconv.Itoa(*g.PurchasePrice)
+	}
+	return []string{
+		strconv.Itoa(g.ID),
+		g.Title,
+		g.Platform,
+		g.Genre,
+		g.Status,
+		releaseDate,
+		strconv.FormatFloat(g.EstimatedHours, 'f', -1, 64),
+		strconv.Itoa(g.PlayedMinutes),
+		strconv.Itoa(g.SteamAppID),
+		strconv.Itoa(g.Rating),
+		g.Review,
+		g.CompletionType,
+		completedAt,
+		g.CreatedAt.Format(time.RFC3339),
+		purchasePrice,
+		g.Currency,
+		g.PurchaseDate,
+		g.Store,
+		strconv.Itoa(g.Priority),
+		g.Deadline,
+	}
+}
+
+// EncodeGames は、ゲーム一覧を指定の形式で書き出します。
+func EncodeGames(w io.Writer, format string, games []*Game) error {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(exportColumns); err != nil {
+			return err
+		}
+		for _, g := range games {
+			if err := cw.Write(gameToRecord(g)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatJSON:
+		if games == nil {
+			games = []*Game{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(games)
+	default:
+		return ErrUnsupportedFormat
+	}
+}
+
+// importRow は、インポートファイルの1行分です。
+// values の列名は小文字・前後の空白なしにそろえます。
+type importRow struct {
+	values   map[string]string
+	problems []string // 読み込み時点で見つかった問題
+}
+
+// decodeImportRows は、インポートファイルを行の一覧に変換します。
+// ファイル全体が読めない場合だけエラーを返し、行ごとの問題は importRow に残します。
+func decodeImportRows(format string, r io.Reader) ([]importRow, error) {
+	switch format {
+	case FormatCSV:
+		return decodeCSVRows(r)
+	case FormatJSON:
+		return decodeJSONRows(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+func decodeCSVRows(r io.Reader) ([]importRow, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	// Excel で保存した CSV の BOM を取り除く
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1 // 列数の違いは行ごとのエラーとして扱わず、足りない列は空とみなす
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: missing header row", ErrInvalidImportFile)
+	}
+
+	header := make([]string, len(records[0]))
+	for i, h := range records[0] {
+		header[i] = strings.ToLower(strings.TrimSpace(h))
+	}
+
+	rows := make([]importRow, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := importRow{values: make(map[string]string, len(header))}
+		for i, h := range header {
+			if i < len(record) {
+				row.values[h] = strings.TrimSpace(record[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func decodeJSONRows(r io.Reader) ([]importRow, error) {
+	var items []map[string]any
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(&items); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+
+	rows := make([]importRow, 0, len(items))
+	for _, item := range items {
+		row := importRow{values: make(map[string]string, len(item))}
+		for k, v := range item {
+			key := strings.ToLower(strings.TrimSpace(k))
+			switch v := v.(type) {
+			case nil:
+				row.values[key] = ""
+			case string:
+				row.values[key] = strings.TrimSpace(v)
+			case json.Number:
+				row.values[key] = v.String()
+			case bool:
+				row.values[key] = strconv.FormatBool(v)
+			default:
+				// 入れ子のオブジェクトや配列は対応する列がないので、取り込む列なら行のエラーにする
+				if importColumns[key] {
+					row.problems = append(row.problems, fmt.Sprintf("%s: unsupported value type", key))
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// rowToCreateRequest は、1行分の値を CreateGameRequest に変換します。
+// 問題があればその内容を列ごとのメッセージとして返します。
+func rowToCreateRequest(r importRow) (*CreateGameRequest, []string) {
+	row := r.values
+	problems := append([]string{}, r.problems...)
+
+	req := &CreateGameRequest{
+		Title:        row["title"],
+		Platform:     row["platform"],
+		Genre:        row["genre"],
+		Status:       row["status"],
+		Currency:     row["currency"],
+		PurchaseDate: row["purchase_date"],
+		Store:        row["store"],
+		Deadline:     row["deadline"],
+	}
+
+	if req.Title == "" {
+		problems = append(problems, "title: required")
+	}
+	if req.Status != "" && !IsValidStatus(req.Status) {
+		problems = append(problems, fmt.Sprintf("status: unknown status %q", req.Status))
+	}
+
+	if v := row["release_date"]; v != "" {
+		t, err := parseImportDate(v)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("release_date: %q is not a date (use YYYY-MM-DD)", v))
+		}
+		req.ReleaseDate = t
+	}
+	if v := row["estimated_hours"]; v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			problems = append(problems, fmt.Sprintf("estimated_hours: %q is not a non-negative number", v))
+		}
+		req.EstimatedHours = f
+	}
+	if v := row["played_minutes"]; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			problems = append(problems, fmt.Sprintf("played_minutes: %q is not a non-negative integer", v))
+		}
+		req.PlayedMinutes = n
+	}
+	if v := row["steam_app_id"]; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			problems = append(problems, fmt.Sprintf("steam_app_id: %q is not a non-negative integer", v))
+		}
+		req.SteamAppID = n
+	}
+	if v := row["purchase_price"]; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			problems = append(problems, fmt.Sprintf("purchase_price: %q is not a non-negative integer", v))
+		}
+		req.PurchasePrice = &n
+	}
+	purchase := &Game{PurchasePrice: req.PurchasePrice, Currency: req.Currency, PurchaseDate: req.PurchaseDate}
+	if err := normalizePurchase(purchase); err != nil && !errors.Is(err, ErrNegativePrice) {
+		problems = append(problems, err.Error())
+	}
+
+	if v := row["priority"]; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("priority: %q is not an integer", v))
+		}
+		req.Priority = n
+	}
+	if err := normalizePlan(&Game{Priority: req.Priority, Deadline: req.Deadline}); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	return req, problems
+}
+
+// parseImportDate は YYYY-MM-DD か RFC3339 の日付を読み取ります。
+func parseImportDate(v string) (time.Time, error) {
+	if t, err := time.Parse(dateLayout, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
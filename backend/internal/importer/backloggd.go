@@ -0,0 +1,95 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+
+	"TO-DO-IT/internal/game"
+)
+
+// backloggdImporter は、Backloggd のエクスポート CSV を読み取ります。
+type backloggdImporter struct{}
+
+// NewBackloggdImporter は、Backloggd 用の Importer を作成します。
+func NewBackloggdImporter() Importer {
+	return &backloggdImporter{}
+}
+
+func (b *backloggdImporter) Source() string { return "backloggd" }
+func (b *backloggdImporter) Format() string { return "csv" }
+
+// backloggdStatuses は、Backloggd のステータス（プレイ種別）をこのアプリのステータスに対応づけます。
+// キーは normalizeKey 済みの名前です。ウィッシュリストは持っていないゲームなので取り込みません。
+var backloggdStatuses = map[string]string{
+	"backlog":   game.StatusUnstarted,
+	"playing":   game.StatusPlaying,
+	"shelved":   game.StatusPaused,
+	"played":    game.StatusPaused,
+	"completed": game.StatusCompleted,
+	"mastered":  game.StatusCompleted,
+	"retired":   game.StatusCompleted,
+	"abandoned": game.StatusDropped,
+}
+
+// Backloggd の列名の候補（エクスポートの版によって名前が違うため複数）
+var (
+	backloggdTitleColumns    = []string{"Game Name", "Name", "Title", "Game"}
+	backloggdPlatformColumns = []string{"Platform", "Platforms"}
+	backloggdStatusColumns   = []string{"Play Type", "Status"}
+	backloggdHoursColumns    = []string{"Time Played", "Hours Played", "Playtime"}
+	backloggdReleaseColumns  = []string{"Release Date", "Released"}
+)
+
+// Parse は Backloggd の CSV を読み取ります。
+func (b *backloggdImporter) Parse(r io.Reader) ([]Record, error) {
+	f, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	if !f.has(backloggdTitleColumns...) {
+		return nil, fmt.Errorf("%w: missing game name column", ErrInvalidFile)
+	}
+
+	records := make([]Record, 0, len(f.rows))
+	for _, row := range f.rows {
+		req := &game.CreateGameRequest{
+			Title:    f.value(row, backloggdTitleColumns...),
+			Platform: f.value(row, backloggdPlatformColumns...),
+			Status:   game.StatusUnstarted,
+		}
+		rec := Record{Request: req}
+
+		if req.Title == "" {
+			rec.Problems = append(rec.Problems, "Game Name: required")
+		}
+
+		if v := f.value(row, backloggdStatusColumns...); v != "" {
+			key := normalizeKey(v)
+			if key == "wishlist" {
+				rec.Skip = "wishlist"
+			} else if status, ok := backloggdStatuses[key]; ok {
+				req.Status = status
+			} else {
+				rec.Problems = append(rec.Problems, fmt.Sprintf("Status: unknown status %q", v))
+			}
+		}
+
+		if v := f.value(row, backloggdHoursColumns...); v != "" {
+			minutes, err := parseMinutes(v)
+			if err != nil {
+				rec.Problems = append(rec.Problems, "Time Played: "+err.Error())
+			}
+			req.PlayedMinutes = minutes
+		}
+		if v := f.value(row, backloggdReleaseColumns...); v != "" {
+			t, err := parseDate(v)
+			if err != nil {
+				rec.Problems = append(rec.Problems, "Release Date: "+err.Error())
+			}
+			req.ReleaseDate = t
+		}
+
+		records = append(records, rec)
+	}
+	return records, nil
+}
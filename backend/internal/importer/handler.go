@@ -0,0 +1,106 @@
+package importer
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"TO-DO-IT/internal/game"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxFileSize は、インポートできるファイルの上限サイズです。
+const maxFileSize = 10 << 20 // 10MB
+
+// Handler は、他サービスからのインポートのHTTPリクエスト処理に関するインターフェースです。
+type Handler interface {
+	RegisterRoutes(apiGroup *echo.Group)
+	GetSources(c echo.Context) error
+	Import(c echo.Context) error
+}
+
+// handler は Handler インターフェースの具体的な実装です。
+type handler struct {
+	svc Service
+}
+
+// NewHandler は、新しい handler インスタンスを作成します。
+func NewHandler(svc Service) Handler {
+	return &handler{svc: svc}
+}
+
+// RegisterRoutes は、ルーターにエンドポイントを登録します。
+func (h *handler) RegisterRoutes(apiGroup *echo.Group) {
+	importRoutes := apiGroup.Group("/import") // /api/import がベースになる
+	{
+		importRoutes.GET("/sources", h.GetSources) // GET /api/import/sources
+		importRoutes.POST("/:source", h.Import)    // POST /api/import/:source?mode=preview|commit
+	}
+}
+
+// GetSources は対応しているインポート元の一覧を返します (GET /api/import/sources)
+func (h *handler) GetSources(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.svc.Sources())
+}
+
+// Import は他サービスのエクスポートファイルを取り込みます (POST /api/import/:source)
+// ファイルは multipart の file フィールドで受け取ります。
+// まず mode=preview（省略時）で結果を確認し、問題なければ同じファイルを mode=commit で送ります。
+func (h *handler) Import(c echo.Context) error {
+	source := c.Param("source")
+
+	mode := c.QueryParam("mode")
+	if mode == "" {
+		mode = "preview"
+	}
+	if mode != "preview" && mode != "commit" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "mode must be preview or commit"})
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "file is required (multipart field \"file\")"})
+	}
+	if file.Size > maxFileSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "file is too large"})
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		log.Printf("Handler: Failed to open uploaded file: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Failed to read uploaded file"})
+	}
+	defer src.Close()
+
+	var report *game.ImportReport
+	if mode == "commit" {
+		report, err = h.svc.Commit(source, src)
+	} else {
+		report, err = h.svc.Preview(source, src)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUnknownSource):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error() + ": " + source})
+		case errors.Is(err, ErrInvalidFile):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Handler: Error importing from %s: %v", source, err)
+		// 登録は全体でロールバックされているので、失敗した行を返して何も登録されていないことを伝える
+		var rowErr *game.ImportRowError
+		if errors.As(err, &rowErr) {
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"error": "Failed to import games; no games were imported",
+				"row":   rowErr.Row,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to import games"})
+	}
+
+	status := http.StatusOK
+	if report.Created > 0 {
+		status = http.StatusCreated
+	}
+	return c.JSON(status, report)
+}
@@ -0,0 +1,85 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+
+	"TO-DO-IT/internal/game"
+)
+
+// howLongToBeatImporter は、HowLongToBeat の「My Games」エクスポート CSV を読み取ります。
+// HowLongToBeat はステータスを Playing / Backlog / Completed / Retired の
+// チェック欄で表すので、それぞれをステータスに対応づけます。
+type howLongToBeatImporter struct{}
+
+// NewHowLongToBeatImporter は、HowLongToBeat 用の Importer を作成します。
+func NewHowLongToBeatImporter() Importer {
+	return &howLongToBeatImporter{}
+}
+
+func (h *howLongToBeatImporter) Source() string { return "howlongtobeat" }
+func (h *howLongToBeatImporter) Format() string { return "csv" }
+
+// Parse は HowLongToBeat の CSV を読み取ります。
+// 推定プレイ時間は Main Story（無ければ Main + Extras、Completionist）を使います。
+func (h *howLongToBeatImporter) Parse(r io.Reader) ([]Record, error) {
+	f, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	if !f.has("Title", "Game") {
+		return nil, fmt.Errorf("%w: missing title column", ErrInvalidFile)
+	}
+
+	records := make([]Record, 0, len(f.rows))
+	for _, row := range f.rows {
+		req := &game.CreateGameRequest{
+			Title:    f.value(row, "Title", "Game"),
+			Platform: f.value(row, "Platform"),
+		}
+		rec := Record{Request: req}
+
+		if req.Title == "" {
+			rec.Problems = append(rec.Problems, "Title: required")
+		}
+
+		// 複数チェックされている場合は、より進んだ状態を優先する
+		switch {
+		case isChecked(f.value(row, "Completed")):
+			req.Status = game.StatusCompleted
+		case isChecked(f.value(row, "Retired")):
+			req.Status = game.StatusDropped
+		case isChecked(f.value(row, "Playing")):
+			req.Status = game.StatusPlaying
+		default:
+			req.Status = game.StatusUnstarted
+		}
+
+		if v := f.value(row, "Progress"); v != "" {
+			minutes, err := parseMinutes(v)
+			if err != nil {
+				rec.Problems = append(rec.Problems, "Progress: "+err.Error())
+			}
+			req.PlayedMinutes = minutes
+		}
+
+		for _, column := range []string{"Main Story", "Main + Extras", "Completionist"} {
+			v := f.value(row, column)
+			if v == "" {
+				continue
+			}
+			minutes, err := parseMinutes(v)
+			if err != nil {
+				rec.Problems = append(rec.Problems, column+": "+err.Error())
+				break
+			}
+			if minutes > 0 {
+				req.EstimatedHours = float64(minutes) / 60
+				break
+			}
+		}
+
+		records = append(records, rec)
+	}
+	return records, nil
+}
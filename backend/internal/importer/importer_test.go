@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"TO-DO-IT/internal/game"
+)
+
+// wantRecord は、Record のうちテストで確かめる項目です。
+type wantRecord struct {
+	title         string
+	platform      string
+	status        string
+	playedMinutes int
+	estimated     float64
+	skip          string
+	problems      int
+}
+
+// checkRecords は、Parse の結果を want と1件ずつ比べます。
+func checkRecords(t *testing.T, imp Importer, input string, want []wantRecord) {
+	t.Helper()
+	records, err := imp.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i, w := range want {
+		rec, req := records[i], records[i].Request
+		got := wantRecord{
+			title:         req.Title,
+			platform:      req.Platform,
+			status:        req.Status,
+			playedMinutes: req.PlayedMinutes,
+			estimated:     req.EstimatedHours,
+			skip:          rec.Skip,
+			problems:      len(rec.Problems),
+		}
+		if got != w {
+			t.Errorf("record %d = %+v, want %+v (problems: %v)", i+1, got, w, rec.Problems)
+		}
+	}
+}
+
+func TestPlayniteParse(t *testing.T) {
+	input := `[
+		{"Name": "Hades", "Platforms": [{"Name": "PC (Windows)"}], "CompletionStatus": {"Name": "Beaten"}, "Playtime": 7200},
+		{"Name": "Celeste", "Source": "Steam", "Playtime": 600},
+		{"Name": "Tunic", "Platforms": ["Nintendo Switch"], "CompletionStatus": "Plan to Play"},
+		{"Name": "Hidden Game", "Hidden": true},
+		{"Name": "", "CompletionStatus": "Someday"},
+		{"Name": "Bad Date", "ReleaseDate": {"ReleaseDate": "soon"}},
+		"not an object"
+	]`
+	checkRecords(t, NewPlayniteImporter(), input, []wantRecord{
+		{title: "Hades", platform: "PC (Windows)", status: game.StatusCompleted, playedMinutes: 120},
+		{title: "Celeste", platform: "Steam", status: game.StatusPaused, playedMinutes: 10},
+		{title: "Tunic", platform: "Nintendo Switch", status: game.StatusUnstarted},
+		{title: "Hidden Game", status: game.StatusUnstarted, skip: "hidden in Playnite"},
+		{status: "", problems: 2}, // 名前がなく、ステータスも不明
+		{title: "Bad Date", status: game.StatusUnstarted, problems: 1},
+		{problems: 1},
+	})
+}
+
+func TestBackloggdParse(t *testing.T) {
+	input := "\xef\xbb\xbfGame Name,Platform,Play Type,Time Played,Release Date\n" +
+		"Elden Ring,PlayStation 5,Playing,12h 30m,\"Feb 25, 2022\"\n" +
+		"Hollow Knight,PC,Shelved,20,\n" +
+		"Starfield,PC,Wishlist,,\n" +
+		"Outer Wilds,PC,Mastered,1:30,\n" +
+		"Mystery,PC,Someday,,\n" +
+		",PC,Backlog,lots,\n"
+	checkRecords(t, NewBackloggdImporter(), input, []wantRecord{
+		{title: "Elden Ring", platform: "PlayStation 5", status: game.StatusPlaying, playedMinutes: 750},
+		{title: "Hollow Knight", platform: "PC", status: game.StatusPaused, playedMinutes: 1200},
+		{title: "Starfield", platform: "PC", status: game.StatusUnstarted, skip: "wishlist"},
+		{title: "Outer Wilds", platform: "PC", status: game.StatusCompleted, playedMinutes: 90},
+		{title: "Mystery", platform: "PC", status: game.StatusUnstarted, problems: 1},
+		{platform: "PC", status: game.StatusUnstarted, problems: 2}, // 名前がなく、時間も読めない
+	})
+}
+
+func TestHowLongToBeatParse(t *testing.T) {
+	input := "Title,Platform,Playing,Backlog,Completed,Retired,Progress,Main Story,Main + Extras,Completionist\n" +
+		"Hades,PC,X,,X,,25:30,,22h,\n" +
+		"Celeste,Nintendo Switch,X,,,,2:15:40,8,,\n" +
+		"Tunic,PC,,X,,,,0,,25\n" +
+		"Anthem,PC,,,,X,,,,\n" +
+		"Broken,PC,,X,,,,??,,\n"
+	checkRecords(t, NewHowLongToBeatImporter(), input, []wantRecord{
+		{title: "Hades", platform: "PC", status: game.StatusCompleted, playedMinutes: 25*60 + 30, estimated: 22},
+		{title: "Celeste", platform: "Nintendo Switch", status: game.StatusPlaying, playedMinutes: 136, estimated: 8},
+		{title: "Tunic", platform: "PC", status: game.StatusUnstarted, estimated: 25},
+		{title: "Anthem", platform: "PC", status: game.StatusDropped},
+		{title: "Broken", platform: "PC", status: game.StatusUnstarted, problems: 1},
+	})
+}
+
+func TestParseInvalidFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		imp   Importer
+		input string
+	}{
+		{name: "playnite not an array", imp: NewPlayniteImporter(), input: `{"Name": "Hades"}`},
+		{name: "backloggd without name column", imp: NewBackloggdImporter(), input: "Platform,Status\nPC,Playing\n"},
+		{name: "howlongtobeat empty file", imp: NewHowLongToBeatImporter(), input: ""},
+		{name: "howlongtobeat broken quote", imp: NewHowLongToBeatImporter(), input: "Title\n\"Hades\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.imp.Parse(strings.NewReader(tt.input))
+			if !errors.Is(err, ErrInvalidFile) {
+				t.Errorf("err = %v, want ErrInvalidFile", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package importer
+
+import (
+	"io"
+
+	"TO-DO-IT/internal/game"
+)
+
+// Importer は、他サービスの積みゲー管理データを読み取るパーサーの共通インターフェースです。
+// 新しい形式に対応するときは、これを実装して Registry に登録します。
+type Importer interface {
+	// Source は、POST /api/import/:source の :source に使う名前です（例: "playnite"）。
+	Source() string
+	// Format は、受け付けるファイル形式です（"csv" か "json"）。
+	Format() string
+	// Parse はファイルを読み、1件ずつ CreateGameRequest に変換します。
+	// ファイル全体が読めない場合だけエラーを返し、行ごとの問題は Record.Problems に入れます。
+	Parse(r io.Reader) ([]Record, error)
+}
+
+// Record は、インポートファイルの1件分を変換した結果です。
+type Record struct {
+	Request  *game.CreateGameRequest
+	Problems []string // 変換時に見つかった問題（空なら登録できる）
+	Skip     string   // 登録対象外の理由（ウィッシュリストなど）。空なら対象
+}
+
+// SourceInfo は、対応しているインポート元の情報です（GET /api/import/sources）。
+type SourceInfo struct {
+	Source string `json:"source"`
+	Format string `json:"format"` // csv か json
+}
@@ -0,0 +1,159 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// csvFile は、ヘッダー付き CSV を列名で引けるようにしたものです。
+type csvFile struct {
+	header map[string]int // 正規化した列名 → 列番号
+	rows   [][]string
+}
+
+// readCSV は CSV を読み込みます。列名は normalizeKey で正規化します。
+func readCSV(r io.Reader) (*csvFile, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	// Excel で保存した CSV の BOM を取り除く
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: missing header row", ErrInvalidFile)
+	}
+
+	f := &csvFile{header: make(map[string]int, len(records[0])), rows: records[1:]}
+	for i, h := range records[0] {
+		f.header[normalizeKey(h)] = i
+	}
+	return f, nil
+}
+
+// value は、row の中から候補の列名のうち最初に見つかった列の値を返します。
+func (f *csvFile) value(row []string, keys ...string) string {
+	for _, key := range keys {
+		i, ok := f.header[normalizeKey(key)]
+		if ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+	}
+	return ""
+}
+
+// has は、候補の列名のいずれかがヘッダーにあるかを返します。
+func (f *csvFile) has(keys ...string) bool {
+	for _, key := range keys {
+		if _, ok := f.header[normalizeKey(key)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeKey は、列名やステータス名を比較用に正規化します（小文字・英数字のみ）。
+// "Main + Extras" と "main_extras" を同じものとして扱うためです。
+func normalizeKey(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// isChecked は、"X" や "1" などのチェック欄に値が入っているかを返します。
+func isChecked(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", "0", "false", "no", "n":
+		return false
+	}
+	return true
+}
+
+var hoursMinutesPattern = regexp.MustCompile(`^(?:(\d+(?:\.\d+)?)\s*h)?\s*(?:(\d+)\s*m)?$`)
+
+// parseMinutes は、プレイ時間の表記を分に変換します。
+// "12:34:56"（時:分:秒）、"12:34"（時:分）、"12h 30m"、"12.5"（時間）に対応します。
+func parseMinutes(v string) (int, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if v == "" {
+		return 0, nil
+	}
+
+	if strings.Contains(v, ":") {
+		parts := strings.Split(v, ":")
+		if len(parts) > 3 {
+			return 0, fmt.Errorf("invalid duration %q", v)
+		}
+		nums := make([]int, len(parts))
+		for i, p := range parts {
+			n, err := strconv.Atoi(p)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid duration %q", v)
+			}
+			nums[i] = n
+		}
+		minutes := nums[0]*60 + nums[1]
+		if len(nums) == 3 && nums[2] >= 30 {
+			minutes++ // 秒は四捨五入
+		}
+		return minutes, nil
+	}
+
+	if m := hoursMinutesPattern.FindStringSubmatch(v); m != nil && (m[1] != "" || m[2] != "") {
+		minutes := 0
+		if m[1] != "" {
+			h, _ := strconv.ParseFloat(m[1], 64)
+			minutes += int(h*60 + 0.5)
+		}
+		if m[2] != "" {
+			n, _ := strconv.Atoi(m[2])
+			minutes += n
+		}
+		return minutes, nil
+	}
+
+	h, err := strconv.ParseFloat(v, 64)
+	if err != nil || h < 0 {
+		return 0, fmt.Errorf("invalid duration %q", v)
+	}
+	return int(h*60 + 0.5), nil
+}
+
+// dateLayouts は、インポート元で使われている日付の形式です。
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02",
+	"2006/01/02",
+	"01/02/2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2006",
+}
+
+// parseDate は、いずれかの形式の日付を読み取ります。
+func parseDate(v string) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", v)
+}
@@ -0,0 +1,99 @@
+package importer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMinutes(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{in: "", want: 0},
+		{in: "12:34", want: 12*60 + 34},
+		{in: "1:30:29", want: 90},
+		{in: "1:30:30", want: 91}, // 秒は四捨五入
+		{in: "12h 30m", want: 750},
+		{in: "12H30M", want: 750},
+		{in: "1.5h", want: 90},
+		{in: "45m", want: 45},
+		{in: "12.5", want: 750},
+		{in: " 3 ", want: 180},
+		{in: "0", want: 0},
+		{in: "1:2:3:4", wantErr: true},
+		{in: "1:xx", wantErr: true},
+		{in: "5:", wantErr: true},
+		{in: "-1", wantErr: true},
+		{in: "about 3 hours", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseMinutes(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseMinutes(%q) = %d, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMinutes(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseMinutes(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	want := time.Date(2021, time.March, 14, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "2021-03-14", want: want},
+		{in: "2021/03/14", want: want},
+		{in: "03/14/2021", want: want},
+		{in: "Mar 14, 2021", want: want},
+		{in: "March 14, 2021", want: want},
+		{in: " 2021-03-14 ", want: want},
+		{in: "2021-03-14T10:30:00Z", want: want.Add(10*time.Hour + 30*time.Minute)},
+		{in: "2021", want: time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{in: "14.03.2021", wantErr: true},
+		{in: "TBA", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseDate(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseDate(%q) = %v, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDate(%q): %v", tt.in, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseDate(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeKey(t *testing.T) {
+	tests := map[string]string{
+		"Main + Extras": "mainextras",
+		"main_extras":   "mainextras",
+		"Play Type":     "playtype",
+		"On Hold":       "onhold",
+	}
+	for in, want := range tests {
+		if got := normalizeKey(in); got != want {
+			t.Errorf("normalizeKey(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"TO-DO-IT/internal/game"
+)
+
+// playniteImporter は、Playnite のライブラリを JSON で書き出したファイルを読み取ります。
+type playniteImporter struct{}
+
+// NewPlayniteImporter は、Playnite 用の Importer を作成します。
+func NewPlayniteImporter() Importer {
+	return &playniteImporter{}
+}
+
+func (p *playniteImporter) Source() string { return "playnite" }
+func (p *playniteImporter) Format() string { return "json" }
+
+// playniteStatuses は、Playnite の CompletionStatus をこのアプリのステータスに対応づけます。
+// キーは normalizeKey 済みの名前です。
+var playniteStatuses = map[string]string{
+	"notplayed":  game.StatusUnstarted,
+	"plantoplay": game.StatusUnstarted,
+	"playing":    game.StatusPlaying,
+	"played":     game.StatusPaused,
+	"onhold":     game.StatusPaused,
+	"beaten":     game.StatusCompleted,
+	"completed":  game.StatusCompleted,
+	"abandoned":  game.StatusDropped,
+}
+
+// playniteName は、Playnite のエクスポートで文字列と {"Name": ...} のどちらでも来る値です。
+type playniteName string
+
+func (n *playniteName) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*n = playniteName(s)
+		return nil
+	}
+	var obj struct {
+		Name string `json:"Name"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*n = playniteName(obj.Name)
+	return nil
+}
+
+// playniteDate は、文字列と {"ReleaseDate": ...} のどちらでも来る発売日です。
+type playniteDate string
+
+func (d *playniteDate) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*d = playniteDate(s)
+		return nil
+	}
+	var obj struct {
+		ReleaseDate string `json:"ReleaseDate"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*d = playniteDate(obj.ReleaseDate)
+	return nil
+}
+
+// playniteGame は、Playnite のゲーム1件のうち取り込みに使う項目です。
+type playniteGame struct {
+	Name             string         `json:"Name"`
+	Platforms        []playniteName `json:"Platforms"`
+	Genres           []playniteName `json:"Genres"`
+	Source           *playniteName  `json:"Source"`
+	CompletionStatus *playniteName  `json:"CompletionStatus"`
+	Playtime         int64          `json:"Playtime"` // 秒
+	ReleaseDate      *playniteDate  `json:"ReleaseDate"`
+	Hidden           bool           `json:"Hidden"`
+}
+
+// Parse は Playnite の JSON（ゲームの配列）を読み取ります。
+func (p *playniteImporter) Parse(r io.Reader) ([]Record, error) {
+	var items []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	records := make([]Record, 0, len(items))
+	for _, item := range items {
+		var g playniteGame
+		if err := json.Unmarshal(item, &g); err != nil {
+			records = append(records, Record{
+				Request:  &game.CreateGameRequest{},
+				Problems: []string{fmt.Sprintf("invalid entry: %v", err)},
+			})
+			continue
+		}
+		records = append(records, p.toRecord(&g))
+	}
+	return records, nil
+}
+
+func (p *playniteImporter) toRecord(g *playniteGame) Record {
+	req := &game.CreateGameRequest{
+		Title:         strings.TrimSpace(g.Name),
+		PlayedMinutes: int(g.Playtime / 60),
+	}
+	rec := Record{Request: req}
+
+	if g.Hidden {
+		rec.Skip = "hidden in Playnite"
+	}
+	if req.Title == "" {
+		rec.Problems = append(rec.Problems, "Name: required")
+	}
+
+	// プラットフォームが無ければライブラリの入手元（Steam など）を使う
+	if len(g.Platforms) > 0 {
+		req.Platform = string(g.Platforms[0])
+	} else if g.Source != nil {
+		req.Platform = string(*g.Source)
+	}
+	if len(g.Genres) > 0 {
+		req.Genre = string(g.Genres[0])
+	}
+
+	req.Status = game.StatusUnstarted
+	if g.CompletionStatus != nil && *g.CompletionStatus != "" {
+		status, ok := playniteStatuses[normalizeKey(string(*g.CompletionStatus))]
+		if !ok {
+			rec.Problems = append(rec.Problems, fmt.Sprintf("CompletionStatus: unknown status %q", *g.CompletionStatus))
+		}
+		req.Status = status
+	} else if g.Playtime > 0 {
+		req.Status = game.StatusPaused
+	}
+
+	if g.ReleaseDate != nil && *g.ReleaseDate != "" {
+		t, err := parseDate(string(*g.ReleaseDate))
+		if err != nil {
+			rec.Problems = append(rec.Problems, "ReleaseDate: "+err.Error())
+		}
+		req.ReleaseDate = t
+	}
+
+	return rec
+}
@@ -0,0 +1,46 @@
+package importer
+
+import "sort"
+
+// Registry は、インポート元の名前から Importer を引くための登録簿です。
+type Registry struct {
+	importers map[string]Importer
+}
+
+// NewRegistry は、渡された Importer を登録した Registry を作成します。
+func NewRegistry(importers ...Importer) *Registry {
+	r := &Registry{importers: make(map[string]Importer, len(importers))}
+	for _, imp := range importers {
+		r.Register(imp)
+	}
+	return r
+}
+
+// DefaultRegistry は、標準で対応しているインポート元をすべて登録した Registry を返します。
+func DefaultRegistry() *Registry {
+	return NewRegistry(
+		NewPlayniteImporter(),
+		NewBackloggdImporter(),
+		NewHowLongToBeatImporter(),
+	)
+}
+
+// Register は Importer を登録します。同じ名前のものは上書きします。
+func (r *Registry) Register(imp Importer) {
+	r.importers[imp.Source()] = imp
+}
+
+// Get は、名前に対応する Importer を返します。見つからなければ nil を返します。
+func (r *Registry) Get(source string) Importer {
+	return r.importers[source]
+}
+
+// Sources は、登録されているインポート元の名前を昇順で返します。
+func (r *Registry) Sources() []string {
+	sources := make([]string, 0, len(r.importers))
+	for source := range r.importers {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	return sources
+}
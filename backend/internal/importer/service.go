@@ -0,0 +1,81 @@
+package importer
+
+import (
+	"errors"
+	"io"
+	"log"
+
+	"TO-DO-IT/internal/game"
+)
+
+// サービス層が返すエラー。handler はこれらを見てHTTPステータスを決めます。
+var (
+	ErrUnknownSource = errors.New("unknown import source")
+	ErrInvalidFile   = errors.New("import file could not be read")
+)
+
+// Service は、他サービスからのインポートに関するインターフェースです。
+// どちらも存在しないインポート元の場合は ErrUnknownSource を返します。
+type Service interface {
+	// Preview はファイルを読み取って変換結果を返します。ゲームは作成しません。
+	Preview(source string, r io.Reader) (*game.ImportReport, error)
+	// Commit はファイルを読み取り、問題のない行をゲームとして登録します。
+	Commit(source string, r io.Reader) (*game.ImportReport, error)
+	// Sources は、対応しているインポート元の一覧を返します。
+	Sources() []SourceInfo
+}
+
+// service は Service インターフェースの具体的な実装です。
+type service struct {
+	registry *Registry
+	gameSvc  game.Service // 担当Cのゲームサービス（登録時の初期値や履歴を手動登録とそろえるため）
+}
+
+// NewService は、新しい service インスタンスを作成します。
+func NewService(registry *Registry, gameSvc game.Service) Service {
+	return &service{registry: registry, gameSvc: gameSvc}
+}
+
+// Preview は、インポートした場合の結果を返します。
+func (s *service) Preview(source string, r io.Reader) (*game.ImportReport, error) {
+	return s.run(source, r, true)
+}
+
+// Commit は、問題のない行をゲームとして登録します。
+func (s *service) Commit(source string, r io.Reader) (*game.ImportReport, error) {
+	return s.run(source, r, false)
+}
+
+// Sources は、対応しているインポート元の一覧を返します。
+func (s *service) Sources() []SourceInfo {
+	names := s.registry.Sources()
+	infos := make([]SourceInfo, 0, len(names))
+	for _, name := range names {
+		infos = append(infos, SourceInfo{Source: name, Format: s.registry.Get(name).Format()})
+	}
+	return infos
+}
+
+// run はファイルを変換し、game サービスで検証・登録します（dryRun なら検証のみ）。
+func (s *service) run(source string, r io.Reader, dryRun bool) (*game.ImportReport, error) {
+	imp := s.registry.Get(source)
+	if imp == nil {
+		return nil, ErrUnknownSource
+	}
+
+	records, err := imp.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*game.ImportItem, len(records))
+	for i, rec := range records {
+		items[i] = &game.ImportItem{Request: rec.Request, Problems: rec.Problems, Skip: rec.Skip}
+	}
+	report, err := s.gameSvc.ImportRows(items, dryRun)
+	if err != nil {
+		log.Printf("Service: Error importing from %s: %v", source, err)
+		return nil, err
+	}
+	return report, nil
+}
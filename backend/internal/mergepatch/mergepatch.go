@@ -0,0 +1,61 @@
+// Package mergepatch は、JSON Merge Patch (RFC 7396) のリクエストを扱うための型をまとめたパッケージです。
+//
+// PATCH では「キーがない（変更しない）」「null（値を消す）」「値がある（上書きする）」を
+// 区別する必要があるので、各項目を Field で受け取ります。
+package mergepatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ContentType は、JSON Merge Patch のリクエストの Content-Type です。
+const ContentType = "application/merge-patch+json"
+
+// ErrInvalidBody は、リクエストボディが JSON オブジェクトとして読めなかったときのエラーです。
+var ErrInvalidBody = errors.New("request body must be a JSON object")
+
+// Field は、パッチの1項目です。
+type Field[T any] struct {
+	Set   bool // キーが含まれていた（null の場合も true）
+	Null  bool // 値が null だった（項目を消す）
+	Value T    // Set かつ !Null のときの値
+}
+
+// Value は、値を設定する Field を作ります（PUT の内容をパッチに変換するときなどに使います）。
+func Value[T any](v T) Field[T] {
+	return Field[T]{Set: true, Value: v}
+}
+
+// UnmarshalJSON は、キーがあったことと null かどうかを記録します。
+// encoding/json は値が null のときも UnmarshalJSON を呼ぶので、ここで区別できます。
+func (f *Field[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	if strings.TrimSpace(string(data)) == "null" {
+		f.Null = true
+		var zero T
+		f.Value = zero
+		return nil
+	}
+	f.Null = false
+	return json.Unmarshal(data, &f.Value)
+}
+
+// Decode は、リクエストボディのパッチを patch（Field を持つ構造体へのポインタ）に読み込みます。
+// ボディが JSON オブジェクトでない場合は ErrInvalidBody を包んだエラーを返します。
+func Decode(r io.Reader, patch any) error {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBody, err)
+	}
+	if !strings.HasPrefix(strings.TrimSpace(string(raw)), "{") {
+		return ErrInvalidBody
+	}
+	if err := json.Unmarshal(raw, patch); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBody, err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package mergepatch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testPatch struct {
+	Title    Field[string]  `json:"title"`
+	Rating   Field[int]     `json:"rating"`
+	Deadline Field[*string] `json:"deadline"`
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want testPatch
+	}{
+		{
+			name: "empty object changes nothing",
+			body: `{}`,
+			want: testPatch{},
+		},
+		{
+			name: "values are set",
+			body: `{"title": "Hades", "rating": 5}`,
+			want: testPatch{Title: Value("Hades"), Rating: Value(5)},
+		},
+		{
+			name: "null clears the field",
+			body: `{"rating": null}`,
+			want: testPatch{Rating: Field[int]{Set: true, Null: true}},
+		},
+		{
+			name: "zero value is not null",
+			body: `{"rating": 0, "title": ""}`,
+			want: testPatch{Title: Value(""), Rating: Value(0)},
+		},
+		{
+			name: "unknown keys are ignored",
+			body: ` {"genre": "RPG", "title": "Celeste"} `,
+			want: testPatch{Title: Value("Celeste")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testPatch
+			if err := Decode(strings.NewReader(tt.body), &got); err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if got.Title != tt.want.Title || got.Rating != tt.want.Rating || got.Deadline.Set != tt.want.Deadline.Set {
+				t.Errorf("Decode(%s) = %+v, want %+v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodePointerField(t *testing.T) {
+	var p testPatch
+	if err := Decode(strings.NewReader(`{"deadline": "2026-12-31"}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Deadline.Set || p.Deadline.Null || p.Deadline.Value == nil || *p.Deadline.Value != "2026-12-31" {
+		t.Errorf("deadline = %+v, want 2026-12-31", p.Deadline)
+	}
+
+	p = testPatch{}
+	if err := Decode(strings.NewReader(`{"deadline": null}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Deadline.Set || !p.Deadline.Null || p.Deadline.Value != nil {
+		t.Errorf("deadline = %+v, want null", p.Deadline)
+	}
+}
+
+func TestDecodeInvalidBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "empty body", body: ``},
+		{name: "array", body: `[{"title": "Hades"}]`},
+		{name: "null", body: `null`},
+		{name: "string", body: `"Hades"`},
+		{name: "broken json", body: `{"title": `},
+		{name: "wrong type", body: `{"rating": "five"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p testPatch
+			if err := Decode(strings.NewReader(tt.body), &p); !errors.Is(err, ErrInvalidBody) {
+				t.Errorf("Decode(%q) = %v, want ErrInvalidBody", tt.body, err)
+			}
+		})
+	}
+}
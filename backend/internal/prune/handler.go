@@ -0,0 +1,77 @@
+package prune
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"TO-DO-IT/internal/game"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(s Service) *Handler {
+	return &Handler{service: s}
+}
+
+// RegisterRoutes ... EchoルーターにAPIエンドポイントを登録
+// /api/games/:id より静的なパス・長いパスなので、game パッケージのルートとぶつからない
+func (h *Handler) RegisterRoutes(api *echo.Group) {
+	api.GET("/games/drop-candidates", h.handleCandidates) // GET /api/games/drop-candidates
+	api.POST("/games/:id/drop", h.handleDrop)             // POST /api/games/:id/drop {"reason": "..."} (省略可)。If-Match が必要
+}
+
+// handleCandidates ... やめる候補を理由つきで返す
+func (h *Handler) handleCandidates(c echo.Context) error {
+	userID := "user_123" // 仮
+
+	res, err := h.service.Candidates(userID)
+	if err != nil {
+		log.Printf("Prune: Error finding drop candidates: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to find drop candidates"})
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// handleDrop ... ゲームを「やめた」にし、未実施の予定を取り消す
+// ゲームの更新と同じく If-Match が必要 (ないと 428、版が合わないと 412)
+func (h *Handler) handleDrop(c echo.Context) error {
+	userID := "user_123" // 仮
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid game id"})
+	}
+	version, err := game.GetIfMatchVersion(c)
+	if err != nil {
+		return err
+	}
+	var req DropRequest
+	if c.Request().ContentLength > 0 {
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		}
+	}
+
+	res, err := h.service.Drop(userID, id, version, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrNotDroppable), errors.Is(err, game.ErrInvalidTransition):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		case errors.Is(err, game.ErrVersionMismatch):
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Prune: Error dropping game: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to drop game"})
+	}
+	if res == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Game not found"})
+	}
+	game.SetETag(c, res.Game)
+	return c.JSON(http.StatusOK, res)
+}
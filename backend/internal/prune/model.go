@@ -0,0 +1,94 @@
+package prune
+
+import (
+	"time"
+
+	"TO-DO-IT/internal/game"
+)
+
+// 理由 (Reason.Code)
+const (
+	ReasonLowEnjoyment  = "low_enjoyment"  // 日記の楽しさの評価が低い
+	ReasonKeepsSkipping = "keeps_skipping" // 予定をスキップ・放置し続けている
+	ReasonStalled       = "stalled"        // 長い間進んでいない (未開始なら、登録してから手を付けていない)
+)
+
+// 判定の基準
+const (
+	// RatedEntries ... 楽しさを見る、直近に評価した日記の数
+	RatedEntries = 5
+	// MinRatedEntries ... 楽しさで判定するのに必要な、評価した日記の数
+	MinRatedEntries = 2
+	// LowEnjoyment ... 楽しさの平均がこれ以下なら低い (1〜5)
+	LowEnjoyment = 2.0
+	// SkipStreak ... 直近の予定がこの回数続けてスキップ・放置されたら、スキップし続けている
+	SkipStreak = 3
+	// StalledDays / UntouchedDays ... 遊び始めたゲーム・未開始のゲームが、この日数進んでいなければ止まっている
+	// この2倍を超えたら、それだけでやめる候補になる
+	StalledDays   = 60
+	UntouchedDays = 180
+	// FlagWeight ... 理由の重みの合計がこれ以上なら、やめる候補にする
+	FlagWeight = 2
+)
+
+// Reason ... やめる候補にした理由
+type Reason struct {
+	Code   string `json:"code"`
+	Weight int    `json:"weight"` // 1 (弱い) か 2 (強い)
+	Detail string `json:"detail"` // 説明 (例: "skipped the last 4 scheduled sessions")
+}
+
+// Signals ... 判定に使った値
+type Signals struct {
+	RatedEntries     int      `json:"rated_entries"`     // 楽しさを評価した直近の日記の数 (RatedEntries 件まで)
+	AverageEnjoyment *float64 `json:"average_enjoyment"` // その平均。評価がなければ null
+	// ConsecutiveSkips ... 終わった予定のうち、最新から続けてスキップ・放置された数
+	ConsecutiveSkips int `json:"consecutive_skips"`
+	Skipped          int `json:"skipped"` // 見た期間にスキップした予定の数
+	Missed           int `json:"missed"`  // 見た期間に、完了もスキップもされずに過ぎた予定の数
+	// LastProgressAt ... 最後に進んだ日時 (プレイセッション・完了した予定・日記・プレイ開始)。なければ null
+	LastProgressAt    *time.Time `json:"last_progress_at"`
+	DaysSinceProgress int        `json:"days_since_progress"` // 進んでいなければ、登録してからの日数
+}
+
+// GameSummary ... 候補のゲームの概要
+type GameSummary struct {
+	ID                int    `json:"id"`
+	Title             string `json:"title"`
+	Platform          string `json:"platform"`
+	Genre             string `json:"genre"`
+	Status            string `json:"status"`
+	Priority          int    `json:"priority"`
+	PlayedMinutes     int    `json:"played_minutes"`
+	CoverThumbnailURL string `json:"cover_thumbnail_url"`
+}
+
+// Candidate ... やめる候補の1本
+type Candidate struct {
+	Game    *GameSummary `json:"game"`
+	Weight  int          `json:"weight"`  // 理由の重みの合計
+	Reasons []*Reason    `json:"reasons"` // 重い順
+	Signals *Signals     `json:"signals"`
+}
+
+// Candidates ... GET /api/games/drop-candidates のレスポンス
+type Candidates struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	// Checked ... 判定したゲームの数 (未開始・プレイ中・中断中のもの)
+	Checked    int          `json:"checked"`
+	Candidates []*Candidate `json:"candidates"` // 重みの合計が大きい順
+}
+
+// DropRequest ... POST /api/games/:id/drop のリクエストボディ (省略可)
+type DropRequest struct {
+	Reason string `json:"reason"` // ステータス変更の理由 (履歴に記録)。省略時は DefaultDropReason
+}
+
+// DefaultDropReason ... やめる候補から「やめる」にしたときの、ステータス変更の理由
+const DefaultDropReason = "dropped from backlog pruning suggestions"
+
+// DropResult ... POST /api/games/:id/drop のレスポンス
+type DropResult struct {
+	Game               *game.Game `json:"game"`
+	CancelledSchedules int64      `json:"cancelled_schedules"` // 取り消した未実施の予定の数
+}
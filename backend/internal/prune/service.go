@@ -0,0 +1,310 @@
+package prune
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"TO-DO-IT/internal/calendar"
+	"TO-DO-IT/internal/game"
+	"TO-DO-IT/internal/task"
+)
+
+var (
+	ErrNotDroppable = errors.New("only unstarted, playing or paused games can be dropped")
+)
+
+// Service ... 楽しめていない・進んでいないゲームを「やめる候補」として挙げる
+type Service interface {
+	// Candidates ... 未開始・プレイ中・中断中のゲームを判定し、やめる候補を返す
+	Candidates(userID string) (*Candidates, error)
+	// Drop ... ゲームを「やめた」にし、未実施の予定を取り消す。ゲームがなければ nil
+	// version は If-Match の版 (0 なら確認しない)。合わなければ game.ErrVersionMismatch
+	Drop(userID string, gameID int, version int, req *DropRequest) (*DropResult, error)
+	// calendar.DropCandidateProvider の実装 (スケジュールの自動生成で後回しにする)
+	DropCandidateIDs(userID string) (map[int]bool, error)
+}
+
+// service (実装)
+type service struct {
+	gameRepo     game.Repository     // 担当Cのゲームリポジトリ
+	gameSvc      game.Service        // ステータス変更 (履歴つき) に使う
+	calendarRepo calendar.Repository // スキップ・放置した予定の数と、予定の取り消しに使う
+	taskRepo     task.Repository     // 日記の楽しさと、最後に遊んだ日時
+}
+
+// NewService ... 必要なリポジトリ・サービスを受け取り、サービスを初期化
+func NewService(gameRepo game.Repository, gameSvc game.Service, calendarRepo calendar.Repository, taskRepo task.Repository) Service {
+	return &service{gameRepo: gameRepo, gameSvc: gameSvc, calendarRepo: calendarRepo, taskRepo: taskRepo}
+}
+
+// gameUserID ... game パッケージ側の仮ユーザーID (calendar の GenerateSchedule と同じ固定値)
+const gameUserID = 1
+
+// scheduleLookbackDays ... スキップ・放置した予定を探す期間
+const scheduleLookbackDays = 365
+
+// activity ... ゲームごとに集めた記録
+type activity struct {
+	ratings      []int     // 直近に評価した日記の楽しさ (新しい順、RatedEntries 件まで)
+	outcomes     []string  // 終わった予定の結果 (新しい順。放置した予定は "missed")
+	lastProgress time.Time // 最後に進んだ日時。なければゼロ値
+}
+
+// outcomeMissed ... 完了もスキップもされずに終了時刻を過ぎた予定
+const outcomeMissed = "missed"
+
+// Candidates ... 記録を集めて、ゲームごとに判定する
+func (s *service) Candidates(userID string) (*Candidates, error) {
+	now := time.Now()
+	games, err := s.activeGames()
+	if err != nil {
+		return nil, err
+	}
+	acts, err := s.loadActivity(userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Candidates{GeneratedAt: now, Checked: len(games), Candidates: []*Candidate{}}
+	for _, g := range games {
+		if c := judge(g, acts[g.ID], now); c.Weight >= FlagWeight {
+			res.Candidates = append(res.Candidates, c)
+		}
+	}
+	sort.SliceStable(res.Candidates, func(i, j int) bool {
+		if res.Candidates[i].Weight != res.Candidates[j].Weight {
+			return res.Candidates[i].Weight > res.Candidates[j].Weight
+		}
+		return res.Candidates[i].Signals.DaysSinceProgress > res.Candidates[j].Signals.DaysSinceProgress
+	})
+	return res, nil
+}
+
+// DropCandidateIDs ... やめる候補のゲームIDの集合
+func (s *service) DropCandidateIDs(userID string) (map[int]bool, error) {
+	res, err := s.Candidates(userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[int]bool, len(res.Candidates))
+	for _, c := range res.Candidates {
+		ids[c.Game.ID] = true
+	}
+	return ids, nil
+}
+
+// Drop ... 予定の取り消しとステータスの変更を、同じトランザクションで行う
+func (s *service) Drop(userID string, gameID int, version int, req *DropRequest) (*DropResult, error) {
+	g, err := s.gameRepo.GetGameByID(gameID)
+	if err != nil || g == nil {
+		return nil, err
+	}
+	if !isActive(g.Status) {
+		return nil, ErrNotDroppable
+	}
+	reason := req.Reason
+	if reason == "" {
+		reason = DefaultDropReason
+	}
+
+	res := &DropResult{}
+	err = s.calendarRepo.RunInTx(func(tx *sql.Tx) error {
+		// ステータスの変更でも calendar が取り消すが、件数を返すために先に取り消しておく
+		n, err := s.calendarRepo.WithTx(tx).CancelPendingSchedulesByGameID(strconv.Itoa(gameID))
+		if err != nil {
+			return err
+		}
+		res.CancelledSchedules = n
+		res.Game, _, err = s.gameSvc.ChangeStatusInTx(tx, gameID, version, game.StatusDropped, reason)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// activeGames ... 判定するゲーム (未開始・プレイ中・中断中)
+func (s *service) activeGames() ([]*game.Game, error) {
+	games, err := s.gameRepo.GetGamesByUserID(gameUserID)
+	if err != nil {
+		return nil, err
+	}
+	var active []*game.Game
+	for _, g := range games {
+		if isActive(g.Status) {
+			active = append(active, g)
+		}
+	}
+	return active, nil
+}
+
+func isActive(status string) bool {
+	return status == game.StatusUnstarted || status == game.StatusPlaying || status == game.StatusPaused
+}
+
+// loadActivity ... 日記・予定・プレイセッション・ステータス変更履歴を、ゲームごとにまとめる
+func (s *service) loadActivity(userID string, now time.Time) (map[int]*activity, error) {
+	acts := map[int]*activity{}
+	get := func(gameID int) *activity {
+		if acts[gameID] == nil {
+			acts[gameID] = &activity{}
+		}
+		return acts[gameID]
+	}
+	touch := func(gameID int, t time.Time) {
+		if a := get(gameID); t.After(a.lastProgress) {
+			a.lastProgress = t
+		}
+	}
+
+	// 日記 (新しい順)。書いたこと自体も、遊んで進んだ記録とみなす
+	entries, err := s.taskRepo.GetJournalEntriesByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		touch(e.GameID, e.CreatedAt)
+		if a := get(e.GameID); e.Enjoyment > 0 && len(a.ratings) < RatedEntries {
+			a.ratings = append(a.ratings, e.Enjoyment)
+		}
+	}
+
+	// 予定 (古い順に返るので、後ろから見る)
+	schedules, err := s.calendarRepo.GetSchedulesByUserID(userID, now.AddDate(0, 0, -scheduleLookbackDays), now)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(schedules) - 1; i >= 0; i-- {
+		sc := schedules[i]
+		id, err := strconv.Atoi(sc.GameID)
+		if err != nil || !sc.EndTime.Before(now) {
+			continue
+		}
+		switch sc.Status {
+		case calendar.ScheduleStatusCompleted:
+			touch(id, sc.EndTime)
+			get(id).outcomes = append(get(id).outcomes, sc.Status)
+		case calendar.ScheduleStatusSkipped:
+			get(id).outcomes = append(get(id).outcomes, sc.Status)
+		case calendar.ScheduleStatusPending:
+			get(id).outcomes = append(get(id).outcomes, outcomeMissed)
+		}
+	}
+
+	lastPlayed, err := s.taskRepo.GetLastPlayedTimes(userID)
+	if err != nil {
+		return nil, err
+	}
+	for id, t := range lastPlayed {
+		touch(id, t)
+	}
+
+	// 遊び始めた・再開したのも進んだ記録とみなす (登録や中断は含めない)
+	histories, err := s.gameRepo.GetStatusHistoryByUserID(gameUserID)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range histories {
+		if h.ToStatus == game.StatusPlaying {
+			touch(h.GameID, h.ChangedAt)
+		}
+	}
+	return acts, nil
+}
+
+// judge ... ゲームの記録から理由を挙げ、重みを合計する
+func judge(g *game.Game, a *activity, now time.Time) *Candidate {
+	if a == nil {
+		a = &activity{}
+	}
+	sig := &Signals{RatedEntries: len(a.ratings)}
+	var reasons []*Reason
+
+	// 1. 楽しさ
+	if len(a.ratings) > 0 {
+		sum := 0
+		for _, r := range a.ratings {
+			sum += r
+		}
+		avg := math.Round(float64(sum)/float64(len(a.ratings))*100) / 100
+		sig.AverageEnjoyment = &avg
+		if len(a.ratings) >= MinRatedEntries && avg <= LowEnjoyment {
+			reasons = append(reasons, &Reason{
+				Code: ReasonLowEnjoyment, Weight: 2,
+				Detail: fmt.Sprintf("average enjoyment %.1f/5 over the last %d rated sessions", avg, len(a.ratings)),
+			})
+		}
+	}
+
+	// 2. スキップ・放置
+	for _, o := range a.outcomes {
+		switch o {
+		case calendar.ScheduleStatusSkipped:
+			sig.Skipped++
+		case outcomeMissed:
+			sig.Missed++
+		}
+	}
+	for _, o := range a.outcomes {
+		if o == calendar.ScheduleStatusCompleted {
+			break
+		}
+		sig.ConsecutiveSkips++
+	}
+	if sig.ConsecutiveSkips >= SkipStreak {
+		reasons = append(reasons, &Reason{
+			Code: ReasonKeepsSkipping, Weight: 2,
+			Detail: fmt.Sprintf("skipped or missed the last %d scheduled sessions", sig.ConsecutiveSkips),
+		})
+	}
+
+	// 3. 進んでいない日数 (一度も進んでいなければ、登録してから)
+	since, limit := g.CreatedAt, UntouchedDays
+	if !a.lastProgress.IsZero() {
+		t := a.lastProgress
+		sig.LastProgressAt = &t
+		since = t
+	}
+	if g.Status != game.StatusUnstarted {
+		limit = StalledDays
+	}
+	sig.DaysSinceProgress = int(now.Sub(since).Hours() / 24)
+	if sig.DaysSinceProgress >= limit {
+		weight := 1
+		if sig.DaysSinceProgress >= 2*limit {
+			weight = 2
+		}
+		detail := fmt.Sprintf("no progress for %d days", sig.DaysSinceProgress)
+		if sig.LastProgressAt == nil {
+			detail = fmt.Sprintf("not touched for %d days since it was added", sig.DaysSinceProgress)
+		}
+		reasons = append(reasons, &Reason{Code: ReasonStalled, Weight: weight, Detail: detail})
+	}
+
+	c := &Candidate{
+		Game: &GameSummary{
+			ID:                g.ID,
+			Title:             g.Title,
+			Platform:          g.Platform,
+			Genre:             g.Genre,
+			Status:            g.Status,
+			Priority:          g.Priority,
+			PlayedMinutes:     g.PlayedMinutes,
+			CoverThumbnailURL: g.CoverThumbnailURL,
+		},
+		Reasons: []*Reason{},
+		Signals: sig,
+	}
+	sort.SliceStable(reasons, func(i, j int) bool { return reasons[i].Weight > reasons[j].Weight })
+	for _, r := range reasons {
+		c.Weight += r.Weight
+		c.Reasons = append(c.Reasons, r)
+	}
+	return c
+}
@@ -0,0 +1,157 @@
+package prune
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"TO-DO-IT/internal/calendar"
+	"TO-DO-IT/internal/game"
+)
+
+func TestJudge(t *testing.T) {
+	now := time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(d int) time.Time { return now.AddDate(0, 0, -d) }
+	const (
+		done    = calendar.ScheduleStatusCompleted
+		skipped = calendar.ScheduleStatusSkipped
+		missed  = outcomeMissed
+	)
+	type want struct {
+		codes            []string
+		weight           int
+		consecutiveSkips int
+		daysSince        int
+	}
+	tests := []struct {
+		name    string
+		status  string
+		created int // 登録してからの日数
+		act     *activity
+		want    want
+	}{
+		{
+			name:    "no activity",
+			status:  game.StatusPlaying,
+			created: 10,
+			want:    want{codes: []string{}, daysSince: 10},
+		},
+		{
+			name:    "low enjoyment",
+			status:  game.StatusPlaying,
+			created: 10,
+			act:     &activity{ratings: []int{2, 1}, lastProgress: daysAgo(1)},
+			want:    want{codes: []string{ReasonLowEnjoyment}, weight: 2, daysSince: 1},
+		},
+		{
+			name:    "enjoyment at the limit is low",
+			status:  game.StatusPlaying,
+			created: 10,
+			act:     &activity{ratings: []int{3, 1}, lastProgress: daysAgo(1)},
+			want:    want{codes: []string{ReasonLowEnjoyment}, weight: 2, daysSince: 1},
+		},
+		{
+			name:    "one rating is not enough",
+			status:  game.StatusPlaying,
+			created: 10,
+			act:     &activity{ratings: []int{1}, lastProgress: daysAgo(1)},
+			want:    want{codes: []string{}, daysSince: 1},
+		},
+		{
+			name:    "skipped and missed in a row",
+			status:  game.StatusPaused,
+			created: 10,
+			act:     &activity{outcomes: []string{skipped, missed, skipped, done}, lastProgress: daysAgo(5)},
+			want:    want{codes: []string{ReasonKeepsSkipping}, weight: 2, consecutiveSkips: 3, daysSince: 5},
+		},
+		{
+			name:    "completed session breaks the streak",
+			status:  game.StatusPaused,
+			created: 10,
+			act:     &activity{outcomes: []string{skipped, missed, done, skipped, skipped}, lastProgress: daysAgo(5)},
+			want:    want{codes: []string{}, consecutiveSkips: 2, daysSince: 5},
+		},
+		{
+			name:    "stalled",
+			status:  game.StatusPlaying,
+			created: 300,
+			act:     &activity{lastProgress: daysAgo(StalledDays)},
+			want:    want{codes: []string{ReasonStalled}, weight: 1, daysSince: StalledDays},
+		},
+		{
+			name:    "stalled for twice the limit",
+			status:  game.StatusPlaying,
+			created: 300,
+			act:     &activity{lastProgress: daysAgo(2 * StalledDays)},
+			want:    want{codes: []string{ReasonStalled}, weight: 2, daysSince: 2 * StalledDays},
+		},
+		{
+			name:    "unstarted game waits longer",
+			status:  game.StatusUnstarted,
+			created: 100,
+			want:    want{codes: []string{}, daysSince: 100},
+		},
+		{
+			name:    "untouched since it was added",
+			status:  game.StatusUnstarted,
+			created: UntouchedDays,
+			want:    want{codes: []string{ReasonStalled}, weight: 1, daysSince: UntouchedDays},
+		},
+		{
+			name:    "reasons are sorted by weight",
+			status:  game.StatusPlaying,
+			created: 300,
+			act:     &activity{ratings: []int{1, 2, 1}, outcomes: []string{missed, missed, missed}, lastProgress: daysAgo(70)},
+			want: want{
+				codes:            []string{ReasonLowEnjoyment, ReasonKeepsSkipping, ReasonStalled},
+				weight:           5,
+				consecutiveSkips: 3,
+				daysSince:        70,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &game.Game{ID: 1, Status: tt.status, CreatedAt: daysAgo(tt.created)}
+			c := judge(g, tt.act, now)
+			codes := []string{}
+			for _, r := range c.Reasons {
+				codes = append(codes, r.Code)
+			}
+			got := want{
+				codes:            codes,
+				weight:           c.Weight,
+				consecutiveSkips: c.Signals.ConsecutiveSkips,
+				daysSince:        c.Signals.DaysSinceProgress,
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("judge() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJudgeSignals(t *testing.T) {
+	now := time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+	progress := now.AddDate(0, 0, -3)
+	a := &activity{
+		ratings:      []int{4, 3, 3},
+		outcomes:     []string{calendar.ScheduleStatusSkipped, outcomeMissed, calendar.ScheduleStatusCompleted, outcomeMissed},
+		lastProgress: progress,
+	}
+	sig := judge(&game.Game{ID: 1, Status: game.StatusPlaying, CreatedAt: now.AddDate(-1, 0, 0)}, a, now).Signals
+
+	if sig.RatedEntries != 3 || sig.AverageEnjoyment == nil || *sig.AverageEnjoyment != 3.33 {
+		t.Errorf("enjoyment = %d entries, average %v; want 3 entries, average 3.33", sig.RatedEntries, sig.AverageEnjoyment)
+	}
+	if sig.Skipped != 1 || sig.Missed != 2 || sig.ConsecutiveSkips != 2 {
+		t.Errorf("skipped %d, missed %d, consecutive %d; want 1, 2, 2", sig.Skipped, sig.Missed, sig.ConsecutiveSkips)
+	}
+	if sig.LastProgressAt == nil || !sig.LastProgressAt.Equal(progress) {
+		t.Errorf("last progress = %v, want %v", sig.LastProgressAt, progress)
+	}
+
+	if sig := judge(&game.Game{ID: 1, Status: game.StatusUnstarted, CreatedAt: now}, nil, now).Signals; sig.AverageEnjoyment != nil || sig.LastProgressAt != nil {
+		t.Errorf("signals without activity = %+v, want no average and no last progress", sig)
+	}
+}
@@ -0,0 +1,63 @@
+package recommend
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(s Service) *Handler {
+	return &Handler{service: s}
+}
+
+// RegisterRoutes ... EchoルーターにAPIエンドポイントを登録
+// /api/games/:id より静的なパスが優先されるので、game パッケージのルートとぶつからない
+func (h *Handler) RegisterRoutes(api *echo.Group) {
+	api.GET("/games/recommend", h.handleRecommend) // GET /api/games/recommend?minutes=45&mode=roulette&seed=42
+}
+
+// handleRecommend ... 今から遊ぶゲームをおすすめする
+// minutes (既定60) / limit (既定3) / mode (top か roulette) / seed (roulette の乱数の種)
+func (h *Handler) handleRecommend(c echo.Context) error {
+	userID := "user_123" // 仮
+
+	opts := Options{Mode: c.QueryParam("mode")}
+	if v := c.QueryParam("minutes"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": ErrInvalidMinutes.Error()})
+		}
+		opts.Minutes = n
+	}
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": ErrInvalidLimit.Error()})
+		}
+		opts.Limit = n
+	}
+	if v := c.QueryParam("seed"); v != "" {
+		seed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "seed must be an integer"})
+		}
+		opts.Seed = &seed
+	}
+
+	rec, err := h.service.Recommend(userID, opts)
+	if err != nil {
+		if errors.Is(err, ErrInvalidMinutes) || errors.Is(err, ErrInvalidLimit) || errors.Is(err, ErrInvalidMode) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Recommend: Error recommending games: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to recommend games"})
+	}
+	return c.JSON(http.StatusOK, rec)
+}
@@ -0,0 +1,88 @@
+package recommend
+
+import "time"
+
+// 選び方
+const (
+	ModeTop      = "top"      // スコアの高い順
+	ModeRoulette = "roulette" // スコアを重みにしたランダム (seed を指定すると同じ結果になる)
+)
+
+// 条件の既定値と上限
+const (
+	DefaultMinutes = 60
+	MaxMinutes     = 24 * 60
+	DefaultLimit   = 3
+	MaxLimit       = 20
+)
+
+// Options ... GET /api/games/recommend の条件
+type Options struct {
+	Minutes int    `json:"minutes"` // 今から遊べる時間 (分)
+	Limit   int    `json:"limit"`   // 何本選ぶか
+	Mode    string `json:"mode"`    // top, roulette
+	// Seed ... roulette の乱数の種。nil なら現在時刻から決め、レスポンスで返す
+	Seed *int64 `json:"seed"`
+}
+
+// 指標 (Factor.Name)
+const (
+	FactorPriority  = "priority"   // 優先度
+	FactorDeadline  = "deadline"   // 期限が近い・過ぎている
+	FactorIdle      = "idle"       // 最後に遊んでから (未開始なら登録してから) の日数
+	FactorLengthFit = "length_fit" // 遊べる時間とセッションの長さが合っているか
+	FactorRating    = "rating"     // 同じジャンルの最近の評価
+	FactorVariety   = "variety"    // 最近遊んだゲームとジャンルが違うか
+)
+
+// factorWeights ... 指標ごとの重み (合計 1)。Score はこれで重み付けした合計を 0〜100 にしたもの
+var factorWeights = map[string]float64{
+	FactorPriority:  0.25,
+	FactorDeadline:  0.20,
+	FactorIdle:      0.15,
+	FactorLengthFit: 0.20,
+	FactorRating:    0.10,
+	FactorVariety:   0.10,
+}
+
+// Factor ... スコアの内訳
+type Factor struct {
+	Name   string  `json:"name"`
+	Value  float64 `json:"value"`  // 0〜1
+	Weight float64 `json:"weight"` // factorWeights の値
+	Detail string  `json:"detail"` // 説明 (例: "deadline in 3 days")
+}
+
+// GameSummary ... おすすめしたゲームの概要
+type GameSummary struct {
+	ID                int     `json:"id"`
+	Title             string  `json:"title"`
+	Platform          string  `json:"platform"`
+	Genre             string  `json:"genre"`
+	Status            string  `json:"status"`
+	Priority          int     `json:"priority"`
+	Deadline          string  `json:"deadline"`
+	RemainingHours    float64 `json:"remaining_hours"` // 推定時間が未設定なら 0
+	CoverThumbnailURL string  `json:"cover_thumbnail_url"`
+}
+
+// Pick ... おすすめの1本
+type Pick struct {
+	Rank  int          `json:"rank"`
+	Game  *GameSummary `json:"game"`
+	Score float64      `json:"score"` // 0〜100
+	// Probability ... roulette で最初の1本に選ばれる確率 (top のときは省略)
+	Probability *float64  `json:"probability,omitempty"`
+	Factors     []*Factor `json:"factors"`
+	// Reasons ... スコアを押し上げた指標の説明 (寄与の大きい順)
+	Reasons []string `json:"reasons"`
+}
+
+// Recommendation ... GET /api/games/recommend のレスポンス
+type Recommendation struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Options     Options   `json:"options"`
+	// Candidates ... 候補にしたゲームの数 (未開始・プレイ中で、本編・前作待ちでも、今日は遊ばない曜日でもないもの)
+	Candidates int     `json:"candidates"`
+	Picks      []*Pick `json:"picks"`
+}
@@ -0,0 +1,413 @@
+package recommend
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"time"
+
+	"TO-DO-IT/internal/calendar"
+	"TO-DO-IT/internal/game"
+	"TO-DO-IT/internal/task"
+)
+
+var (
+	ErrInvalidMinutes = errors.New("minutes must be between 1 and 1440")
+	ErrInvalidLimit   = errors.New("limit must be between 1 and 20")
+	ErrInvalidMode    = errors.New("mode must be top or roulette")
+)
+
+// Service ... 「今なにを遊ぶか」のおすすめ
+type Service interface {
+	// Recommend ... 未開始・プレイ中のゲームを採点し、opts.Mode の方法で opts.Limit 本選ぶ
+	Recommend(userID string, opts Options) (*Recommendation, error)
+}
+
+// service (実装)
+type service struct {
+	gameRepo     game.Repository     // 担当Cのゲームリポジトリ
+	gameSvc      game.Service        // 本編・前作待ちのゲームの判定に使う
+	calendarRepo calendar.Repository // 担当Aのカレンダーリポジトリ (最近のプレイ)
+	taskRepo     task.Repository     // プレイセッション (タイマーで記録した最近のプレイ)
+}
+
+// NewService ... 必要なリポジトリ・サービスを受け取り、サービスを初期化
+func NewService(gameRepo game.Repository, gameSvc game.Service, calendarRepo calendar.Repository, taskRepo task.Repository) Service {
+	return &service{gameRepo: gameRepo, gameSvc: gameSvc, calendarRepo: calendarRepo, taskRepo: taskRepo}
+}
+
+// gameUserID ... game パッケージ側の仮ユーザーID (calendar の GenerateSchedule と同じ固定値)
+const gameUserID = 1
+
+const (
+	// recentSessionDays ... 最後に遊んだ日を探す期間
+	recentSessionDays = 365
+	// varietySessions ... ジャンルの偏りを見る、直近のセッションの数
+	varietySessions = 5
+	// ratedGames ... ジャンルの評価を見る、直近に評価したゲームの数
+	ratedGames = 10
+	// reasonThreshold ... おすすめの理由に挙げる指標の値の下限
+	reasonThreshold = 0.8
+)
+
+// Recommend ... 候補を集めて採点し、選ぶ
+func (s *service) Recommend(userID string, opts Options) (*Recommendation, error) {
+	if opts.Minutes == 0 {
+		opts.Minutes = DefaultMinutes
+	}
+	if opts.Limit == 0 {
+		opts.Limit = DefaultLimit
+	}
+	if opts.Mode == "" {
+		opts.Mode = ModeTop
+	}
+	switch {
+	case opts.Minutes < 0 || opts.Minutes > MaxMinutes:
+		return nil, ErrInvalidMinutes
+	case opts.Limit < 0 || opts.Limit > MaxLimit:
+		return nil, ErrInvalidLimit
+	case opts.Mode != ModeTop && opts.Mode != ModeRoulette:
+		return nil, ErrInvalidMode
+	}
+
+	now := time.Now()
+	games, err := s.gameRepo.GetGamesByUserID(gameUserID)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.gameSvc.BlockedGames()
+	if err != nil {
+		return nil, err
+	}
+	signals, err := s.loadSignals(userID, games, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 候補: 未開始・プレイ中で、本編・前作待ちでも、タグのルールで今日は遊ばない曜日でもないゲーム
+	var picks []*Pick
+	for _, g := range games {
+		if g.Status != game.StatusUnstarted && g.Status != game.StatusPlaying {
+			continue
+		}
+		if len(blocked[g.ID]) > 0 || !g.CanScheduleOn(now.Weekday()) {
+			continue
+		}
+		picks = append(picks, scoreGame(g, opts.Minutes, signals, now))
+	}
+	// 乱数の結果が取得順に左右されないように、ID 順にしておく
+	sort.Slice(picks, func(i, j int) bool { return picks[i].Game.ID < picks[j].Game.ID })
+
+	rec := &Recommendation{GeneratedAt: now, Options: opts, Candidates: len(picks)}
+
+	// 2. 選ぶ
+	if opts.Mode == ModeRoulette {
+		if opts.Seed == nil {
+			seed := now.UnixNano()
+			rec.Options.Seed = &seed
+		}
+		picks = spinRoulette(picks, opts.Limit, *rec.Options.Seed)
+	} else {
+		rec.Options.Seed = nil
+		sort.SliceStable(picks, func(i, j int) bool { return picks[i].Score > picks[j].Score })
+		if len(picks) > opts.Limit {
+			picks = picks[:opts.Limit]
+		}
+	}
+	for i, p := range picks {
+		p.Rank = i + 1
+	}
+	rec.Picks = picks
+	if rec.Picks == nil {
+		rec.Picks = []*Pick{}
+	}
+	return rec, nil
+}
+
+// signals ... 採点に使う、ゲーム以外から集めた情報
+type signals struct {
+	lastActivity  map[int]time.Time  // ゲームごとの最後の活動 (遊んだ・ステータスを変えた)
+	played        map[int]bool       // 一度でも遊んだ (完了したセッションがある) か
+	recentGenres  []string           // 直近のセッションのゲームのジャンル (新しい順)
+	genreRatings  map[string]float64 // 直近に評価したゲームの、ジャンルごとの平均評価
+	genreRatedCnt map[string]int     // genreRatings の平均に使ったゲームの数
+}
+
+// loadSignals ... 最近のセッション・プレイ記録・ステータス変更履歴・評価を集める
+func (s *service) loadSignals(userID string, games []*game.Game, now time.Time) (*signals, error) {
+	sig := &signals{
+		lastActivity:  map[int]time.Time{},
+		played:        map[int]bool{},
+		genreRatings:  map[string]float64{},
+		genreRatedCnt: map[string]int{},
+	}
+	byID := make(map[int]*game.Game, len(games))
+	for _, g := range games {
+		byID[g.ID] = g
+	}
+	touch := func(gameID int, t time.Time) {
+		if t.After(sig.lastActivity[gameID]) {
+			sig.lastActivity[gameID] = t
+		}
+	}
+
+	sessions, err := s.calendarRepo.GetRecentSchedulesByStatus(userID, calendar.ScheduleStatusCompleted, now.AddDate(0, 0, -recentSessionDays))
+	if err != nil {
+		return nil, err
+	}
+	for _, sc := range sessions {
+		id, err := strconv.Atoi(sc.GameID)
+		if err != nil {
+			continue
+		}
+		touch(id, sc.EndTime)
+		sig.played[id] = true
+		if g := byID[id]; g != nil && len(sig.recentGenres) < varietySessions {
+			sig.recentGenres = append(sig.recentGenres, g.Genre)
+		}
+	}
+
+	// スケジュールなしでタイマーだけ使って遊んだゲームも、遊んだことにする
+	lastPlayed, err := s.taskRepo.GetLastPlayedTimes(userID)
+	if err != nil {
+		return nil, err
+	}
+	for id, t := range lastPlayed {
+		touch(id, t)
+		sig.played[id] = true
+	}
+
+	histories, err := s.gameRepo.GetStatusHistoryByUserID(gameUserID)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range histories {
+		if h.FromStatus != "" { // 登録時の記録は活動に数えない
+			touch(h.GameID, h.ChangedAt)
+		}
+	}
+
+	// 直近に評価したゲームのジャンルごとの平均
+	var rated []*game.Game
+	for _, g := range games {
+		if g.Rating > 0 && g.CompletedAt != nil && g.Genre != "" {
+			rated = append(rated, g)
+		}
+	}
+	sort.Slice(rated, func(i, j int) bool { return rated[i].CompletedAt.After(*rated[j].CompletedAt) })
+	if len(rated) > ratedGames {
+		rated = rated[:ratedGames]
+	}
+	for _, g := range rated {
+		sig.genreRatings[g.Genre] += float64(g.Rating)
+		sig.genreRatedCnt[g.Genre]++
+	}
+	for genre, n := range sig.genreRatedCnt {
+		sig.genreRatings[genre] /= float64(n)
+	}
+	return sig, nil
+}
+
+// scoreGame ... 指標ごとに 0〜1 で採点し、重み付けした合計を 0〜100 のスコアにする
+func scoreGame(g *game.Game, minutes int, sig *signals, now time.Time) *Pick {
+	remaining := math.Max(g.EstimatedHours-float64(g.PlayedMinutes)/60, 0)
+	factors := []*Factor{
+		priorityFactor(g),
+		deadlineFactor(g, now),
+		idleFactor(g, sig, now),
+		lengthFitFactor(g, remaining, minutes),
+		ratingFactor(g, sig),
+		varietyFactor(g, sig),
+	}
+
+	score := 0.0
+	for _, f := range factors {
+		f.Weight = factorWeights[f.Name]
+		score += f.Weight * f.Value
+	}
+
+	return &Pick{
+		Game: &GameSummary{
+			ID:                g.ID,
+			Title:             g.Title,
+			Platform:          g.Platform,
+			Genre:             g.Genre,
+			Status:            g.Status,
+			Priority:          g.Priority,
+			Deadline:          g.Deadline,
+			RemainingHours:    math.Round(remaining*100) / 100,
+			CoverThumbnailURL: g.CoverThumbnailURL,
+		},
+		Score:   math.Round(score*1000) / 10,
+		Factors: factors,
+		Reasons: reasons(factors),
+	}
+}
+
+// priorityFactor ... 優先度 (0〜5) をそのまま割合に
+func priorityFactor(g *game.Game) *Factor {
+	f := &Factor{Name: FactorPriority, Value: float64(g.Priority) / game.MaxPriority, Detail: "no priority set"}
+	if g.Priority > 0 {
+		f.Detail = fmt.Sprintf("priority %d/%d", g.Priority, game.MaxPriority)
+	}
+	return f
+}
+
+// deadlineFactor ... 期限が近いほど高い。過ぎていれば 1、60日以上先なら 0
+func deadlineFactor(g *game.Game, now time.Time) *Factor {
+	f := &Factor{Name: FactorDeadline, Detail: "no deadline"}
+	deadline, err := time.ParseInLocation("2006-01-02", g.Deadline, time.Local)
+	if err != nil {
+		return f
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	days := int(math.Round(deadline.Sub(today).Hours() / 24))
+	switch {
+	case days < 0:
+		f.Value = 1
+		f.Detail = fmt.Sprintf("deadline passed %d days ago", -days)
+	case days == 0:
+		f.Value = 1
+		f.Detail = "deadline is today"
+	default:
+		f.Value = math.Max(1-float64(days)/60, 0)
+		f.Detail = fmt.Sprintf("deadline in %d days", days)
+	}
+	return f
+}
+
+// idleFactor ... 最後に遊んでから (なければ登録してから) 長いほど高い。30日で 1
+func idleFactor(g *game.Game, sig *signals, now time.Time) *Factor {
+	last, ok := sig.lastActivity[g.ID]
+	if !ok || last.Before(g.CreatedAt) {
+		last = g.CreatedAt
+	}
+	days := int(now.Sub(last).Hours() / 24)
+	f := &Factor{Name: FactorIdle, Value: math.Min(float64(days)/30, 1)}
+	switch {
+	case sig.played[g.ID]:
+		f.Detail = fmt.Sprintf("last played %d days ago", days)
+	case g.Status == game.StatusUnstarted:
+		f.Detail = fmt.Sprintf("waiting in the backlog for %d days", days)
+	default:
+		f.Detail = fmt.Sprintf("untouched for %d days", days)
+	}
+	return f
+}
+
+// lengthFitFactor ... 遊べる時間で、1回分のセッション (長いゲームほど長く、始めたばかりは長め) が遊べるか
+// 残り時間が遊べる時間に収まるなら、クリアできるので 1
+func lengthFitFactor(g *game.Game, remaining float64, minutes int) *Factor {
+	f := &Factor{Name: FactorLengthFit}
+	remainingMinutes := int(math.Ceil(remaining * 60))
+	if g.EstimatedHours > 0 && remainingMinutes > 0 && remainingMinutes <= minutes {
+		f.Value = 1
+		f.Detail = fmt.Sprintf("can be finished in about %d minutes", remainingMinutes)
+		return f
+	}
+
+	// 1回分のセッションの長さ: 推定時間の 1/20 (30〜120分)。推定時間がなければ60分
+	session := 60.0
+	if g.EstimatedHours > 0 {
+		session = math.Min(math.Max(g.EstimatedHours*60/20, 30), 120)
+	}
+	if g.Status == game.StatusUnstarted {
+		session = math.Max(session, 60) // 始めるときは操作や話を覚えるので長めに
+	}
+
+	f.Value = math.Min(float64(minutes)/session, 1)
+	if f.Value >= 1 {
+		f.Detail = fmt.Sprintf("a %d-minute session fits", minutes)
+	} else {
+		f.Detail = fmt.Sprintf("a session needs about %.0f minutes", session)
+	}
+	return f
+}
+
+// ratingFactor ... 同じジャンルのゲームを最近どう評価したか (1〜5 を 0〜1 に)。評価がなければ 0.5
+func ratingFactor(g *game.Game, sig *signals) *Factor {
+	f := &Factor{Name: FactorRating, Value: 0.5, Detail: "no recent ratings for this genre"}
+	if n := sig.genreRatedCnt[g.Genre]; n > 0 && g.Genre != "" {
+		avg := sig.genreRatings[g.Genre]
+		f.Value = (avg - 1) / 4
+		f.Detail = fmt.Sprintf("recent %s games rated %.1f/5", g.Genre, avg)
+	}
+	return f
+}
+
+// varietyFactor ... 直近のセッションに同じジャンルが少ないほど高い。セッションがなければ 0.5
+func varietyFactor(g *game.Game, sig *signals) *Factor {
+	f := &Factor{Name: FactorVariety, Value: 0.5, Detail: "no recent sessions"}
+	if len(sig.recentGenres) == 0 {
+		return f
+	}
+	same := 0
+	for _, genre := range sig.recentGenres {
+		if genre != "" && genre == g.Genre {
+			same++
+		}
+	}
+	f.Value = 1 - float64(same)/float64(len(sig.recentGenres))
+	if same == 0 {
+		f.Detail = "a change from recently played genres"
+	} else {
+		f.Detail = fmt.Sprintf("%d of the last %d sessions were %s", same, len(sig.recentGenres), g.Genre)
+	}
+	return f
+}
+
+// reasons ... スコアを大きく押し上げた (reasonThreshold 以上の) 指標の説明を、寄与の大きい順に最大3つ
+func reasons(factors []*Factor) []string {
+	sorted := append([]*Factor{}, factors...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Weight*sorted[i].Value > sorted[j].Weight*sorted[j].Value
+	})
+	out := []string{}
+	for _, f := range sorted {
+		if len(out) == 3 || f.Value < reasonThreshold {
+			break
+		}
+		out = append(out, f.Detail)
+	}
+	return out
+}
+
+// spinRoulette ... スコアを重みにして、重複なしで limit 本を選ぶ
+// スコアが 0 のゲームも選ばれる可能性があるように、重みに 1 を足す
+func spinRoulette(picks []*Pick, limit int, seed int64) []*Pick {
+	weight := func(p *Pick) float64 { return p.Score + 1 }
+
+	total := 0.0
+	for _, p := range picks {
+		total += weight(p)
+	}
+	for _, p := range picks {
+		prob := math.Round(weight(p)/total*1000) / 1000
+		p.Probability = &prob
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	pool := append([]*Pick{}, picks...)
+	var chosen []*Pick
+	for len(chosen) < limit && len(pool) > 0 {
+		sum := 0.0
+		for _, p := range pool {
+			sum += weight(p)
+		}
+		r := rng.Float64() * sum
+		idx := len(pool) - 1
+		for i, p := range pool {
+			if r < weight(p) {
+				idx = i
+				break
+			}
+			r -= weight(p)
+		}
+		chosen = append(chosen, pool[idx])
+		pool = append(pool[:idx], pool[idx+1:]...)
+	}
+	return chosen
+}
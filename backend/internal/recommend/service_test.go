@@ -0,0 +1,145 @@
+package recommend
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"TO-DO-IT/internal/game"
+)
+
+// rouletteCandidates ... ID 1〜n の候補 (スコアは ID ごとに違う値)
+func rouletteCandidates(n int) []*Pick {
+	picks := make([]*Pick, 0, n)
+	for id := 1; id <= n; id++ {
+		picks = append(picks, &Pick{Game: &GameSummary{ID: id}, Score: float64(id * 15 % 100)})
+	}
+	return picks
+}
+
+func pickedIDs(picks []*Pick) []int {
+	ids := make([]int, 0, len(picks))
+	for _, p := range picks {
+		ids = append(ids, p.Game.ID)
+	}
+	return ids
+}
+
+func TestSpinRoulette(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates int
+		limit      int
+		want       int
+	}{
+		{name: "fewer picks than candidates", candidates: 8, limit: 3, want: 3},
+		{name: "limit larger than candidates", candidates: 4, limit: 10, want: 4},
+		{name: "no candidates", candidates: 0, limit: 3, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(0); seed < 20; seed++ {
+				first := pickedIDs(spinRoulette(rouletteCandidates(tt.candidates), tt.limit, seed))
+				again := pickedIDs(spinRoulette(rouletteCandidates(tt.candidates), tt.limit, seed))
+				if !reflect.DeepEqual(first, again) {
+					t.Fatalf("seed %d: picks %v, then %v; want the same picks", seed, first, again)
+				}
+				if len(first) != tt.want {
+					t.Fatalf("seed %d: got %d picks, want %d", seed, len(first), tt.want)
+				}
+				seen := map[int]bool{}
+				for _, id := range first {
+					if seen[id] {
+						t.Fatalf("seed %d: game %d picked twice in %v", seed, id, first)
+					}
+					seen[id] = true
+				}
+			}
+		})
+	}
+}
+
+func TestSpinRouletteSeedsDiffer(t *testing.T) {
+	first := pickedIDs(spinRoulette(rouletteCandidates(8), 3, 1))
+	for seed := int64(2); seed < 20; seed++ {
+		if !reflect.DeepEqual(pickedIDs(spinRoulette(rouletteCandidates(8), 3, seed)), first) {
+			return
+		}
+	}
+	t.Errorf("every seed picked %v; want the seed to change the picks", first)
+}
+
+func TestSpinRouletteProbability(t *testing.T) {
+	picks := rouletteCandidates(5)
+	spinRoulette(picks, 1, 7)
+	sum := 0.0
+	for _, p := range picks {
+		if p.Probability == nil {
+			t.Fatalf("game %d: no probability", p.Game.ID)
+		}
+		sum += *p.Probability
+	}
+	if math.Abs(sum-1) > 0.01 {
+		t.Errorf("probabilities add up to %.3f, want 1", sum)
+	}
+	// スコアが高いほど選ばれやすい
+	if *picks[1].Probability <= *picks[0].Probability {
+		t.Errorf("score %.0f: %.3f, score %.0f: %.3f; want the higher score more likely",
+			picks[0].Score, *picks[0].Probability, picks[1].Score, *picks[1].Probability)
+	}
+}
+
+func TestLengthFitFactor(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    string
+		estimated float64
+		remaining float64
+		minutes   int
+		want      float64
+	}{
+		{name: "can be finished", status: game.StatusPlaying, estimated: 2, remaining: 0.5, minutes: 60, want: 1},
+		{name: "finishing needs longer", status: game.StatusPlaying, estimated: 2, remaining: 1.5, minutes: 15, want: 0.5},
+		{name: "no estimate uses an hour", status: game.StatusPlaying, minutes: 30, want: 0.5},
+		{name: "short session of a short game", status: game.StatusPlaying, estimated: 10, remaining: 10, minutes: 15, want: 0.5},
+		{name: "long game caps the session at two hours", status: game.StatusPlaying, estimated: 100, remaining: 100, minutes: 60, want: 0.5},
+		{name: "long enough for a session", status: game.StatusPlaying, estimated: 100, remaining: 100, minutes: 180, want: 1},
+		{name: "unstarted game needs an hour", status: game.StatusUnstarted, estimated: 10, remaining: 10, minutes: 30, want: 0.5},
+		{name: "played past the estimate", status: game.StatusPlaying, estimated: 2, remaining: 0, minutes: 15, want: 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &game.Game{Status: tt.status, EstimatedHours: tt.estimated}
+			f := lengthFitFactor(g, tt.remaining, tt.minutes)
+			if f.Name != FactorLengthFit || math.Abs(f.Value-tt.want) > 1e-9 {
+				t.Errorf("lengthFitFactor() = %s %.3f (%s), want %.3f", f.Name, f.Value, f.Detail, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeadlineFactor(t *testing.T) {
+	now := time.Date(2026, time.June, 1, 15, 0, 0, 0, time.Local)
+	tests := []struct {
+		deadline string
+		want     float64
+		detail   string
+	}{
+		{deadline: "", want: 0, detail: "no deadline"},
+		{deadline: "someday", want: 0, detail: "no deadline"},
+		{deadline: "2026-05-29", want: 1, detail: "deadline passed 3 days ago"},
+		{deadline: "2026-06-01", want: 1, detail: "deadline is today"},
+		{deadline: "2026-06-16", want: 0.75, detail: "deadline in 15 days"},
+		{deadline: "2026-07-31", want: 0, detail: "deadline in 60 days"},
+		{deadline: "2026-12-31", want: 0, detail: "deadline in 213 days"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.deadline, func(t *testing.T) {
+			f := deadlineFactor(&game.Game{Deadline: tt.deadline}, now)
+			if f.Name != FactorDeadline || math.Abs(f.Value-tt.want) > 1e-9 || f.Detail != tt.detail {
+				t.Errorf("deadlineFactor(%q) = %.3f %q, want %.3f %q", tt.deadline, f.Value, f.Detail, tt.want, tt.detail)
+			}
+		})
+	}
+}
@@ -14,4 +14,20 @@ type Motivation struct {
 type PlayResult struct {
 	ScheduleID string `json:"schedule_id"` // どのスケジュールに対する結果か
 	Result     string `json:"result"`      // "success" (成功) or "failure" (失敗/スキップ)
-}
\ No newline at end of file
+}
+
+// PlaySessionReport (プレイセッションの記録)
+// タイマーで計ったプレイ時間に応じたポイント計算に使う情報
+type PlaySessionReport struct {
+	GameID    int  `json:"game_id"`
+	Minutes   int  `json:"minutes"`   // 実際にプレイした時間 (分)
+	Scheduled bool `json:"scheduled"` // スケジュールどおりに遊んだ (スケジュールに紐づく) ならボーナス
+}
+
+// CompletionReport (完了報告) [cite: 76]
+// ゲームを最後までプレイしたときのボーナス計算に使う情報
+type CompletionReport struct {
+	GameID         int     `json:"game_id"`
+	EstimatedHours float64 `json:"estimated_hours"` // ゲームの長さ（推定プレイ時間）
+	FullClear      bool    `json:"full_clear"`      // やり込み（100%）ならボーナス増
+}
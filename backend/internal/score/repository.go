@@ -5,16 +5,28 @@ import "database/sql"
 type Repository interface {
 	GetMotivationByUserID(userID string) (*Motivation, error)
 	UpdateMotivation(motivation *Motivation) error
+	// WithTx ... 他パッケージと同じトランザクション上で動くリポジトリを返す
+	WithTx(tx *sql.Tx) Repository
+}
+
+// querier ... *sql.DB と *sql.Tx の共通メソッド
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	QueryRow(query string, args ...any) *sql.Row
 }
 
 type postgresRepository struct {
-	db *sql.DB
+	db querier
 }
 
 func NewRepository(db *sql.DB) Repository {
 	return &postgresRepository{db: db}
 }
 
+func (r *postgresRepository) WithTx(tx *sql.Tx) Repository {
+	return &postgresRepository{db: tx}
+}
+
 func (r *postgresRepository) GetMotivationByUserID(userID string) (*Motivation, error) {
 	query := `SELECT user_id, points, rank, level FROM motivation WHERE user_id = ?`
 
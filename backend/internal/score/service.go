@@ -1,9 +1,18 @@
 package score
 
+import "database/sql"
+
 // Service (インターフェース)
 type Service interface {
 	GetMotivation(userID string) (*Motivation, error)
 	ReportPlayResult(userID string, result PlayResult) (*Motivation, error) // [cite: 76]
+	// ReportCompletion ... 完了報告のボーナスを付与し、付与したポイントを返す
+	ReportCompletion(userID string, report CompletionReport) (*Motivation, int, error)
+	// ReportPlaySession ... プレイセッションのポイントを付与し、付与したポイントを返す
+	ReportPlaySession(userID string, report PlaySessionReport) (*Motivation, int, error)
+
+	// WithTx ... 他パッケージと同じトランザクション上で動くサービスを返す
+	WithTx(tx *sql.Tx) Service
 }
 
 type service struct {
@@ -15,6 +24,10 @@ func NewService(repo Repository) Service {
 	return &service{repo: repo}
 }
 
+func (s *service) WithTx(tx *sql.Tx) Service {
+	return &service{repo: s.repo.WithTx(tx)}
+}
+
 func (s *service) GetMotivation(userID string) (*Motivation, error) {
 	return s.repo.GetMotivationByUserID(userID)
 }
@@ -41,7 +54,86 @@ func (s *service) ReportPlayResult(userID string, result PlayResult) (*Motivatio
 		return nil, err
 	}
 
-	// (TODO: taskRepoを使ってplay_sessions [cite: 96-101] にプレイ記録を保存する)
+	// (プレイ記録は task パッケージが play_sessions に保存し、ReportPlaySession でポイントを付与する)
 
 	return motivation, nil
 }
+
+// 完了ボーナスの計算用パラメータ
+const (
+	completionBaseBonus    = 30  // 長さに関係なく付与する基本ボーナス
+	completionBonusPerHour = 2   // 推定プレイ時間1時間あたりのボーナス
+	completionMaxHours     = 100 // これ以上長いゲームは同じ扱い
+	defaultGameHours       = 10  // 推定プレイ時間が未設定のときの仮の長さ
+)
+
+// completionBonus ... ゲームの長さに応じた完了ボーナスを計算する
+// 長いゲームほど多くもらえる。やり込み（100%）は1.5倍
+func completionBonus(report CompletionReport) int {
+	hours := report.EstimatedHours
+	if hours <= 0 {
+		hours = defaultGameHours
+	}
+	if hours > completionMaxHours {
+		hours = completionMaxHours
+	}
+
+	bonus := completionBaseBonus + int(hours*completionBonusPerHour)
+	if report.FullClear {
+		bonus = bonus * 3 / 2
+	}
+	return bonus
+}
+
+// ReportCompletion (完了ボーナス)
+func (s *service) ReportCompletion(userID string, report CompletionReport) (*Motivation, int, error) {
+	motivation, err := s.repo.GetMotivationByUserID(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	bonus := completionBonus(report)
+	motivation.Points += bonus
+
+	if err := s.repo.UpdateMotivation(motivation); err != nil {
+		return nil, 0, err
+	}
+
+	return motivation, bonus, nil
+}
+
+// プレイセッションのポイントの計算用パラメータ
+const (
+	sessionMinutesPerPoint = 10 // 実際にプレイした10分ごとに1ポイント
+	sessionMaxPoints       = 30 // 1回のセッションでもらえる時間分のポイントの上限
+	sessionScheduledBonus  = 10 // スケジュールどおりに遊んだときのボーナス (ReportPlayResult の成功と同じ)
+)
+
+// playSessionPoints ... 実際にプレイした時間に応じたポイントを計算する
+func playSessionPoints(report PlaySessionReport) int {
+	points := report.Minutes / sessionMinutesPerPoint
+	if points > sessionMaxPoints {
+		points = sessionMaxPoints
+	}
+	if report.Scheduled && points > 0 {
+		points += sessionScheduledBonus
+	}
+	return points
+}
+
+// ReportPlaySession (プレイセッションのポイント)
+func (s *service) ReportPlaySession(userID string, report PlaySessionReport) (*Motivation, int, error) {
+	motivation, err := s.repo.GetMotivationByUserID(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	points := playSessionPoints(report)
+	motivation.Points += points
+
+	if err := s.repo.UpdateMotivation(motivation); err != nil {
+		return nil, 0, err
+	}
+
+	return motivation, points, nil
+}
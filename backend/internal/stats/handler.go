@@ -0,0 +1,113 @@
+package stats
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(s Service) *Handler {
+	return &Handler{service: s}
+}
+
+// RegisterRoutes ... EchoルーターにAPIエンドポイントを登録
+func (h *Handler) RegisterRoutes(api *echo.Group) {
+	api.GET("/stats", h.handleGetStats)                              // GET /api/stats?from=2025-01-01&to=2025-12-31
+	api.GET("/stats/trend", h.handleGetTrend)                        // GET /api/stats/trend?bucket=week&from=2025-01-01
+	api.POST("/stats/snapshots/backfill", h.handleBackfillSnapshots) // POST /api/stats/snapshots/backfill
+}
+
+// handleGetStats ... 積みゲーの統計を返す
+// from / to は YYYY-MM-DD (to の日も含む) か RFC3339。省略すると、最初から現在までを集計する
+func (h *Handler) handleGetStats(c echo.Context) error {
+	userID := "user_123" // 仮
+
+	r, err := parseRange(c.QueryParam("from"), c.QueryParam("to"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	stats, err := h.service.GetStats(userID, r)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRange) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Stats: Error getting stats: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get stats"})
+	}
+	return c.JSON(http.StatusOK, stats)
+}
+
+// handleGetTrend ... 日ごとの記録の推移を返す
+// bucket は day (省略時) / week / month。各期間の最後の記録を返す
+func (h *Handler) handleGetTrend(c echo.Context) error {
+	r, err := parseRange(c.QueryParam("from"), c.QueryParam("to"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	trend, err := h.service.GetTrend(c.QueryParam("bucket"), r)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRange) || errors.Is(err, ErrInvalidBucket) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Stats: Error getting trend: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get trend"})
+	}
+	return c.JSON(http.StatusOK, trend)
+}
+
+// handleBackfillSnapshots ... 記録がない過去の日を、ステータス変更履歴から復元する
+// (起動時にも実行される。すでにある日の記録は上書きしない)
+func (h *Handler) handleBackfillSnapshots(c echo.Context) error {
+	n, err := h.service.BackfillSnapshots()
+	if err != nil {
+		log.Printf("Stats: Error backfilling snapshots: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to backfill snapshots"})
+	}
+	return c.JSON(http.StatusOK, map[string]int64{"inserted": n})
+}
+
+// dateLayout ... クエリで受け付ける日付の形式
+const dateLayout = "2006-01-02"
+
+// parseRange ... クエリの from / to を集計期間に変換する
+// 日付だけの to はその日の終わりまで含めるため、翌日の0時 (を含まない) にする
+func parseRange(fromParam string, toParam string) (Range, error) {
+	r := Range{To: time.Now()}
+
+	if fromParam != "" {
+		from, _, err := parseTime(fromParam)
+		if err != nil {
+			return r, errors.New("from must be YYYY-MM-DD or RFC3339")
+		}
+		r.From = &from
+	}
+	if toParam != "" {
+		to, dateOnly, err := parseTime(toParam)
+		if err != nil {
+			return r, errors.New("to must be YYYY-MM-DD or RFC3339")
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		r.To = to
+	}
+	return r, nil
+}
+
+// parseTime ... YYYY-MM-DD (ローカル時刻の0時) か RFC3339 を読み取る。日付だけだったかも返す
+func parseTime(v string) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation(dateLayout, v, time.Local); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t, false, err
+}
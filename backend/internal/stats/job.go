@@ -0,0 +1,34 @@
+package stats
+
+import (
+	"log"
+	"time"
+)
+
+// StartSnapshotJob ... 日ごとの記録を取るジョブをバックグラウンドで動かす
+// 起動時に、過去の記録を復元してから今日の記録を取り、その後は interval ごとに今日の記録を取り直す
+// (日付が変わった後の最初の実行で、新しい日の記録ができる)。返り値の関数でジョブを止める
+func StartSnapshotJob(svc Service, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		if n, err := svc.BackfillSnapshots(); err != nil {
+			log.Printf("Stats: Error backfilling snapshots: %v", err)
+		} else if n > 0 {
+			log.Printf("Stats: Backfilled %d snapshots from status history", n)
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := svc.TakeSnapshots(); err != nil {
+				log.Printf("Stats: Error taking snapshots: %v", err)
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,130 @@
+package stats
+
+import (
+	"time"
+
+	"TO-DO-IT/internal/game"
+)
+
+// Range ... 集計期間。From 以上 To 未満
+type Range struct {
+	From *time.Time `json:"from"` // nil なら最初から
+	To   time.Time  `json:"to"`
+}
+
+// 指標ごとに、期間で絞り込む日時の種類
+const (
+	BasisGameCreated   = "game.created_at"     // ゲームを登録した日時
+	BasisGameCompleted = "game.completed_at"   // ゲームをクリアした日時
+	BasisSessionStart  = "schedule.start_time" // プレイセッションの開始時刻
+)
+
+// MetricRange ... 指標の集計期間 (どの日時で絞り込んだかも返す)
+type MetricRange struct {
+	Range
+	Basis string `json:"basis"`
+}
+
+// CountMetric ... ステータス別・プラットフォーム別・ジャンル別のゲーム数
+type CountMetric struct {
+	Range  MetricRange       `json:"range"`
+	Total  int               `json:"total"`
+	Counts []*game.GameCount `json:"counts"`
+}
+
+// CompletionRateMetric ... 期間内に登録したゲームのうち、クリア済みの割合
+type CompletionRateMetric struct {
+	Range     MetricRange `json:"range"`
+	Games     int         `json:"games"`
+	Completed int         `json:"completed"`
+	Rate      *float64    `json:"rate"` // 0〜1。ゲームがなければ null
+}
+
+// DaysToCompleteMetric ... 期間内にクリアしたゲームの、登録からクリアまでの平均日数
+type DaysToCompleteMetric struct {
+	Range       MetricRange `json:"range"`
+	Games       int         `json:"games"`
+	AverageDays *float64    `json:"average_days"` // クリアしたゲームがなければ null
+}
+
+// SessionMetric ... 期間内のプレイセッション (スケジュール) の件数と割合
+type SessionMetric struct {
+	Range MetricRange `json:"range"`
+	// Scheduled ... 取り消し (cancelled) 以外のセッション数。割合はこれに対する値
+	Scheduled int `json:"scheduled"`
+	Pending   int `json:"pending"`
+	Completed int `json:"completed"`
+	Skipped   int `json:"skipped"`
+	Cancelled int `json:"cancelled"`
+
+	CompletedRatio *float64 `json:"completed_ratio"` // セッションがなければ null
+	SkippedRatio   *float64 `json:"skipped_ratio"`
+}
+
+// HoursMetric ... 期間内に予定したプレイ時間と、実際にプレイした時間
+// PlayedHours は、タイマーで記録した時間と、タイマーを使わずに完了したスケジュールの時間の合計
+type HoursMetric struct {
+	Range        MetricRange `json:"range"`
+	PlannedHours float64     `json:"planned_hours"`
+	PlayedHours  float64     `json:"played_hours"`
+	TrackedHours float64     `json:"tracked_hours"` // PlayedHours のうち、プレイセッションのタイマーで記録した時間
+	PlayedRatio  *float64    `json:"played_ratio"`  // 予定した時間がなければ null
+}
+
+// Stats ... GET /api/stats のレスポンス
+type Stats struct {
+	Range          Range                 `json:"range"`
+	ByStatus       *CountMetric          `json:"by_status"`
+	ByPlatform     *CountMetric          `json:"by_platform"`
+	ByGenre        *CountMetric          `json:"by_genre"`
+	CompletionRate *CompletionRateMetric `json:"completion_rate"`
+	DaysToComplete *DaysToCompleteMetric `json:"days_to_complete"`
+	Sessions       *SessionMetric        `json:"sessions"`
+	Hours          *HoursMetric          `json:"hours"`
+}
+
+// 記録の取り方
+const (
+	SourceJob      = "job"      // 毎日のジョブで記録した
+	SourceBackfill = "backfill" // ジョブを動かす前の日を、ステータス変更履歴から復元した
+)
+
+// Snapshot ... ある日の積みゲーの状態 (backlog_snapshots テーブル)
+type Snapshot struct {
+	UserID    int    `json:"-"`
+	Day       string `json:"day"` // YYYY-MM-DD
+	Unstarted int    `json:"unstarted"`
+	Playing   int    `json:"playing"`
+	Paused    int    `json:"paused"`
+	Completed int    `json:"completed"`
+	Dropped   int    `json:"dropped"`
+	// RemainingHours ... 未開始・プレイ中・中断中のゲームの、クリアまでの残り時間 (推定時間 - プレイ時間) の合計
+	RemainingHours float64 `json:"remaining_hours"`
+	Source         string  `json:"source"` // job, backfill
+}
+
+// Backlog ... まだ終わっていない (未開始・プレイ中・中断中の) ゲームの数
+func (s *Snapshot) Backlog() int {
+	return s.Unstarted + s.Playing + s.Paused
+}
+
+// 推移の集計単位
+const (
+	BucketDay   = "day"
+	BucketWeek  = "week" // 月曜始まり
+	BucketMonth = "month"
+)
+
+// TrendPoint ... 推移の1点。期間 (Period から始まる日・週・月) の最後の記録
+type TrendPoint struct {
+	Period string `json:"period"` // 期間の初日 (YYYY-MM-DD)
+	Snapshot
+	Backlog int `json:"backlog"`
+}
+
+// Trend ... GET /api/stats/trend のレスポンス
+type Trend struct {
+	Bucket string        `json:"bucket"`
+	Range  Range         `json:"range"`
+	Points []*TrendPoint `json:"points"`
+}
@@ -0,0 +1,152 @@
+package stats
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Repository ... 積みゲーの日ごとの記録 (backlog_snapshots テーブル) のDB操作
+type Repository interface {
+	// TakeSnapshots ... 全ユーザーの今のゲームの状態を day の記録として保存し、件数を返す (同じ日の記録は上書き)
+	TakeSnapshots(day string) (int64, error)
+	// InsertSnapshotsIfMissing ... まだ記録がない日の分だけ保存し、件数を返す (履歴からの復元用)
+	InsertSnapshotsIfMissing(snapshots []*Snapshot) (int64, error)
+	// GetGameUserIDs ... ゲームを登録しているユーザーのID
+	GetGameUserIDs() ([]int, error)
+	// GetTrend ... fromDay 〜 toDay (両端を含む) の記録を bucket ごとにまとめ、各期間の最後の記録を返す
+	GetTrend(userID int, bucket string, fromDay string, toDay string) ([]*TrendPoint, error)
+}
+
+// repository (実装)
+type repository struct {
+	db *sql.DB
+}
+
+// NewRepository ... DB接続を受け取り、リポジトリを初期化
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// TakeSnapshots ... games テーブルをユーザーごとに集計して、その日の記録にする
+func (r *repository) TakeSnapshots(day string) (int64, error) {
+	// WHERE を必ず書く (INSERT ... SELECT と ON CONFLICT の構文があいまいにならないように)
+	query := `INSERT INTO backlog_snapshots
+			(user_id, day, unstarted, playing, paused, completed, dropped, remaining_hours, source, created_at)
+		SELECT user_id, ?,
+			SUM(status = 'unstarted'), SUM(status = 'playing'), SUM(status = 'paused'),
+			SUM(status = 'completed'), SUM(status = 'dropped'),
+			ROUND(COALESCE(SUM(CASE WHEN status IN ('unstarted', 'playing', 'paused')
+				THEN MAX(estimated_hours - played_minutes / 60.0, 0) END), 0), 2),
+			?, ?
+		FROM games
+		WHERE deleted_at IS NULL
+		GROUP BY user_id
+		ON CONFLICT (user_id, day) DO UPDATE SET
+			unstarted = excluded.unstarted, playing = excluded.playing, paused = excluded.paused,
+			completed = excluded.completed, dropped = excluded.dropped,
+			remaining_hours = excluded.remaining_hours, source = excluded.source, created_at = excluded.created_at`
+
+	result, err := r.db.Exec(query, day, SourceJob, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// InsertSnapshotsIfMissing ... ジョブで記録した日は上書きしない
+func (r *repository) InsertSnapshotsIfMissing(snapshots []*Snapshot) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback() // Commit 後は何もしない
+
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO backlog_snapshots
+		(user_id, day, unstarted, playing, paused, completed, dropped, remaining_hours, source, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	var inserted int64
+	for _, s := range snapshots {
+		result, err := stmt.Exec(s.UserID, s.Day, s.Unstarted, s.Playing, s.Paused, s.Completed, s.Dropped,
+			s.RemainingHours, s.Source, now)
+		if err != nil {
+			return 0, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		inserted += n
+	}
+
+	return inserted, tx.Commit()
+}
+
+// GetGameUserIDs ... games テーブルに出てくるユーザーID
+func (r *repository) GetGameUserIDs() ([]int, error) {
+	rows, err := r.db.Query(`SELECT DISTINCT user_id FROM games ORDER BY user_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// bucketPeriods ... 集計単位ごとの、記録の日から期間の初日を求める式
+var bucketPeriods = map[string]string{
+	BucketDay:   `day`,
+	BucketWeek:  `date(day, '-6 days', 'weekday 1')`, // その日以前で直近の月曜日
+	BucketMonth: `strftime('%Y-%m-01', day)`,
+}
+
+// GetTrend ... 期間ごとに最後の日の記録を返す (週なら日曜、その日の記録がなければ最後に記録した日)
+func (r *repository) GetTrend(userID int, bucket string, fromDay string, toDay string) ([]*TrendPoint, error) {
+	period, ok := bucketPeriods[bucket]
+	if !ok {
+		return nil, fmt.Errorf("unknown bucket %q", bucket)
+	}
+
+	query := `SELECT p.period, s.day, s.unstarted, s.playing, s.paused, s.completed, s.dropped, s.remaining_hours, s.source
+		FROM backlog_snapshots s
+		JOIN (
+			SELECT ` + period + ` AS period, MAX(day) AS day FROM backlog_snapshots
+			WHERE user_id = ? AND day >= ? AND day <= ?
+			GROUP BY period
+		) p ON p.day = s.day
+		WHERE s.user_id = ?
+		ORDER BY s.day`
+
+	rows, err := r.db.Query(query, userID, fromDay, toDay, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []*TrendPoint{}
+	for rows.Next() {
+		p := &TrendPoint{}
+		if err := rows.Scan(&p.Period, &p.Day, &p.Unstarted, &p.Playing, &p.Paused, &p.Completed, &p.Dropped,
+			&p.RemainingHours, &p.Source); err != nil {
+			return nil, err
+		}
+		p.UserID = userID
+		p.Backlog = p.Snapshot.Backlog()
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
@@ -0,0 +1,303 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"TO-DO-IT/internal/calendar"
+	"TO-DO-IT/internal/game"
+	"TO-DO-IT/internal/task"
+)
+
+var (
+	// ErrInvalidRange ... from が to より後のとき
+	ErrInvalidRange = errors.New("from must be before to")
+	// ErrInvalidBucket ... 推移の集計単位が day / week / month 以外のとき
+	ErrInvalidBucket = errors.New("bucket must be day, week or month")
+)
+
+// Service ... 統計のビジネスロジック
+// 集計は game / calendar / task のリポジトリの SQL で行い、ここでは割合の計算とまとめだけを行う
+type Service interface {
+	// GetStats ... 期間内の統計をまとめて返す
+	GetStats(userID string, r Range) (*Stats, error)
+
+	// 日ごとの記録 (推移のグラフ用)
+	// TakeSnapshots ... 全ユーザーの今日の記録を取る (同じ日に何度呼んでも、最後の状態で上書き)
+	TakeSnapshots() error
+	// BackfillSnapshots ... 記録がない過去の日を、ゲームのステータス変更履歴から復元し、復元した件数を返す
+	BackfillSnapshots() (int64, error)
+	// GetTrend ... 期間内の記録を bucket (day / week / month) ごとに返す
+	GetTrend(bucket string, r Range) (*Trend, error)
+}
+
+// service (実装)
+type service struct {
+	repo         Repository          // 日ごとの記録
+	gameRepo     game.Repository     // 担当Cのゲームリポジトリ
+	calendarRepo calendar.Repository // 担当Aのカレンダーリポジトリ
+	taskRepo     task.Repository     // プレイセッション (実際に遊んだ時間)
+}
+
+// NewService ... 必要なリポジトリを受け取り、サービスを初期化
+func NewService(repo Repository, gameRepo game.Repository, calendarRepo calendar.Repository, taskRepo task.Repository) Service {
+	return &service{repo: repo, gameRepo: gameRepo, calendarRepo: calendarRepo, taskRepo: taskRepo}
+}
+
+// gameUserID ... game パッケージ側の仮ユーザーID (calendar の GenerateSchedule と同じ固定値)
+const gameUserID = 1
+
+// GetStats ... 各指標を集計する
+func (s *service) GetStats(userID string, r Range) (*Stats, error) {
+	from := time.Time{}
+	if r.From != nil {
+		from = *r.From
+		if !from.Before(r.To) {
+			return nil, ErrInvalidRange
+		}
+	}
+	metricRange := func(basis string) MetricRange {
+		return MetricRange{Range: r, Basis: basis}
+	}
+
+	stats := &Stats{Range: r}
+
+	// 1. ステータス別・プラットフォーム別・ジャンル別のゲーム数
+	var err error
+	if stats.ByStatus, err = s.countGames("status", from, r); err != nil {
+		return nil, err
+	}
+	if stats.ByPlatform, err = s.countGames("platform", from, r); err != nil {
+		return nil, err
+	}
+	if stats.ByGenre, err = s.countGames("genre", from, r); err != nil {
+		return nil, err
+	}
+
+	// 2. クリア率と、クリアまでの平均日数
+	games, completed, err := s.gameRepo.CountCompletedGames(gameUserID, from, r.To)
+	if err != nil {
+		return nil, err
+	}
+	stats.CompletionRate = &CompletionRateMetric{
+		Range:     metricRange(BasisGameCreated),
+		Games:     games,
+		Completed: completed,
+		Rate:      ratio(completed, games),
+	}
+
+	completedGames, avgDays, err := s.gameRepo.AverageDaysToComplete(gameUserID, from, r.To)
+	if err != nil {
+		return nil, err
+	}
+	stats.DaysToComplete = &DaysToCompleteMetric{
+		Range:       metricRange(BasisGameCompleted),
+		Games:       completedGames,
+		AverageDays: avgDays,
+	}
+
+	// 3. プレイセッションの件数・割合と、予定した時間・プレイした時間
+	sessions, err := s.calendarRepo.GetSessionCounts(userID, from, r.To)
+	if err != nil {
+		return nil, err
+	}
+	scheduled := sessions.Pending + sessions.Completed + sessions.Skipped
+	stats.Sessions = &SessionMetric{
+		Range:          metricRange(BasisSessionStart),
+		Scheduled:      scheduled,
+		Pending:        sessions.Pending,
+		Completed:      sessions.Completed,
+		Skipped:        sessions.Skipped,
+		Cancelled:      sessions.Cancelled,
+		CompletedRatio: ratio(sessions.Completed, scheduled),
+		SkippedRatio:   ratio(sessions.Skipped, scheduled),
+	}
+
+	// 実際に遊んだ時間には、タイマーで記録した時間も足す
+	trackedMinutes, err := s.taskRepo.GetPlayedMinutes(userID, from, r.To)
+	if err != nil {
+		return nil, err
+	}
+	tracked := math.Round(float64(trackedMinutes)/60*100) / 100
+	stats.Hours = &HoursMetric{
+		Range:        metricRange(BasisSessionStart),
+		PlannedHours: sessions.PlannedHours,
+		PlayedHours:  math.Round((sessions.PlayedHours+tracked)*100) / 100,
+		TrackedHours: tracked,
+	}
+	if sessions.PlannedHours > 0 {
+		played := stats.Hours.PlayedHours / sessions.PlannedHours
+		stats.Hours.PlayedRatio = &played
+	}
+
+	return stats, nil
+}
+
+// countGames ... 期間内に登録したゲームを groupBy ごとに数える
+func (s *service) countGames(groupBy string, from time.Time, r Range) (*CountMetric, error) {
+	rows, err := s.gameRepo.CountGamesBy(gameUserID, groupBy, from, r.To)
+	if err != nil {
+		return nil, err
+	}
+	metric := &CountMetric{Range: MetricRange{Range: r, Basis: BasisGameCreated}, Counts: rows}
+	for _, row := range rows {
+		metric.Total += row.Count
+	}
+	return metric, nil
+}
+
+// ratio ... n / total。total が0なら nil (割合を出せない)
+func ratio(n int, total int) *float64 {
+	if total == 0 {
+		return nil
+	}
+	v := float64(n) / float64(total)
+	return &v
+}
+
+// --- 日ごとの記録 ---
+
+// TakeSnapshots ... 今日の記録を取る
+func (s *service) TakeSnapshots() error {
+	_, err := s.repo.TakeSnapshots(time.Now().Format(dateLayout))
+	return err
+}
+
+// BackfillSnapshots ... ユーザーごとに、履歴の最初の日から昨日までの記録を復元する
+func (s *service) BackfillSnapshots() (int64, error) {
+	userIDs, err := s.repo.GetGameUserIDs()
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, userID := range userIDs {
+		snapshots, err := s.rebuildSnapshots(userID, time.Now())
+		if err != nil {
+			return total, err
+		}
+		n, err := s.repo.InsertSnapshotsIfMissing(snapshots)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// rebuildSnapshots ... ステータス変更履歴から、履歴の最初の日から today の前日までの各日の終わりの状態を復元する
+//   - 履歴がないゲーム (履歴の記録を始める前に登録し、その後変更していないゲーム) は数えない
+//   - 最初の履歴より前は、その履歴の変更前のステータスだったとみなす
+//   - ゴミ箱に入れた日より後は数えない (完全に削除したゲームは履歴も残っていないので数えられない)
+//   - 残り時間は過去の値が残っていないので、今の推定時間・プレイ時間で計算する
+func (s *service) rebuildSnapshots(userID int, today time.Time) ([]*Snapshot, error) {
+	games, err := s.gameRepo.GetGamesByUserIDIncludingDeleted(userID)
+	if err != nil {
+		return nil, err
+	}
+	histories, err := s.gameRepo.GetStatusHistoryByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(histories) == 0 {
+		return nil, nil
+	}
+
+	byGame := map[int][]*game.StatusHistory{}
+	for _, h := range histories {
+		byGame[h.GameID] = append(byGame[h.GameID], h)
+	}
+
+	first := startOfDay(histories[0].ChangedAt)
+	last := startOfDay(today)
+	var snapshots []*Snapshot
+	for day := first; day.Before(last); day = day.AddDate(0, 0, 1) {
+		end := day.AddDate(0, 0, 1)
+		snapshot := &Snapshot{UserID: userID, Day: day.Format(dateLayout), Source: SourceBackfill}
+
+		for _, g := range games {
+			if g.DeletedAt != nil && g.DeletedAt.Before(end) {
+				continue
+			}
+			status := statusAt(byGame[g.ID], end)
+			if status == "" {
+				continue
+			}
+			snapshot.count(status, g)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// statusAt ... 履歴から、at の直前のステータスを求める。まだ登録されていない (分からない) なら空文字
+func statusAt(histories []*game.StatusHistory, at time.Time) string {
+	status := ""
+	if len(histories) > 0 {
+		status = histories[0].FromStatus // 最初の変更より前 (登録時の記録なら空文字)
+	}
+	for _, h := range histories {
+		if !h.ChangedAt.Before(at) {
+			break
+		}
+		status = h.ToStatus
+	}
+	return status
+}
+
+// count ... ステータスが status のゲーム g を記録に足す
+func (s *Snapshot) count(status string, g *game.Game) {
+	switch status {
+	case game.StatusUnstarted:
+		s.Unstarted++
+	case game.StatusPlaying:
+		s.Playing++
+	case game.StatusPaused:
+		s.Paused++
+	case game.StatusCompleted:
+		s.Completed++
+	case game.StatusDropped:
+		s.Dropped++
+	default:
+		return
+	}
+	if status == game.StatusUnstarted || status == game.StatusPlaying || status == game.StatusPaused {
+		s.RemainingHours += max(g.EstimatedHours-float64(g.PlayedMinutes)/60, 0)
+	}
+}
+
+// startOfDay ... t の日 (ローカル時刻) の0時
+func startOfDay(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// GetTrend ... 期間内の記録を集計単位ごとに返す
+func (s *service) GetTrend(bucket string, r Range) (*Trend, error) {
+	if bucket == "" {
+		bucket = BucketDay
+	}
+	if _, ok := bucketPeriods[bucket]; !ok {
+		return nil, ErrInvalidBucket
+	}
+
+	fromDay := ""
+	if r.From != nil {
+		if !r.From.Before(r.To) {
+			return nil, ErrInvalidRange
+		}
+		fromDay = r.From.Local().Format(dateLayout)
+	}
+	// To は含まないので、その直前の日までにする
+	toDay := r.To.Add(-time.Nanosecond).Local().Format(dateLayout)
+
+	points, err := s.repo.GetTrend(gameUserID, bucket, fromDay, toDay)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range points {
+		p.RemainingHours = math.Round(p.RemainingHours*100) / 100
+	}
+	return &Trend{Bucket: bucket, Range: r, Points: points}, nil
+}
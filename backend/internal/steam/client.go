@@ -0,0 +1,96 @@
+package steam
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL は Steam Web API の本番URLです。
+const DefaultBaseURL = "https://api.steampowered.com"
+
+// Client は、Steam Web API からライブラリ情報を取得するインターフェースです。
+type Client interface {
+	GetOwnedGames(steamID string) ([]OwnedGame, error)
+	GetRecentlyPlayedGames(steamID string) ([]OwnedGame, error)
+}
+
+// client は Client インターフェースの具体的な実装です。
+type client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewClient は、新しい client インスタンスを作成します。
+// baseURL を差し替えると、テスト用のローカルな偽 Steam サーバーに向けられます。
+func NewClient(baseURL string, apiKey string) Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// GetOwnedGames は、所持ゲームの一覧と累計プレイ時間を取得します。
+// プロフィールやゲーム情報が非公開の場合は ErrPrivateProfile を返します。
+func (c *client) GetOwnedGames(steamID string) ([]OwnedGame, error) {
+	params := url.Values{}
+	params.Set("steamid", steamID)
+	params.Set("include_appinfo", "1")
+	params.Set("include_played_free_games", "1")
+
+	var res ownedGamesResponse
+	if err := c.get("/IPlayerService/GetOwnedGames/v1/", params, &res); err != nil {
+		return nil, err
+	}
+	if res.Response.GameCount == nil {
+		return nil, ErrPrivateProfile
+	}
+	return res.Response.Games, nil
+}
+
+// GetRecentlyPlayedGames は、直近2週間に遊んだゲームとそのプレイ時間を取得します。
+func (c *client) GetRecentlyPlayedGames(steamID string) ([]OwnedGame, error) {
+	params := url.Values{}
+	params.Set("steamid", steamID)
+
+	var res recentlyPlayedResponse
+	if err := c.get("/IPlayerService/GetRecentlyPlayedGames/v1/", params, &res); err != nil {
+		return nil, err
+	}
+	return res.Response.Games, nil
+}
+
+// get は Steam Web API に GET リクエストを送り、JSON を out にデコードします。
+func (c *client) get(path string, params url.Values, out any) error {
+	if c.apiKey != "" {
+		params.Set("key", c.apiKey)
+	}
+	params.Set("format", "json")
+
+	resp, err := c.httpClient.Get(c.baseURL + path + "?" + params.Encode())
+	if err != nil {
+		log.Printf("Steam: Error calling %s: %v", path, err)
+		return fmt.Errorf("%w: %v", ErrSteamUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Steam: %s returned %d", path, resp.StatusCode)
+		return fmt.Errorf("%w: %s returned %d", ErrSteamUnavailable, path, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		log.Printf("Steam: Error decoding %s: %v", path, err)
+		return fmt.Errorf("%w: invalid response from %s", ErrSteamUnavailable, path)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package steam
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"TO-DO-IT/internal/game"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Handler は、Steam ライブラリ取り込みのHTTPリクエスト処理に関するインターフェースです。
+type Handler interface {
+	RegisterRoutes(apiGroup *echo.Group)
+	ImportLibrary(c echo.Context) error
+}
+
+// handler は Handler インターフェースの具体的な実装です。
+type handler struct {
+	svc Service
+}
+
+// NewHandler は、新しい handler インスタンスを作成します。
+func NewHandler(svc Service) Handler {
+	return &handler{svc: svc}
+}
+
+// RegisterRoutes は、ルーターにエンドポイントを登録します。
+func (h *handler) RegisterRoutes(apiGroup *echo.Group) {
+	apiGroup.POST("/games/import/steam", h.ImportLibrary) // POST /api/games/import/steam
+}
+
+// ImportLibrary は Steam の所持ゲームを取り込みます (POST /api/games/import/steam)
+func (h *handler) ImportLibrary(c echo.Context) error {
+	var req ImportRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("Handler: Failed to bind JSON for steam import: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body: " + err.Error()})
+	}
+
+	result, err := h.svc.ImportLibrary(&req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidSteamID):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, ErrPrivateProfile):
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		case errors.Is(err, ErrSteamUnavailable):
+			return c.JSON(http.StatusBadGateway, map[string]string{"error": err.Error()})
+		}
+		log.Printf("Handler: Error importing steam library: %v", err)
+		// 登録は全体でロールバックされているので、失敗したゲームを返して何も登録されていないことを伝える
+		var rowErr *game.ImportRowError
+		if errors.As(err, &rowErr) {
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"error": "Failed to import steam library; no games were imported",
+				"row":   rowErr.Row,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to import steam library"})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,50 @@
+package steam
+
+import "TO-DO-IT/internal/game"
+
+// OwnedGame は、Steam Web API が返す所持ゲーム1件分の情報です。
+type OwnedGame struct {
+	AppID           int    `json:"appid"`
+	Name            string `json:"name"`
+	PlaytimeForever int    `json:"playtime_forever"` // 累計プレイ時間（分）
+	Playtime2Weeks  int    `json:"playtime_2weeks"`  // 直近2週間のプレイ時間（分）
+}
+
+// ownedGamesResponse は IPlayerService/GetOwnedGames のレスポンスです。
+// 非公開プロフィールの場合は response が空オブジェクトになり、GameCount が nil になります。
+type ownedGamesResponse struct {
+	Response struct {
+		GameCount *int        `json:"game_count"`
+		Games     []OwnedGame `json:"games"`
+	} `json:"response"`
+}
+
+// recentlyPlayedResponse は IPlayerService/GetRecentlyPlayedGames のレスポンスです。
+type recentlyPlayedResponse struct {
+	Response struct {
+		TotalCount int         `json:"total_count"`
+		Games      []OwnedGame `json:"games"`
+	} `json:"response"`
+}
+
+// ImportRequest は、Steam ライブラリ取り込み（POST /api/games/import/steam）のリクエストボディです。
+type ImportRequest struct {
+	SteamID string `json:"steam_id"` // 17桁の SteamID64
+}
+
+// SkippedGame は、取り込まなかったゲームとその理由です。
+type SkippedGame struct {
+	AppID  int    `json:"appid"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// ImportResult は、Steam ライブラリ取り込みの結果です。
+type ImportResult struct {
+	SteamID string        `json:"steam_id"`
+	Total   int           `json:"total"` // Steam 上の所持ゲーム数
+	Created []*game.Game  `json:"created"`
+	Skipped []SkippedGame `json:"skipped"`
+	// Rows ... 所持ゲーム1件ごとの結果（CSV などのインポートと同じ形式。row は Steam が返した順の番号）
+	Rows []game.ImportRowResult `json:"rows"`
+}
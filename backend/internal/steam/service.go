@@ -0,0 +1,135 @@
+package steam
+
+import (
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+
+	"TO-DO-IT/internal/game"
+)
+
+// PlatformName は、Steam から取り込んだゲームに設定するプラットフォーム名です（カタログの正式名）。
+const PlatformName = "PC"
+
+// サービス層が返すエラー。handler はこれらを見てHTTPステータスを決めます。
+var (
+	ErrInvalidSteamID   = errors.New("steam_id must be a 17-digit SteamID64")
+	ErrPrivateProfile   = errors.New("steam library is private or unavailable")
+	ErrSteamUnavailable = errors.New("failed to fetch data from Steam")
+)
+
+// steamIDPattern は SteamID64（17桁の数字）の形式です。
+var steamIDPattern = regexp.MustCompile(`^\d{17}$`)
+
+// Service は、Steam ライブラリ取り込みのビジネスロジックに関するインターフェースです。
+type Service interface {
+	// ImportLibrary は、Steam の所持ゲームを積みゲーとして登録します。
+	// すでに取り込み済みの appid（ゴミ箱のゲームを含む）は登録しません。
+	// 登録に失敗したときは1件も登録せず、失敗したゲームの番号を *game.ImportRowError で返します。
+	ImportLibrary(req *ImportRequest) (*ImportResult, error)
+}
+
+// service は Service インターフェースの具体的な実装です。
+type service struct {
+	client  Client
+	gameSvc game.Service // 担当Cのゲームサービス（ステータスの初期値などを共通化するため）
+}
+
+// NewService は、新しい service インスタンスを作成します。
+func NewService(client Client, gameSvc game.Service) Service {
+	return &service{client: client, gameSvc: gameSvc}
+}
+
+// ImportLibrary は、Steam の所持ゲームを取り込みます。
+// 一度も起動していないゲームは「未開始」、直近2週間に遊んだゲームは「プレイ中」、
+// それ以外の遊んだことがあるゲームは「一時中断」として登録します。
+func (s *service) ImportLibrary(req *ImportRequest) (*ImportResult, error) {
+	if !steamIDPattern.MatchString(req.SteamID) {
+		return nil, ErrInvalidSteamID
+	}
+
+	owned, err := s.client.GetOwnedGames(req.SteamID)
+	if err != nil {
+		return nil, err
+	}
+	recent, err := s.client.GetRecentlyPlayedGames(req.SteamID)
+	if err != nil {
+		return nil, err
+	}
+
+	recentlyPlayed := make(map[int]bool, len(recent))
+	for _, g := range recent {
+		recentlyPlayed[g.AppID] = true
+	}
+
+	// 取り込み済みの appid を集める（ゴミ箱に入れたゲームを復活させないため、削除済みも含める）
+	existing, err := s.gameSvc.GetGames(&game.GameListQuery{IncludeDeleted: true})
+	if err != nil {
+		return nil, err
+	}
+	imported := make(map[int]bool, len(existing))
+	for _, g := range existing {
+		if g.SteamAppID != 0 {
+			imported[g.SteamAppID] = true
+		}
+	}
+
+	result := &ImportResult{
+		SteamID: req.SteamID,
+		Total:   len(owned),
+		Created: []*game.Game{},
+		Skipped: []SkippedGame{},
+	}
+
+	// すべてのゲームを検証してから、まとめて登録する（途中で失敗したら1件も登録しない）
+	// 手動登録や CSV で先に登録済みのゲームは取り込まない（統合は POST /api/games/:id/merge で行う）
+	items := make([]*game.ImportItem, 0, len(owned))
+	for _, g := range owned {
+		status := game.StatusUnstarted
+		switch {
+		case recentlyPlayed[g.AppID]:
+			status = game.StatusPlaying
+		case g.PlaytimeForever > 0:
+			status = game.StatusPaused
+		}
+
+		item := &game.ImportItem{Request: &game.CreateGameRequest{
+			Title:         g.Name,
+			Platform:      PlatformName,
+			Status:        status,
+			PlayedMinutes: g.PlaytimeForever,
+			SteamAppID:    g.AppID,
+		}}
+		switch {
+		case imported[g.AppID]:
+			item.Skip = "already imported"
+		case g.Name == "":
+			item.Skip = "missing title"
+		}
+		imported[g.AppID] = true
+		items = append(items, item)
+	}
+
+	report, err := s.gameSvc.ImportRows(items, false)
+	if err != nil {
+		log.Printf("Service: Error importing steam library: %v", err)
+		return nil, err
+	}
+
+	result.Rows = report.Rows
+	for i, row := range report.Rows {
+		switch row.Result {
+		case "created":
+			result.Created = append(result.Created, row.Game)
+		case "skipped", "invalid":
+			reason := row.Reason
+			if row.Result == "invalid" {
+				reason = strings.Join(row.Errors, "; ")
+			}
+			result.Skipped = append(result.Skipped, SkippedGame{AppID: owned[i].AppID, Name: owned[i].Name, Reason: reason})
+		}
+	}
+
+	return result, nil
+}
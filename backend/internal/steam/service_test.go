@@ -0,0 +1,233 @@
+package steam
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"TO-DO-IT/internal/game"
+
+	"github.com/labstack/echo/v4"
+)
+
+// fakeGameService は、取り込みで使う GetGames / ImportRows だけを持つ game.Service です。
+// それ以外のメソッドを呼ぶと、埋め込んだ nil インターフェースで panic します。
+type fakeGameService struct {
+	game.Service
+	existing   []*game.Game
+	duplicates map[string]int // 登録済みとみなすタイトルと、そのゲームの ID
+	failRow    int            // この行の登録で失敗する（0 なら失敗しない）
+	created    []*game.CreateGameRequest
+}
+
+func (f *fakeGameService) GetGames(q *game.GameListQuery) ([]*game.Game, error) {
+	return f.existing, nil
+}
+
+func (f *fakeGameService) ImportRows(items []*game.ImportItem, dryRun bool) (*game.ImportReport, error) {
+	report := &game.ImportReport{Total: len(items)}
+	var valid []*game.CreateGameRequest
+	for i, item := range items {
+		row := game.ImportRowResult{Row: i + 1, Title: item.Request.Title, Result: "created"}
+		switch {
+		case item.Skip != "":
+			row.Result, row.Reason = "skipped", item.Skip
+		case f.duplicates[item.Request.Title] != 0:
+			row.Result, row.Reason = "skipped", fmt.Sprintf("duplicate of #%d", f.duplicates[item.Request.Title])
+		default:
+			if row.Row == f.failRow {
+				return nil, &game.ImportRowError{Row: row.Row, Err: errors.New("database is locked")}
+			}
+			req := item.Request
+			valid = append(valid, req)
+			row.Game = &game.Game{
+				ID:            len(f.created) + len(valid),
+				Title:         req.Title,
+				Platform:      req.Platform,
+				Status:        req.Status,
+				PlayedMinutes: req.PlayedMinutes,
+				SteamAppID:    req.SteamAppID,
+			}
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	f.created = append(f.created, valid...) // 失敗したときは1件も登録しない
+	return report, nil
+}
+
+// fakeSteam は、パスごとに決まったレスポンスを返す偽の Steam Web API サーバーを立てます。
+func fakeSteam(t *testing.T, responses map[string]string, status int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("steamid"); got != "76561197960287930" {
+			t.Errorf("steamid = %q", got)
+		}
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Errorf("write response: %v", err)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+const (
+	ownedPath  = "/IPlayerService/GetOwnedGames/v1/"
+	recentPath = "/IPlayerService/GetRecentlyPlayedGames/v1/"
+)
+
+// postImport は、偽の Steam サーバーに向けたハンドラに取り込みのリクエストを送ります。
+func postImport(t *testing.T, srv *httptest.Server, gameSvc game.Service) *httptest.ResponseRecorder {
+	t.Helper()
+	e := echo.New()
+	NewHandler(NewService(NewClient(srv.URL, "test-key"), gameSvc)).RegisterRoutes(e.Group("/api"))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/games/import/steam", strings.NewReader(`{"steam_id":"76561197960287930"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestImportLibrary(t *testing.T) {
+	srv := fakeSteam(t, map[string]string{
+		ownedPath: `{"response":{"game_count":6,"games":[
+			{"appid":10,"name":"Unplayed","playtime_forever":0},
+			{"appid":20,"name":"Recent","playtime_forever":300,"playtime_2weeks":60},
+			{"appid":30,"name":"Paused","playtime_forever":120},
+			{"appid":40,"name":"Already","playtime_forever":10},
+			{"appid":50,"name":"Manual","playtime_forever":0},
+			{"appid":60,"name":"","playtime_forever":0}
+		]}}`,
+		recentPath: `{"response":{"total_count":1,"games":[{"appid":20,"name":"Recent","playtime_2weeks":60}]}}`,
+	}, http.StatusOK)
+	gameSvc := &fakeGameService{
+		existing:   []*game.Game{{ID: 99, Title: "Already", SteamAppID: 40}},
+		duplicates: map[string]int{"Manual": 7},
+	}
+
+	rec := postImport(t, srv, gameSvc)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var result ImportResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Total != 6 || len(result.Rows) != 6 {
+		t.Errorf("total = %d, %d rows; want 6, 6", result.Total, len(result.Rows))
+	}
+	wantStatus := map[int]string{
+		10: game.StatusUnstarted,
+		20: game.StatusPlaying,
+		30: game.StatusPaused,
+	}
+	if len(result.Created) != len(wantStatus) {
+		t.Fatalf("created %d games, want %d", len(result.Created), len(wantStatus))
+	}
+	for _, g := range result.Created {
+		if g.Status != wantStatus[g.SteamAppID] {
+			t.Errorf("appid %d: status = %q, want %q", g.SteamAppID, g.Status, wantStatus[g.SteamAppID])
+		}
+		if g.Platform != PlatformName {
+			t.Errorf("appid %d: platform = %q, want %q", g.SteamAppID, g.Platform, PlatformName)
+		}
+	}
+	wantSkipped := []SkippedGame{
+		{AppID: 40, Name: "Already", Reason: "already imported"},
+		{AppID: 50, Name: "Manual", Reason: "duplicate of #7"},
+		{AppID: 60, Reason: "missing title"},
+	}
+	if !reflect.DeepEqual(result.Skipped, wantSkipped) {
+		t.Errorf("skipped = %+v, want %+v", result.Skipped, wantSkipped)
+	}
+}
+
+func TestImportLibraryRollsBack(t *testing.T) {
+	srv := fakeSteam(t, map[string]string{
+		ownedPath: `{"response":{"game_count":2,"games":[
+			{"appid":10,"name":"First","playtime_forever":0},
+			{"appid":20,"name":"Second","playtime_forever":0}
+		]}}`,
+		recentPath: `{"response":{"total_count":0}}`,
+	}, http.StatusOK)
+	gameSvc := &fakeGameService{failRow: 2}
+
+	rec := postImport(t, srv, gameSvc)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500 (body = %s)", rec.Code, rec.Body)
+	}
+	var body struct {
+		Row int `json:"row"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Row != 2 {
+		t.Errorf("row = %d, want 2", body.Row)
+	}
+	if len(gameSvc.created) != 0 {
+		t.Errorf("created %d games, want none", len(gameSvc.created))
+	}
+}
+
+func TestImportLibraryErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses map[string]string
+		status    int
+		wantCode  int
+	}{
+		{
+			name:      "private profile",
+			responses: map[string]string{ownedPath: `{"response":{}}`},
+			status:    http.StatusOK,
+			wantCode:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "steam returns an error",
+			status:   http.StatusInternalServerError,
+			wantCode: http.StatusBadGateway,
+		},
+		{
+			name:      "invalid json",
+			responses: map[string]string{ownedPath: `not json`},
+			status:    http.StatusOK,
+			wantCode:  http.StatusBadGateway,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gameSvc := &fakeGameService{}
+			rec := postImport(t, fakeSteam(t, tt.responses, tt.status), gameSvc)
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d (body = %s)", rec.Code, tt.wantCode, rec.Body)
+			}
+			if len(gameSvc.created) != 0 {
+				t.Errorf("created %d games, want none", len(gameSvc.created))
+			}
+		})
+	}
+}
+
+func TestImportLibraryInvalidSteamID(t *testing.T) {
+	_, err := NewService(NewClient("http://127.0.0.1:0", ""), &fakeGameService{}).ImportLibrary(&ImportRequest{SteamID: "123"})
+	if err != ErrInvalidSteamID {
+		t.Errorf("err = %v, want ErrInvalidSteamID", err)
+	}
+}
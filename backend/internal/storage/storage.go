@@ -0,0 +1,43 @@
+// Package storage は、アップロードされたファイル（ゲームのカバー画像など）の保存先を抽象化します。
+// いまはローカルのファイルシステムに保存する実装（Local）だけですが、
+// S3 などに置き換える場合も Storage インターフェースを実装すれば呼び出し側は変わりません。
+package storage
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("file not found")
+	ErrInvalidKey = errors.New("invalid storage key")
+)
+
+// Storage は、キー（"covers/12-ab34.png" のようなスラッシュ区切りのパス）でファイルを保存・取得します。
+type Storage interface {
+	// Put は data を key に保存します。同じ key のファイルがあれば上書きします。
+	Put(key string, data []byte) error
+	// Open は key のファイルを開きます。存在しなければ ErrNotFound を返します。
+	// 呼び出し側で Close してください。
+	Open(key string) (*Object, error)
+	// Delete は key のファイルを削除します。存在しなくてもエラーにはしません。
+	Delete(key string) error
+}
+
+// Object は Open で開いたファイルです。
+type Object struct {
+	io.ReadSeekCloser
+	Size    int64
+	ModTime time.Time
+}
+
+// keyPattern は、キーとして受け付ける形式です。
+// ".." や絶対パスで保存先の外を指せないよう、英数字と "-", "_", "." の要素だけを許します。
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9_.-]*(/[A-Za-z0-9_-][A-Za-z0-9_.-]*)*$`)
+
+// ValidKey は key が Storage のキーとして使えるかを返します。
+func ValidKey(key string) bool {
+	return keyPattern.MatchString(key)
+}
@@ -1 +1,243 @@
 package task
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"TO-DO-IT/internal/game"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(s Service) *Handler {
+	return &Handler{service: s}
+}
+
+// RegisterRoutes ... EchoルーターにAPIエンドポイントを登録
+func (h *Handler) RegisterRoutes(api *echo.Group) {
+	sessionApi := api.Group("/sessions") // /api/sessions
+	{
+		sessionApi.POST("", h.handleStartSession)             // {"game_id": 1, "schedule_id": "sched_..."}
+		sessionApi.GET("", h.handleGetSessions)               // ?game_id=1
+		sessionApi.GET("/current", h.handleGetCurrentSession) // プレイ中・一時停止中のセッション
+		sessionApi.POST("/:id/pause", h.handlePauseSession)
+		sessionApi.POST("/:id/resume", h.handleResumeSession)
+		sessionApi.POST("/:id/stop", h.handleStopSession)
+	}
+
+	journalApi := api.Group("/journal") // /api/journal
+	{
+		journalApi.POST("", h.handleCreateJournalEntry) // {"session_id": 1, "text": "...", "enjoyment": 4, "mood": "good"}
+		journalApi.GET("/:id", h.handleGetJournalEntry)
+		journalApi.PUT("/:id", h.handleUpdateJournalEntry)
+		journalApi.DELETE("/:id", h.handleDeleteJournalEntry)
+	}
+	// /api/games/:id より長いパスなので、game パッケージのルートとぶつからない
+	api.GET("/games/:id/timeline", h.handleGetTimeline)
+}
+
+// --- ハンドラの実装 ---
+
+func (h *Handler) handleStartSession(c echo.Context) error {
+	userID := "user_123" // 仮
+
+	var req StartSessionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	session, err := h.service.StartSession(userID, &req)
+	if err != nil {
+		return sessionError(c, err)
+	}
+	return c.JSON(http.StatusCreated, session)
+}
+
+func (h *Handler) handleGetSessions(c echo.Context) error {
+	userID := "user_123" // 仮
+
+	gameID := 0
+	if v := c.QueryParam("game_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "game_id must be an integer"})
+		}
+		gameID = id
+	}
+
+	sessions, err := h.service.GetSessions(userID, gameID)
+	if err != nil {
+		return sessionError(c, err)
+	}
+	return c.JSON(http.StatusOK, sessions)
+}
+
+func (h *Handler) handleGetCurrentSession(c echo.Context) error {
+	userID := "user_123" // 仮
+
+	session, err := h.service.GetCurrentSession(userID)
+	if err != nil {
+		return sessionError(c, err)
+	}
+	if session == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "no play session in progress"})
+	}
+	return c.JSON(http.StatusOK, session)
+}
+
+func (h *Handler) handlePauseSession(c echo.Context) error {
+	return h.changeSession(c, h.service.PauseSession)
+}
+
+func (h *Handler) handleResumeSession(c echo.Context) error {
+	return h.changeSession(c, h.service.ResumeSession)
+}
+
+// changeSession ... 一時停止・再開の共通処理
+func (h *Handler) changeSession(c echo.Context, fn func(userID string, id int) (*PlaySession, error)) error {
+	userID := "user_123" // 仮
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid session id"})
+	}
+
+	session, err := fn(userID, id)
+	if err != nil {
+		return sessionError(c, err)
+	}
+	if session == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "play session not found"})
+	}
+	return c.JSON(http.StatusOK, session)
+}
+
+// handleStopSession ... セッションを終え、実際のプレイ時間とポイントを反映する
+func (h *Handler) handleStopSession(c echo.Context) error {
+	userID := "user_123" // 仮
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid session id"})
+	}
+
+	res, err := h.service.StopSession(userID, id)
+	if err != nil {
+		return sessionError(c, err)
+	}
+	if res == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "play session not found"})
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) handleCreateJournalEntry(c echo.Context) error {
+	userID := "user_123" // 仮
+
+	var req JournalRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	entry, err := h.service.CreateJournalEntry(userID, &req)
+	if err != nil {
+		return sessionError(c, err)
+	}
+	return c.JSON(http.StatusCreated, entry)
+}
+
+func (h *Handler) handleGetJournalEntry(c echo.Context) error {
+	userID := "user_123" // 仮
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid journal entry id"})
+	}
+
+	entry, err := h.service.GetJournalEntry(userID, id)
+	if err != nil {
+		return sessionError(c, err)
+	}
+	if entry == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "journal entry not found"})
+	}
+	return c.JSON(http.StatusOK, entry)
+}
+
+func (h *Handler) handleUpdateJournalEntry(c echo.Context) error {
+	userID := "user_123" // 仮
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid journal entry id"})
+	}
+	var req JournalUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	entry, err := h.service.UpdateJournalEntry(userID, id, &req)
+	if err != nil {
+		return sessionError(c, err)
+	}
+	if entry == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "journal entry not found"})
+	}
+	return c.JSON(http.StatusOK, entry)
+}
+
+func (h *Handler) handleDeleteJournalEntry(c echo.Context) error {
+	userID := "user_123" // 仮
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid journal entry id"})
+	}
+
+	if err := h.service.DeleteJournalEntry(userID, id); err != nil {
+		return sessionError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// handleGetTimeline ... ゲームのセッション・日記・ステータス変更を新しい順に返す
+func (h *Handler) handleGetTimeline(c echo.Context) error {
+	userID := "user_123" // 仮
+
+	gameID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid game id"})
+	}
+
+	timeline, err := h.service.GetTimeline(userID, gameID)
+	if err != nil {
+		return sessionError(c, err)
+	}
+	if timeline == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Game not found"})
+	}
+	return c.JSON(http.StatusOK, timeline)
+}
+
+// sessionError ... セッション・日記の操作のエラーをレスポンスに変換する
+func sessionError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrGameNotFound), errors.Is(err, ErrInvalidSchedule),
+		errors.Is(err, ErrJournalLinkRequired), errors.Is(err, ErrJournalLinkNotFound),
+		errors.Is(err, ErrJournalLinkMismatch), errors.Is(err, ErrInvalidEnjoyment),
+		errors.Is(err, ErrInvalidMood), errors.Is(err, ErrEmptyJournalEntry):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrSessionInProgress), errors.Is(err, ErrSessionNotRunning),
+		errors.Is(err, ErrSessionNotPaused), errors.Is(err, ErrSessionEnded),
+		errors.Is(err, game.ErrInvalidTransition): // ゲームのステータスを変えられない
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
+	log.Printf("Task: Error handling play session or journal: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to process the request"})
+}
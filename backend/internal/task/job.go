@@ -0,0 +1,27 @@
+package task
+
+import (
+	"log"
+	"time"
+)
+
+// StartAutoCloseJob ... 止め忘れたセッションを interval ごとに自動的に終了するジョブを、バックグラウンドで動かす
+// (セッションの API を呼んだときにも終了するので、これは API を使わない間の取りこぼし用)。返り値の関数でジョブを止める
+func StartAutoCloseJob(svc Service, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if _, err := svc.CloseStaleSessions(); err != nil {
+				log.Printf("Task: Error closing stale play sessions: %v", err)
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() { close(done) }
+}
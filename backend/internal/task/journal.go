@@ -0,0 +1,173 @@
+package task
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"TO-DO-IT/internal/game"
+)
+
+// CreateJournalEntry ... 紐づけ先 (スケジュール・セッション) を確かめてから日記を保存する
+func (s *service) CreateJournalEntry(userID string, req *JournalRequest) (*game.JournalEntry, error) {
+	if req.ScheduleID == "" && req.SessionID == 0 {
+		return nil, ErrJournalLinkRequired
+	}
+	entry := &game.JournalEntry{
+		UserID:       userID,
+		ScheduleID:   req.ScheduleID,
+		SessionID:    req.SessionID,
+		Text:         strings.TrimSpace(req.Text),
+		Enjoyment:    req.Enjoyment,
+		Mood:         req.Mood,
+		ProgressNote: strings.TrimSpace(req.ProgressNote),
+	}
+	if err := validateJournalEntry(entry); err != nil {
+		return nil, err
+	}
+
+	if req.SessionID != 0 {
+		session, err := s.repo.GetSessionByID(req.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		if session == nil || session.UserID != userID {
+			return nil, ErrJournalLinkNotFound
+		}
+		// セッションだけ指定されたら、セッションのスケジュールにも紐づける (スケジュールは確認済み)
+		if entry.ScheduleID == "" {
+			entry.ScheduleID = session.ScheduleID
+		} else if session.ScheduleID != "" && session.ScheduleID != entry.ScheduleID {
+			return nil, ErrJournalLinkMismatch
+		}
+		entry.GameID = session.GameID
+	}
+	if req.ScheduleID != "" {
+		schedule, err := s.calendarRepo.GetScheduleByID(req.ScheduleID)
+		if err != nil {
+			return nil, err
+		}
+		if schedule == nil || schedule.UserID != userID {
+			return nil, ErrJournalLinkNotFound
+		}
+		gameID, err := strconv.Atoi(schedule.GameID)
+		if err != nil {
+			return nil, ErrJournalLinkNotFound
+		}
+		if entry.GameID != 0 && entry.GameID != gameID {
+			return nil, ErrJournalLinkMismatch
+		}
+		entry.GameID = gameID
+	}
+
+	now := time.Now()
+	entry.CreatedAt = now
+	entry.UpdatedAt = now
+	id, err := s.repo.CreateJournalEntry(entry)
+	if err != nil {
+		return nil, err
+	}
+	entry.ID = id
+	return entry, nil
+}
+
+func (s *service) GetJournalEntry(userID string, id int) (*game.JournalEntry, error) {
+	entry, err := s.repo.GetJournalEntryByID(id)
+	if err != nil || entry == nil || entry.UserID != userID {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *service) UpdateJournalEntry(userID string, id int, req *JournalUpdateRequest) (*game.JournalEntry, error) {
+	entry, err := s.GetJournalEntry(userID, id)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	entry.Text = strings.TrimSpace(req.Text)
+	entry.Enjoyment = req.Enjoyment
+	entry.Mood = req.Mood
+	entry.ProgressNote = strings.TrimSpace(req.ProgressNote)
+	if err := validateJournalEntry(entry); err != nil {
+		return nil, err
+	}
+	entry.UpdatedAt = time.Now()
+	if err := s.repo.UpdateJournalEntry(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *service) DeleteJournalEntry(userID string, id int) error {
+	entry, err := s.GetJournalEntry(userID, id)
+	if err != nil || entry == nil {
+		return err // 見つからなければ何もしない
+	}
+	return s.repo.DeleteJournalEntry(id)
+}
+
+// validateJournalEntry ... 日記の中身を確かめる
+//   - 楽しさは 0 (未評価) か 1〜MaxEnjoyment
+//   - 気分は空か JournalMoods のいずれか
+//   - 何も書いていない日記は保存しない
+func validateJournalEntry(entry *game.JournalEntry) error {
+	if entry.Enjoyment < 0 || entry.Enjoyment > MaxEnjoyment {
+		return ErrInvalidEnjoyment
+	}
+	if entry.Mood != "" && !slices.Contains(JournalMoods, entry.Mood) {
+		return ErrInvalidMood
+	}
+	if entry.Text == "" && entry.Enjoyment == 0 && entry.Mood == "" && entry.ProgressNote == "" {
+		return ErrEmptyJournalEntry
+	}
+	return nil
+}
+
+// GetTimeline ... 終了したセッション・日記・ステータス変更を1つの時系列にまとめる
+func (s *service) GetTimeline(userID string, gameID int) (*Timeline, error) {
+	g, err := s.gameRepo.GetGameByID(gameID)
+	if err != nil || g == nil {
+		return nil, err
+	}
+
+	items := []*TimelineItem{}
+	sessions, err := s.repo.GetSessions(userID, gameID)
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		if session.EndedAt != nil { // 終わっていないセッションは GET /api/sessions/current で見る
+			items = append(items, &TimelineItem{Kind: TimelineKindSession, At: *session.EndedAt, Session: session})
+		}
+	}
+
+	entries, err := s.repo.GetJournalEntriesByGameID(gameID, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.UserID == userID {
+			items = append(items, &TimelineItem{Kind: TimelineKindJournal, At: entry.CreatedAt, Journal: entry})
+		}
+	}
+
+	histories, err := s.gameRepo.GetStatusHistoryByGameID(gameID)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range histories {
+		items = append(items, &TimelineItem{Kind: TimelineKindStatusChange, At: h.ChangedAt, StatusChange: h})
+	}
+
+	// 同じ時刻なら、元の並び (セッション → 日記 → ステータス) を保つ
+	sort.SliceStable(items, func(i, j int) bool { return items[i].At.After(items[j].At) })
+	return &Timeline{GameID: gameID, Items: items}, nil
+}
+
+// LatestJournalEntries ... ゲームの日記を新しい順に limit 件まで返す
+func (s *service) LatestJournalEntries(gameID int, limit int) ([]*game.JournalEntry, error) {
+	return s.repo.GetJournalEntriesByGameID(gameID, limit)
+}
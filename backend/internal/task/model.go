@@ -1 +1,128 @@
 package task
+
+import (
+	"time"
+
+	"TO-DO-IT/internal/game"
+	"TO-DO-IT/internal/score"
+)
+
+// PlaySession (プレイセッション) [cite: 96-101]
+// タイマーで記録した、実際にゲームを遊んだ時間 (play_sessions テーブル)
+type PlaySession struct {
+	ID         int    `json:"id"`
+	UserID     string `json:"user_id"`
+	GameID     int    `json:"game_id"`
+	ScheduleID string `json:"schedule_id"` // スケジュールに紐づけて始めた場合のみ。なければ空文字
+	Status     string `json:"status"`      // running, paused, finished, auto_closed
+
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`  // 終了していなければ null
+	PausedAt  *time.Time `json:"paused_at"` // 一時停止中なら、止めた時刻
+	// PausedSeconds ... これまでの一時停止の合計 (秒)。今の一時停止は含まない
+	PausedSeconds int `json:"paused_seconds"`
+
+	// Minutes ... 実際にプレイした時間 (分)。終了前は、その時点までの時間
+	Minutes int `json:"minutes"`
+	Points  int `json:"points"` // 終了時に付与したポイント
+}
+
+// セッションのステータス
+const (
+	SessionStatusRunning    = "running"     // プレイ中
+	SessionStatusPaused     = "paused"      // 一時停止中
+	SessionStatusFinished   = "finished"    // 終了
+	SessionStatusAutoClosed = "auto_closed" // 止め忘れを、上限の時間で自動的に終了した
+)
+
+// AutoCloseAfter ... プレイ中・一時停止中のまま、これより長く経ったセッションは自動的に終了する
+const AutoCloseAfter = 6 * time.Hour
+
+// active ... まだ終わっていない (プレイ中・一時停止中) か
+func (s *PlaySession) active() bool {
+	return s.Status == SessionStatusRunning || s.Status == SessionStatusPaused
+}
+
+// playedDuration ... at までに実際にプレイした時間 (一時停止中の時間を除く)
+func (s *PlaySession) playedDuration(at time.Time) time.Duration {
+	end := at
+	switch {
+	case s.EndedAt != nil:
+		end = *s.EndedAt
+	case s.PausedAt != nil:
+		end = *s.PausedAt
+	}
+	d := end.Sub(s.StartedAt) - time.Duration(s.PausedSeconds)*time.Second
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// StartSessionRequest ... セッションの開始 (POST /api/sessions) のリクエストボディ
+type StartSessionRequest struct {
+	GameID     int    `json:"game_id"`
+	ScheduleID string `json:"schedule_id"` // 任意。スケジュールの予定どおりに遊ぶとき
+}
+
+// StopSessionResponse ... セッションの終了 (POST /api/sessions/:id/stop) のレスポンス
+type StopSessionResponse struct {
+	Session    *PlaySession      `json:"session"`
+	Motivation *score.Motivation `json:"motivation"` // ポイント反映後
+}
+
+// プレイ日記 (game.JournalEntry) の気分
+const (
+	MoodGreat      = "great"      // 最高
+	MoodGood       = "good"       // 良い
+	MoodNeutral    = "neutral"    // ふつう
+	MoodTired      = "tired"      // 疲れた
+	MoodFrustrated = "frustrated" // イライラした
+)
+
+// JournalMoods ... 日記に付けられる気分の一覧
+var JournalMoods = []string{MoodGreat, MoodGood, MoodNeutral, MoodTired, MoodFrustrated}
+
+// MaxEnjoyment ... 日記の楽しさの最大値 (0 は未評価)
+const MaxEnjoyment = 5
+
+// JournalRequest ... 日記の作成 (POST /api/journal) のリクエストボディ
+// schedule_id と session_id の少なくとも一方が必要。セッションだけ指定したときは、セッションのスケジュールに紐づく
+type JournalRequest struct {
+	ScheduleID   string `json:"schedule_id"`
+	SessionID    int    `json:"session_id"`
+	Text         string `json:"text"`
+	Enjoyment    int    `json:"enjoyment"`
+	Mood         string `json:"mood"`
+	ProgressNote string `json:"progress_note"`
+}
+
+// JournalUpdateRequest ... 日記の更新 (PUT /api/journal/:id) のリクエストボディ。紐づけ先は変えられない
+type JournalUpdateRequest struct {
+	Text         string `json:"text"`
+	Enjoyment    int    `json:"enjoyment"`
+	Mood         string `json:"mood"`
+	ProgressNote string `json:"progress_note"`
+}
+
+// タイムラインの項目の種類
+const (
+	TimelineKindSession      = "session"       // プレイセッション (終了したもの)
+	TimelineKindJournal      = "journal"       // 日記
+	TimelineKindStatusChange = "status_change" // ステータスの変更
+)
+
+// TimelineItem ... タイムラインの1項目。Kind に応じて、Session / Journal / StatusChange のどれか1つが入る
+type TimelineItem struct {
+	Kind         string              `json:"kind"`
+	At           time.Time           `json:"at"` // セッションは終了時刻、日記は作成時刻、ステータスは変更時刻
+	Session      *PlaySession        `json:"session,omitempty"`
+	Journal      *game.JournalEntry  `json:"journal,omitempty"`
+	StatusChange *game.StatusHistory `json:"status_change,omitempty"`
+}
+
+// Timeline ... ゲームごとのタイムライン (GET /api/games/:id/timeline) のレスポンス
+type Timeline struct {
+	GameID int             `json:"game_id"`
+	Items  []*TimelineItem `json:"items"` // 新しい順
+}
@@ -1 +1,364 @@
 package task
+
+import (
+	"database/sql"
+	"time"
+
+	"TO-DO-IT/internal/game"
+)
+
+// Repository (インターフェース)
+type Repository interface {
+	// プレイセッション (PlaySession)
+	CreateSession(session *PlaySession) (int, error)
+	// GetSessionByID ... 見つからなければ nil を返す (エラーではない)
+	GetSessionByID(id int) (*PlaySession, error)
+	// GetActiveSession ... ユーザーのプレイ中・一時停止中のセッションを返す。なければ nil
+	GetActiveSession(userID string) (*PlaySession, error)
+	// GetActiveSessions ... 全ユーザーのプレイ中・一時停止中のセッション (止め忘れの確認用)
+	GetActiveSessions() ([]*PlaySession, error)
+	// GetSessions ... ユーザーのセッションを新しい順に返す。gameID が0ならすべてのゲーム
+	GetSessions(userID string, gameID int) ([]*PlaySession, error)
+	// UpdateSession ... まだ終了していないセッションだけ更新する
+	// 読み込んだ後に他のリクエスト (自動終了など) が終了させていたら ErrSessionEnded
+	UpdateSession(session *PlaySession) error
+	// DeleteSessionsByGameID ... ゲームのセッションをすべて削除し、件数を返す (ゲームの完全削除用)
+	DeleteSessionsByGameID(gameID int) (int64, error)
+	// MoveSessions ... ゲームのセッションをすべて別のゲームに付け替え、件数を返す (ゲームの統合用)
+	MoveSessions(fromGameID int, toGameID int) (int64, error)
+
+	// プレイ日記 (game.JournalEntry)
+	CreateJournalEntry(entry *game.JournalEntry) (int, error)
+	// GetJournalEntryByID ... 見つからなければ nil を返す (エラーではない)
+	GetJournalEntryByID(id int) (*game.JournalEntry, error)
+	// GetJournalEntriesByGameID ... ゲームの日記を新しい順に返す。limit が0ならすべて
+	GetJournalEntriesByGameID(gameID int, limit int) ([]*game.JournalEntry, error)
+	// GetJournalEntriesByUserID ... ユーザーのすべてのゲームの日記を新しい順に返す
+	GetJournalEntriesByUserID(userID string) ([]*game.JournalEntry, error)
+	UpdateJournalEntry(entry *game.JournalEntry) error
+	DeleteJournalEntry(id int) error
+	// DeleteJournalEntriesByGameID ... ゲームの日記をすべて削除し、件数を返す (ゲームの完全削除用)
+	DeleteJournalEntriesByGameID(gameID int) (int64, error)
+	// MoveJournalEntries ... ゲームの日記をすべて別のゲームに付け替え、件数を返す (ゲームの統合用)
+	MoveJournalEntries(fromGameID int, toGameID int) (int64, error)
+
+	// 集計
+	// GetPlayedMinutes ... 開始時刻が from 以上 to 未満の、終了したセッションのプレイ時間 (分) の合計
+	GetPlayedMinutes(userID string, from time.Time, to time.Time) (int, error)
+	// GetLastPlayedTimes ... ゲームごとの、最後に終了したセッションの終了時刻
+	GetLastPlayedTimes(userID string) (map[int]time.Time, error)
+
+	// トランザクション
+	// WithTx ... game など他パッケージと同じトランザクション上で動くリポジトリを返す
+	WithTx(tx *sql.Tx) Repository
+	// RunInTx ... fn をトランザクション内で実行する。fn がエラーを返すとロールバック
+	RunInTx(fn func(tx *sql.Tx) error) error
+}
+
+// querier ... *sql.DB と *sql.Tx の共通メソッド
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// postgresRepository (実装)
+type postgresRepository struct {
+	db *sql.DB
+	tx *sql.Tx // トランザクション中のみ
+	q  querier // 実際にSQLを発行する先 (db か tx)
+}
+
+// NewRepository ... DB接続を受け取り、リポジトリを初期化
+func NewRepository(db *sql.DB) Repository {
+	return &postgresRepository{db: db, q: db}
+}
+
+func (r *postgresRepository) WithTx(tx *sql.Tx) Repository {
+	return &postgresRepository{db: r.db, tx: tx, q: tx}
+}
+
+func (r *postgresRepository) RunInTx(fn func(tx *sql.Tx) error) error {
+	// すでにトランザクション中ならそのまま使う
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// sessionColumns ... play_sessions テーブルから取得するカラム。scanSession と順番を合わせる
+const sessionColumns = `id, user_id, game_id, schedule_id, status, started_at, ended_at, paused_at, paused_seconds, minutes, points`
+
+// rowScanner ... *sql.Row と *sql.Rows の共通メソッド
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSession(row rowScanner) (*PlaySession, error) {
+	var s PlaySession
+	var endedAt, pausedAt sql.NullTime
+	if err := row.Scan(&s.ID, &s.UserID, &s.GameID, &s.ScheduleID, &s.Status, &s.StartedAt,
+		&endedAt, &pausedAt, &s.PausedSeconds, &s.Minutes, &s.Points); err != nil {
+		return nil, err
+	}
+	if endedAt.Valid {
+		s.EndedAt = &endedAt.Time
+	}
+	if pausedAt.Valid {
+		s.PausedAt = &pausedAt.Time
+	}
+	return &s, nil
+}
+
+func (r *postgresRepository) querySessions(query string, args ...any) ([]*PlaySession, error) {
+	rows, err := r.q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*PlaySession{}
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// --- プレイセッション (PlaySession) の実装 ---
+
+func (r *postgresRepository) CreateSession(session *PlaySession) (int, error) {
+	query := `INSERT INTO play_sessions (user_id, game_id, schedule_id, status, started_at, paused_seconds, minutes, points)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := r.q.Exec(query, session.UserID, session.GameID, session.ScheduleID, session.Status,
+		session.StartedAt, session.PausedSeconds, session.Minutes, session.Points)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+func (r *postgresRepository) GetSessionByID(id int) (*PlaySession, error) {
+	query := `SELECT ` + sessionColumns + ` FROM play_sessions WHERE id = ?`
+	s, err := scanSession(r.q.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return s, err
+}
+
+func (r *postgresRepository) GetActiveSession(userID string) (*PlaySession, error) {
+	query := `SELECT ` + sessionColumns + ` FROM play_sessions
+			  WHERE user_id = ? AND status IN ('running', 'paused')
+			  ORDER BY id DESC LIMIT 1`
+	s, err := scanSession(r.q.QueryRow(query, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return s, err
+}
+
+func (r *postgresRepository) GetActiveSessions() ([]*PlaySession, error) {
+	query := `SELECT ` + sessionColumns + ` FROM play_sessions
+			  WHERE status IN ('running', 'paused') ORDER BY id`
+	return r.querySessions(query)
+}
+
+func (r *postgresRepository) GetSessions(userID string, gameID int) ([]*PlaySession, error) {
+	query := `SELECT ` + sessionColumns + ` FROM play_sessions WHERE user_id = ?`
+	args := []any{userID}
+	if gameID != 0 {
+		query += ` AND game_id = ?`
+		args = append(args, gameID)
+	}
+	query += ` ORDER BY julianday(started_at) DESC, id DESC`
+	return r.querySessions(query, args...)
+}
+
+func (r *postgresRepository) UpdateSession(session *PlaySession) error {
+	query := `UPDATE play_sessions SET game_id = ?, schedule_id = ?, status = ?, started_at = ?, ended_at = ?, paused_at = ?,
+			  paused_seconds = ?, minutes = ?, points = ?
+			  WHERE id = ? AND ended_at IS NULL`
+	result, err := r.q.Exec(query, session.GameID, session.ScheduleID, session.Status, session.StartedAt, session.EndedAt,
+		session.PausedAt, session.PausedSeconds, session.Minutes, session.Points, session.ID)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrSessionEnded
+	}
+	return nil
+}
+
+func (r *postgresRepository) DeleteSessionsByGameID(gameID int) (int64, error) {
+	result, err := r.q.Exec(`DELETE FROM play_sessions WHERE game_id = ?`, gameID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *postgresRepository) MoveSessions(fromGameID int, toGameID int) (int64, error) {
+	result, err := r.q.Exec(`UPDATE play_sessions SET game_id = ? WHERE game_id = ?`, toGameID, fromGameID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// --- プレイ日記 (game.JournalEntry) の実装 ---
+
+// journalColumns ... journal_entries テーブルから取得するカラム。scanJournalEntry と順番を合わせる
+const journalColumns = `id, user_id, game_id, schedule_id, session_id, text, enjoyment, mood, progress_note, created_at, updated_at`
+
+func scanJournalEntry(row rowScanner) (*game.JournalEntry, error) {
+	var e game.JournalEntry
+	if err := row.Scan(&e.ID, &e.UserID, &e.GameID, &e.ScheduleID, &e.SessionID, &e.Text, &e.Enjoyment,
+		&e.Mood, &e.ProgressNote, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *postgresRepository) CreateJournalEntry(entry *game.JournalEntry) (int, error) {
+	query := `INSERT INTO journal_entries (user_id, game_id, schedule_id, session_id, text, enjoyment, mood, progress_note, created_at, updated_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := r.q.Exec(query, entry.UserID, entry.GameID, entry.ScheduleID, entry.SessionID, entry.Text,
+		entry.Enjoyment, entry.Mood, entry.ProgressNote, entry.CreatedAt, entry.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+func (r *postgresRepository) GetJournalEntryByID(id int) (*game.JournalEntry, error) {
+	query := `SELECT ` + journalColumns + ` FROM journal_entries WHERE id = ?`
+	e, err := scanJournalEntry(r.q.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return e, err
+}
+
+func (r *postgresRepository) GetJournalEntriesByGameID(gameID int, limit int) ([]*game.JournalEntry, error) {
+	query := `SELECT ` + journalColumns + ` FROM journal_entries WHERE game_id = ?
+			  ORDER BY julianday(created_at) DESC, id DESC`
+	args := []any{gameID}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	return r.queryJournalEntries(query, args...)
+}
+
+func (r *postgresRepository) GetJournalEntriesByUserID(userID string) ([]*game.JournalEntry, error) {
+	query := `SELECT ` + journalColumns + ` FROM journal_entries WHERE user_id = ?
+			  ORDER BY julianday(created_at) DESC, id DESC`
+	return r.queryJournalEntries(query, userID)
+}
+
+func (r *postgresRepository) queryJournalEntries(query string, args ...any) ([]*game.JournalEntry, error) {
+	rows, err := r.q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*game.JournalEntry{}
+	for rows.Next() {
+		e, err := scanJournalEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *postgresRepository) UpdateJournalEntry(entry *game.JournalEntry) error {
+	query := `UPDATE journal_entries SET text = ?, enjoyment = ?, mood = ?, progress_note = ?, updated_at = ?
+			  WHERE id = ?`
+	_, err := r.q.Exec(query, entry.Text, entry.Enjoyment, entry.Mood, entry.ProgressNote, entry.UpdatedAt, entry.ID)
+	return err
+}
+
+func (r *postgresRepository) DeleteJournalEntry(id int) error {
+	_, err := r.q.Exec(`DELETE FROM journal_entries WHERE id = ?`, id)
+	return err
+}
+
+func (r *postgresRepository) DeleteJournalEntriesByGameID(gameID int) (int64, error) {
+	result, err := r.q.Exec(`DELETE FROM journal_entries WHERE game_id = ?`, gameID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *postgresRepository) MoveJournalEntries(fromGameID int, toGameID int) (int64, error) {
+	result, err := r.q.Exec(`UPDATE journal_entries SET game_id = ? WHERE game_id = ?`, toGameID, fromGameID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// --- 集計 ---
+
+func (r *postgresRepository) GetPlayedMinutes(userID string, from time.Time, to time.Time) (int, error) {
+	query := `SELECT COALESCE(SUM(minutes), 0) FROM play_sessions
+			  WHERE user_id = ? AND status IN ('finished', 'auto_closed')
+			  AND julianday(started_at) >= julianday(?) AND julianday(started_at) < julianday(?)`
+	var minutes int
+	err := r.q.QueryRow(query, userID, from, to).Scan(&minutes)
+	return minutes, err
+}
+
+func (r *postgresRepository) GetLastPlayedTimes(userID string) (map[int]time.Time, error) {
+	// MAX() の結果は DATETIME 型にならず文字列で返るので、ゲームごとに最新の1件を取り出す
+	query := `SELECT game_id, ended_at FROM play_sessions p
+			  WHERE user_id = ? AND ended_at IS NOT NULL
+			  AND NOT EXISTS (SELECT 1 FROM play_sessions n WHERE n.game_id = p.game_id AND n.user_id = p.user_id
+			                  AND n.ended_at IS NOT NULL AND julianday(n.ended_at) > julianday(p.ended_at))`
+	rows, err := r.q.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	last := map[int]time.Time{}
+	for rows.Next() {
+		var gameID int
+		var endedAt time.Time
+		if err := rows.Scan(&gameID, &endedAt); err != nil {
+			return nil, err
+		}
+		last[gameID] = endedAt
+	}
+	return last, rows.Err()
+}
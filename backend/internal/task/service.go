@@ -1 +1,403 @@
 package task
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"TO-DO-IT/internal/calendar"
+	"TO-DO-IT/internal/game"
+	"TO-DO-IT/internal/score"
+)
+
+var (
+	ErrSessionInProgress = errors.New("another play session is already in progress (stop it first)")
+	ErrSessionNotRunning = errors.New("play session is not running")
+	ErrSessionNotPaused  = errors.New("play session is not paused")
+	ErrSessionEnded      = errors.New("play session has already ended")
+	ErrGameNotFound      = errors.New("game_id does not match any game")
+	ErrInvalidSchedule   = errors.New("schedule_id must be a pending schedule of the same game")
+
+	// プレイ日記
+	ErrJournalLinkRequired = errors.New("schedule_id or session_id is required")
+	ErrJournalLinkNotFound = errors.New("schedule_id or session_id does not match any schedule or play session")
+	ErrJournalLinkMismatch = errors.New("schedule_id and session_id must refer to the same schedule")
+	ErrInvalidEnjoyment    = errors.New("enjoyment must be between 1 and 5")
+	ErrInvalidMood         = errors.New("mood must be one of great, good, neutral, tired, frustrated")
+	ErrEmptyJournalEntry   = errors.New("journal entry must have text, enjoyment, mood or progress_note")
+)
+
+// Service (インターフェース)
+// 他のユーザーのセッションは、見つからない (nil) 扱い
+type Service interface {
+	// StartSession ... ゲームのセッションを始める。未開始・中断中のゲームは「プレイ中」になる
+	StartSession(userID string, req *StartSessionRequest) (*PlaySession, error)
+	// GetCurrentSession ... プレイ中・一時停止中のセッションを返す。なければ nil
+	GetCurrentSession(userID string) (*PlaySession, error)
+	// GetSessions ... セッションを新しい順に返す。gameID が0ならすべてのゲーム
+	GetSessions(userID string, gameID int) ([]*PlaySession, error)
+	PauseSession(userID string, id int) (*PlaySession, error)
+	ResumeSession(userID string, id int) (*PlaySession, error)
+	// StopSession ... セッションを終え、実際のプレイ時間をゲームの累計プレイ時間とポイントに反映する
+	// スケジュールに紐づいていれば、そのスケジュールを完了にする
+	StopSession(userID string, id int) (*StopSessionResponse, error)
+	// CloseStaleSessions ... AutoCloseAfter より長く放置されたセッションを自動的に終了し、件数を返す
+	CloseStaleSessions() (int, error)
+
+	// プレイ日記
+	// CreateJournalEntry ... スケジュールかセッションに紐づけて日記を書く。ゲームは紐づけ先から決まる
+	CreateJournalEntry(userID string, req *JournalRequest) (*game.JournalEntry, error)
+	// GetJournalEntry / UpdateJournalEntry ... 見つからなければ nil
+	GetJournalEntry(userID string, id int) (*game.JournalEntry, error)
+	UpdateJournalEntry(userID string, id int, req *JournalUpdateRequest) (*game.JournalEntry, error)
+	// DeleteJournalEntry ... 見つからなくてもエラーにしない
+	DeleteJournalEntry(userID string, id int) error
+	// GetTimeline ... ゲームのセッション・日記・ステータス変更を新しい順に並べる。ゲームがなければ nil
+	GetTimeline(userID string, gameID int) (*Timeline, error)
+	// game.JournalProvider の実装 (ゲーム詳細に載せる最新の日記)
+	LatestJournalEntries(gameID int, limit int) ([]*game.JournalEntry, error)
+
+	// game.PurgeListener の実装
+	// ゲームが完全に削除されたら、そのゲームのセッション・日記も消す
+	OnGamePurged(tx *sql.Tx, g *game.Game) error
+	// game.MergeListener の実装
+	// ゲームが統合されたら、統合元のセッション・日記を統合先に付け替える
+	OnGamesMerged(tx *sql.Tx, target *game.Game, source *game.Game) error
+}
+
+// service (実装)
+type service struct {
+	repo         Repository
+	gameRepo     game.Repository     // 累計プレイ時間の更新に使う (担当C)
+	gameSvc      game.Service        // ステータス変更 (履歴つき) に使う (担当C)
+	calendarRepo calendar.Repository // 紐づけたスケジュールの確認・完了に使う
+	scoreSvc     score.Service       // ポイントの付与に使う
+}
+
+// NewService ... 必要なリポジトリ・サービスを受け取り、サービスを初期化
+func NewService(repo Repository, gameRepo game.Repository, gameSvc game.Service, calendarRepo calendar.Repository, scoreSvc score.Service) Service {
+	return &service{
+		repo:         repo,
+		gameRepo:     gameRepo,
+		gameSvc:      gameSvc,
+		calendarRepo: calendarRepo,
+		scoreSvc:     scoreSvc,
+	}
+}
+
+func (s *service) StartSession(userID string, req *StartSessionRequest) (*PlaySession, error) {
+	if _, err := s.CloseStaleSessions(); err != nil {
+		return nil, err
+	}
+
+	var session *PlaySession
+	err := s.repo.RunInTx(func(tx *sql.Tx) error {
+		repo := s.repo.WithTx(tx)
+		active, err := repo.GetActiveSession(userID)
+		if err != nil {
+			return err
+		}
+		if active != nil {
+			return ErrSessionInProgress
+		}
+
+		g, err := s.gameRepo.WithTx(tx).GetGameByID(req.GameID)
+		if err != nil {
+			return err
+		}
+		if g == nil {
+			return ErrGameNotFound
+		}
+		if req.ScheduleID != "" {
+			schedule, err := s.calendarRepo.WithTx(tx).GetScheduleByID(req.ScheduleID)
+			if err != nil {
+				return err
+			}
+			if schedule == nil || schedule.UserID != userID || schedule.GameID != strconv.Itoa(g.ID) ||
+				schedule.Status != calendar.ScheduleStatusPending {
+				return ErrInvalidSchedule
+			}
+		}
+
+		// 遊び始めたゲームは「プレイ中」にする (クリア済み・やめたゲームの遊び直しは、ステータスを変えない)
+		if g.Status == game.StatusUnstarted || g.Status == game.StatusPaused {
+			if _, _, err := s.gameSvc.ChangeStatusInTx(tx, g.ID, 0, game.StatusPlaying, "play session started"); err != nil {
+				return err
+			}
+		}
+
+		session = &PlaySession{
+			UserID:     userID,
+			GameID:     g.ID,
+			ScheduleID: req.ScheduleID,
+			Status:     SessionStatusRunning,
+			StartedAt:  time.Now(),
+		}
+		session.ID, err = repo.CreateSession(session)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (s *service) GetCurrentSession(userID string) (*PlaySession, error) {
+	if _, err := s.CloseStaleSessions(); err != nil {
+		return nil, err
+	}
+	session, err := s.repo.GetActiveSession(userID)
+	if err != nil || session == nil {
+		return nil, err
+	}
+	return withCurrentMinutes(session, time.Now()), nil
+}
+
+func (s *service) GetSessions(userID string, gameID int) ([]*PlaySession, error) {
+	if _, err := s.CloseStaleSessions(); err != nil {
+		return nil, err
+	}
+	sessions, err := s.repo.GetSessions(userID, gameID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, session := range sessions {
+		withCurrentMinutes(session, now)
+	}
+	return sessions, nil
+}
+
+func (s *service) PauseSession(userID string, id int) (*PlaySession, error) {
+	return s.updateSession(userID, id, func(session *PlaySession, now time.Time) error {
+		if session.Status != SessionStatusRunning {
+			return ErrSessionNotRunning
+		}
+		session.Status = SessionStatusPaused
+		session.PausedAt = &now
+		return nil
+	})
+}
+
+func (s *service) ResumeSession(userID string, id int) (*PlaySession, error) {
+	return s.updateSession(userID, id, func(session *PlaySession, now time.Time) error {
+		if session.Status != SessionStatusPaused {
+			return ErrSessionNotPaused
+		}
+		session.PausedSeconds += int(now.Sub(*session.PausedAt).Seconds())
+		session.PausedAt = nil
+		session.Status = SessionStatusRunning
+		return nil
+	})
+}
+
+// updateSession ... 自動終了を済ませてから、ユーザーのセッションを fn で変更して保存する
+func (s *service) updateSession(userID string, id int, fn func(session *PlaySession, now time.Time) error) (*PlaySession, error) {
+	if _, err := s.CloseStaleSessions(); err != nil {
+		return nil, err
+	}
+
+	var session *PlaySession
+	now := time.Now()
+	err := s.repo.RunInTx(func(tx *sql.Tx) error {
+		repo := s.repo.WithTx(tx)
+		current, err := repo.GetSessionByID(id)
+		if err != nil || current == nil || current.UserID != userID {
+			return err
+		}
+		if !current.active() {
+			return ErrSessionEnded
+		}
+		if err := fn(current, now); err != nil {
+			return err
+		}
+		if err := repo.UpdateSession(current); err != nil {
+			return err
+		}
+		session = current
+		return nil
+	})
+	if err != nil || session == nil {
+		return nil, err
+	}
+	return withCurrentMinutes(session, now), nil
+}
+
+func (s *service) StopSession(userID string, id int) (*StopSessionResponse, error) {
+	if _, err := s.CloseStaleSessions(); err != nil {
+		return nil, err
+	}
+
+	var res *StopSessionResponse
+	err := s.repo.RunInTx(func(tx *sql.Tx) error {
+		session, err := s.repo.WithTx(tx).GetSessionByID(id)
+		if err != nil || session == nil || session.UserID != userID {
+			return err
+		}
+		if !session.active() {
+			return ErrSessionEnded
+		}
+
+		now := time.Now()
+		if session.PausedAt != nil { // 一時停止中に終えたら、止めていた時間は数えない
+			session.PausedSeconds += int(now.Sub(*session.PausedAt).Seconds())
+			session.PausedAt = nil
+		}
+		session.EndedAt = &now
+		session.Status = SessionStatusFinished
+
+		res = &StopSessionResponse{Session: session}
+		res.Motivation, err = s.finishSession(tx, session)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// finishSession ... 終了したセッション (EndedAt・Status を設定済み) の実際のプレイ時間を反映して保存する
+//   - ゲームの累計プレイ時間に足す (残り時間の見積もりに使われる)
+//   - 自分で終えたセッションだけ、ポイントを付与し、紐づいたスケジュールを完了にする
+//     (自動終了したセッションは、本当に遊んでいたか分からないので時間だけ記録する)
+func (s *service) finishSession(tx *sql.Tx, session *PlaySession) (*score.Motivation, error) {
+	session.Minutes = int(math.Round(session.playedDuration(*session.EndedAt).Minutes()))
+	if session.Minutes > 0 {
+		if err := s.gameRepo.WithTx(tx).AddPlayedMinutes(session.GameID, session.Minutes); err != nil {
+			return nil, err
+		}
+	}
+
+	var motivation *score.Motivation
+	if session.Status == SessionStatusFinished {
+		var err error
+		motivation, session.Points, err = s.scoreSvc.WithTx(tx).ReportPlaySession(session.UserID, score.PlaySessionReport{
+			GameID:    session.GameID,
+			Minutes:   session.Minutes,
+			Scheduled: session.ScheduleID != "",
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if session.ScheduleID != "" {
+			calRepo := s.calendarRepo.WithTx(tx)
+			schedule, err := calRepo.GetScheduleByID(session.ScheduleID)
+			if err != nil {
+				return nil, err
+			}
+			if schedule != nil && schedule.Status == calendar.ScheduleStatusPending {
+				if err := calRepo.UpdateScheduleStatus(schedule.ID, calendar.ScheduleStatusCompleted); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return motivation, s.repo.WithTx(tx).UpdateSession(session)
+}
+
+// CloseStaleSessions ... 止め忘れたセッションを終了する
+//   - プレイ中のまま AutoCloseAfter を超えたら、AutoCloseAfter だけ遊んだことにする
+//   - 一時停止のまま AutoCloseAfter を超えたら、一時停止した時点で終えたことにする
+//
+// 自動終了のジョブ・各APIの呼び出し・StopSession が同時に同じセッションを終えないように、
+// トランザクションの中で読み直し、UpdateSession (終了していないときだけ更新) で確定する
+func (s *service) CloseStaleSessions() (int, error) {
+	sessions, err := s.repo.GetActiveSessions()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	closed := 0
+	for _, stale := range sessions {
+		if _, ok := autoCloseEnd(stale, now); !ok {
+			continue
+		}
+
+		var session *PlaySession
+		err := s.repo.RunInTx(func(tx *sql.Tx) error {
+			current, err := s.repo.WithTx(tx).GetSessionByID(stale.ID)
+			if err != nil || current == nil || !current.active() {
+				return err // 読んだ後に終了・削除された
+			}
+			end, ok := autoCloseEnd(current, now)
+			if !ok {
+				return nil // 読んだ後に再開された
+			}
+
+			current.EndedAt = &end
+			current.PausedAt = nil
+			current.Status = SessionStatusAutoClosed
+			if _, err := s.finishSession(tx, current); err != nil {
+				return err
+			}
+			session = current
+			return nil
+		})
+		if errors.Is(err, ErrSessionEnded) {
+			continue // 同時に他のリクエストが終了させた (この回の反映はロールバック済み)
+		}
+		if err != nil {
+			return closed, err
+		}
+		if session == nil {
+			continue
+		}
+		log.Printf("Task: play session %d was left running and auto-closed (%d minutes)", session.ID, session.Minutes)
+		closed++
+	}
+	return closed, nil
+}
+
+// autoCloseEnd ... 止め忘れたセッションを終えたことにする時刻。まだ自動終了しないなら ok が false
+func autoCloseEnd(session *PlaySession, now time.Time) (end time.Time, ok bool) {
+	switch session.Status {
+	case SessionStatusRunning:
+		end = session.StartedAt.Add(time.Duration(session.PausedSeconds)*time.Second + AutoCloseAfter)
+		return end, now.After(end)
+	case SessionStatusPaused:
+		end = *session.PausedAt
+		return end, now.After(end.Add(AutoCloseAfter))
+	}
+	return time.Time{}, false
+}
+
+// withCurrentMinutes ... 終了前のセッションに、今までのプレイ時間を入れる (表示用。保存はしない)
+func withCurrentMinutes(session *PlaySession, now time.Time) *PlaySession {
+	if session.active() {
+		session.Minutes = int(session.playedDuration(now).Minutes())
+	}
+	return session
+}
+
+// OnGamePurged ... ゲームのセッションと日記を削除する (完全削除と同じトランザクション)
+func (s *service) OnGamePurged(tx *sql.Tx, g *game.Game) error {
+	repo := s.repo.WithTx(tx)
+	n, err := repo.DeleteSessionsByGameID(g.ID)
+	if err != nil {
+		return err
+	}
+	entries, err := repo.DeleteJournalEntriesByGameID(g.ID)
+	if err != nil {
+		return err
+	}
+	if n > 0 || entries > 0 {
+		log.Printf("Task: deleted %d play sessions and %d journal entries of purged game %d", n, entries, g.ID)
+	}
+	return nil
+}
+
+// OnGamesMerged ... 統合元のセッションと日記を統合先に付け替える (統合と同じトランザクション)
+func (s *service) OnGamesMerged(tx *sql.Tx, target *game.Game, source *game.Game) error {
+	repo := s.repo.WithTx(tx)
+	if _, err := repo.MoveSessions(source.ID, target.ID); err != nil {
+		return err
+	}
+	_, err := repo.MoveJournalEntries(source.ID, target.ID)
+	return err
+}
@@ -0,0 +1,310 @@
+package task
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"TO-DO-IT/internal/game"
+	"TO-DO-IT/internal/score"
+)
+
+// fakeDB ... テスト用のセッションと累計プレイ時間。RunInTx は fn が失敗したら元に戻す
+type fakeDB struct {
+	sessions map[int]PlaySession
+	played   map[int]int // ゲームごとの累計プレイ時間 (分)
+}
+
+// fakeRepository は、セッションの読み書きだけを持つ Repository です。
+// それ以外のメソッドを呼ぶと、埋め込んだ nil インターフェースで panic します。
+type fakeRepository struct {
+	Repository
+	db *fakeDB
+}
+
+func (r *fakeRepository) GetSessionByID(id int) (*PlaySession, error) {
+	session, ok := r.db.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+func (r *fakeRepository) GetActiveSessions() ([]*PlaySession, error) {
+	var sessions []*PlaySession
+	for _, session := range r.db.sessions {
+		if session.active() {
+			sessions = append(sessions, &session)
+		}
+	}
+	return sessions, nil
+}
+
+// UpdateSession ... 本物と同じく、終了していないセッションだけ更新する
+func (r *fakeRepository) UpdateSession(session *PlaySession) error {
+	if r.db.sessions[session.ID].EndedAt != nil {
+		return ErrSessionEnded
+	}
+	r.db.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *fakeRepository) WithTx(tx *sql.Tx) Repository { return r }
+
+func (r *fakeRepository) RunInTx(fn func(tx *sql.Tx) error) error {
+	sessions, played := map[int]PlaySession{}, map[int]int{}
+	for id, session := range r.db.sessions {
+		sessions[id] = session
+	}
+	for id, minutes := range r.db.played {
+		played[id] = minutes
+	}
+	if err := fn(nil); err != nil {
+		r.db.sessions, r.db.played = sessions, played // ロールバック
+		return err
+	}
+	return nil
+}
+
+// fakeGameRepository は、累計プレイ時間の加算だけを持つ game.Repository です。
+type fakeGameRepository struct {
+	game.Repository
+	db *fakeDB
+}
+
+func (r *fakeGameRepository) AddPlayedMinutes(id int, minutes int) error {
+	r.db.played[id] += minutes
+	return nil
+}
+
+func (r *fakeGameRepository) WithTx(tx *sql.Tx) game.Repository { return r }
+
+// fakeScoreService は、セッションのポイントの付与だけを持つ score.Service です。
+type fakeScoreService struct {
+	score.Service
+	reported int
+}
+
+func (s *fakeScoreService) ReportPlaySession(userID string, report score.PlaySessionReport) (*score.Motivation, int, error) {
+	s.reported++
+	return &score.Motivation{}, 1, nil
+}
+
+func (s *fakeScoreService) WithTx(tx *sql.Tx) score.Service { return s }
+
+// newTestService ... sessions を持つ偽のリポジトリで service を作る
+func newTestService(sessions ...PlaySession) (*service, *fakeDB, *fakeScoreService) {
+	db := &fakeDB{sessions: map[int]PlaySession{}, played: map[int]int{}}
+	for _, session := range sessions {
+		db.sessions[session.ID] = session
+	}
+	scoreSvc := &fakeScoreService{}
+	svc := NewService(&fakeRepository{db: db}, &fakeGameRepository{db: db}, nil, nil, scoreSvc).(*service)
+	return svc, db, scoreSvc
+}
+
+func ptr(t time.Time) *time.Time { return &t }
+
+func TestPlayedDuration(t *testing.T) {
+	start := time.Date(2026, time.June, 1, 20, 0, 0, 0, time.UTC)
+	now := start.Add(2 * time.Hour)
+	tests := []struct {
+		name    string
+		session PlaySession
+		want    time.Duration
+	}{
+		{name: "running", session: PlaySession{StartedAt: start}, want: 2 * time.Hour},
+		{name: "running after a pause", session: PlaySession{StartedAt: start, PausedSeconds: 1800}, want: 90 * time.Minute},
+		{
+			name:    "paused",
+			session: PlaySession{StartedAt: start, PausedSeconds: 600, PausedAt: ptr(start.Add(time.Hour))},
+			want:    50 * time.Minute,
+		},
+		{
+			name:    "ended",
+			session: PlaySession{StartedAt: start, PausedSeconds: 600, EndedAt: ptr(start.Add(40 * time.Minute))},
+			want:    30 * time.Minute,
+		},
+		{name: "not started yet", session: PlaySession{StartedAt: now.Add(time.Minute)}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.session.playedDuration(now); got != tt.want {
+				t.Errorf("playedDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoCloseEnd(t *testing.T) {
+	start := time.Date(2026, time.June, 1, 20, 0, 0, 0, time.UTC)
+	running := func(pausedSeconds int) PlaySession {
+		return PlaySession{Status: SessionStatusRunning, StartedAt: start, PausedSeconds: pausedSeconds}
+	}
+	paused := func(after time.Duration) PlaySession {
+		return PlaySession{Status: SessionStatusPaused, StartedAt: start, PausedAt: ptr(start.Add(after))}
+	}
+	tests := []struct {
+		name    string
+		session PlaySession
+		now     time.Time
+		wantOK  bool
+		wantEnd time.Time
+		played  time.Duration // 終えたことにした時刻までのプレイ時間
+	}{
+		{name: "running", session: running(0), now: start.Add(time.Hour)},
+		{name: "running exactly AutoCloseAfter", session: running(0), now: start.Add(AutoCloseAfter)},
+		{
+			name:    "running past AutoCloseAfter",
+			session: running(0),
+			now:     start.Add(AutoCloseAfter + time.Second),
+			wantOK:  true,
+			wantEnd: start.Add(AutoCloseAfter),
+			played:  AutoCloseAfter,
+		},
+		{name: "pauses are not counted", session: running(3600), now: start.Add(AutoCloseAfter + time.Minute)},
+		{
+			name:    "running with pauses past AutoCloseAfter",
+			session: running(3600),
+			now:     start.Add(AutoCloseAfter + 2*time.Hour),
+			wantOK:  true,
+			wantEnd: start.Add(AutoCloseAfter + time.Hour),
+			played:  AutoCloseAfter,
+		},
+		{name: "paused", session: paused(time.Hour), now: start.Add(3 * time.Hour)},
+		{name: "paused exactly AutoCloseAfter", session: paused(time.Hour), now: start.Add(time.Hour + AutoCloseAfter)},
+		{
+			name:    "paused session going stale ends when it was paused",
+			session: paused(time.Hour),
+			now:     start.Add(8 * time.Hour),
+			wantOK:  true,
+			wantEnd: start.Add(time.Hour),
+			played:  time.Hour,
+		},
+		{
+			name:    "finished",
+			session: PlaySession{Status: SessionStatusFinished, StartedAt: start, EndedAt: ptr(start.Add(time.Hour))},
+			now:     start.Add(24 * time.Hour),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, ok := autoCloseEnd(&tt.session, tt.now)
+			if ok != tt.wantOK {
+				t.Fatalf("autoCloseEnd() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if !end.Equal(tt.wantEnd) {
+				t.Errorf("end = %v, want %v", end, tt.wantEnd)
+			}
+			tt.session.EndedAt = &end
+			if got := tt.session.playedDuration(tt.now); got != tt.played {
+				t.Errorf("played until the end = %v, want %v", got, tt.played)
+			}
+		})
+	}
+}
+
+func TestStopSession(t *testing.T) {
+	svc, db, scoreSvc := newTestService(PlaySession{
+		ID: 1, UserID: "user_123", GameID: 7, Status: SessionStatusRunning, StartedAt: time.Now().Add(-90 * time.Minute),
+	})
+
+	res, err := svc.StopSession("user_123", 1)
+	if err != nil {
+		t.Fatalf("StopSession: %v", err)
+	}
+	if res.Session.Status != SessionStatusFinished || res.Session.Minutes != 90 || res.Session.EndedAt == nil {
+		t.Errorf("session = %+v, want finished after 90 minutes", res.Session)
+	}
+	if db.played[7] != 90 {
+		t.Errorf("played minutes = %d, want 90", db.played[7])
+	}
+
+	// 同じセッションをもう一度終えても、時間もポイントも足さない
+	if _, err := svc.StopSession("user_123", 1); !errors.Is(err, ErrSessionEnded) {
+		t.Errorf("second StopSession: err = %v, want ErrSessionEnded", err)
+	}
+	if db.played[7] != 90 || scoreSvc.reported != 1 {
+		t.Errorf("played minutes = %d, %d reports; want 90, 1", db.played[7], scoreSvc.reported)
+	}
+
+	// 他のユーザーのセッションは見つからない扱い
+	if res, err := svc.StopSession("someone_else", 1); res != nil || err != nil {
+		t.Errorf("StopSession by another user = %+v, %v; want nil, nil", res, err)
+	}
+}
+
+func TestCloseStaleSessions(t *testing.T) {
+	now := time.Now()
+	svc, db, scoreSvc := newTestService(
+		PlaySession{ID: 1, UserID: "user_123", GameID: 7, Status: SessionStatusRunning, StartedAt: now.Add(-7 * time.Hour)},
+		PlaySession{ID: 2, UserID: "user_456", GameID: 8, Status: SessionStatusRunning, StartedAt: now.Add(-time.Hour)},
+		PlaySession{
+			ID: 3, UserID: "user_789", GameID: 9, Status: SessionStatusPaused,
+			StartedAt: now.Add(-9 * time.Hour), PausedAt: ptr(now.Add(-8 * time.Hour)),
+		},
+	)
+
+	closed, err := svc.CloseStaleSessions()
+	if err != nil {
+		t.Fatalf("CloseStaleSessions: %v", err)
+	}
+	if closed != 2 {
+		t.Errorf("closed %d sessions, want 2", closed)
+	}
+	wantPlayed := map[int]int{7: int(AutoCloseAfter.Minutes()), 9: 60}
+	if !reflect.DeepEqual(db.played, wantPlayed) {
+		t.Errorf("played minutes = %v, want %v", db.played, wantPlayed)
+	}
+	for _, id := range []int{1, 3} {
+		if s := db.sessions[id]; s.Status != SessionStatusAutoClosed || s.EndedAt == nil || s.PausedAt != nil {
+			t.Errorf("session %d = %+v, want auto-closed", id, s)
+		}
+	}
+	if s := db.sessions[2]; s.Status != SessionStatusRunning {
+		t.Errorf("session 2 status = %q, want running", s.Status)
+	}
+	if scoreSvc.reported != 0 {
+		t.Errorf("%d points reports, want none for auto-closed sessions", scoreSvc.reported)
+	}
+
+	// 自動終了したセッションは、もう一度終えられない
+	if closed, err := svc.CloseStaleSessions(); closed != 0 || err != nil {
+		t.Errorf("second CloseStaleSessions = %d, %v; want 0, nil", closed, err)
+	}
+	if _, err := svc.StopSession("user_123", 1); !errors.Is(err, ErrSessionEnded) {
+		t.Errorf("StopSession after auto-close: err = %v, want ErrSessionEnded", err)
+	}
+	if !reflect.DeepEqual(db.played, wantPlayed) {
+		t.Errorf("played minutes = %v, want %v", db.played, wantPlayed)
+	}
+}
+
+func TestFinishSessionOnlyOnce(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	svc, db, _ := newTestService(PlaySession{ID: 1, UserID: "user_123", GameID: 7, Status: SessionStatusRunning, StartedAt: start})
+
+	// 読み込んだ後に、他のリクエストが先に終了させた
+	stale, _ := svc.repo.GetSessionByID(1)
+	if _, err := svc.StopSession("user_123", 1); err != nil {
+		t.Fatalf("StopSession: %v", err)
+	}
+
+	err := svc.repo.RunInTx(func(tx *sql.Tx) error {
+		stale.EndedAt = ptr(time.Now())
+		stale.Status = SessionStatusAutoClosed
+		_, err := svc.finishSession(tx, stale)
+		return err
+	})
+	if !errors.Is(err, ErrSessionEnded) {
+		t.Errorf("finishSession on an ended session: err = %v, want ErrSessionEnded", err)
+	}
+	if db.played[7] != 60 || db.sessions[1].Status != SessionStatusFinished {
+		t.Errorf("played minutes = %d, status %q; want 60 once and finished", db.played[7], db.sessions[1].Status)
+	}
+}